@@ -0,0 +1,121 @@
+package goshopify
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AuditEntry records a POST, PUT or DELETE request made to a shop.
+type AuditEntry struct {
+	Time   time.Time `json:"time"`
+	Shop   string    `json:"shop"`
+	Method string    `json:"method"`
+	Path   string    `json:"path"`
+
+	// Hex encoded SHA-256 hash of the request body
+	PayloadHash string `json:"payload_hash"`
+
+	// Status code of the response, or 0 when there was none
+	Status int `json:"status"`
+
+	// Shopify's X-Request-Id of the response
+	RequestID string `json:"request_id,omitempty"`
+
+	// Error that prevented a response, if any
+	Error string `json:"error,omitempty"`
+}
+
+// AuditSink stores audit entries. Record is called after every POST, PUT or
+// DELETE request, possibly from several goroutines at once.
+type AuditSink interface {
+	Record(entry AuditEntry)
+}
+
+// AuditSinkFunc is an adapter to use a function as an AuditSink.
+type AuditSinkFunc func(entry AuditEntry)
+
+// Record calls f(entry).
+func (f AuditSinkFunc) Record(entry AuditEntry) {
+	f(entry)
+}
+
+// JSONAuditSink writes audit entries as lines of JSON.
+type JSONAuditSink struct {
+	// Called for entries that could not be written, e.g. because the disk
+	// is full, so that they are not lost silently
+	Failed func(entry AuditEntry, err error)
+
+	mu      sync.Mutex
+	encoder *json.Encoder
+	err     error
+}
+
+// NewJSONAuditSink returns a sink that writes audit entries to w.
+func NewJSONAuditSink(w io.Writer) *JSONAuditSink {
+	return &JSONAuditSink{encoder: json.NewEncoder(w)}
+}
+
+// Record writes the entry as a line of JSON. Failures are passed to Failed
+// and kept for Err.
+func (s *JSONAuditSink) Record(entry AuditEntry) {
+	s.mu.Lock()
+	err := s.encoder.Encode(entry)
+	if err != nil && s.err == nil {
+		s.err = err
+	}
+	failed := s.Failed
+	s.mu.Unlock()
+
+	if err != nil && failed != nil {
+		failed(entry, err)
+	}
+}
+
+// Err returns the first error writing an entry, or nil if all were written.
+func (s *JSONAuditSink) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// WithAuditSink makes the client record every POST, PUT and DELETE request it
+// makes, and its outcome, to sink.
+func WithAuditSink(sink AuditSink) Option {
+	return func(c *Client) {
+		c.audit = sink
+	}
+}
+
+// Record a request and its response, or the error that prevented one.
+func (c *Client) recordAudit(req *http.Request, resp *http.Response, err error) {
+	entry := AuditEntry{
+		Time:   time.Now().UTC(),
+		Shop:   req.URL.Host,
+		Method: req.Method,
+		Path:   req.URL.Path,
+	}
+
+	hash := sha256.New()
+	if req.GetBody != nil {
+		body, bodyErr := req.GetBody()
+		if bodyErr == nil {
+			io.Copy(hash, body)
+			body.Close()
+		}
+	}
+	entry.PayloadHash = hex.EncodeToString(hash.Sum(nil))
+
+	if err != nil {
+		entry.Error = err.Error()
+	} else {
+		entry.Status = resp.StatusCode
+		entry.RequestID = resp.Header.Get("X-Request-Id")
+	}
+
+	c.audit.Record(entry)
+}
@@ -0,0 +1,103 @@
+package goshopify
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestAuditSink(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var entries []AuditEntry
+	client = NewClient(app, "fooshop", "abcd", WithAuditSink(AuditSinkFunc(func(entry AuditEntry) {
+		entries = append(entries, entry)
+	})))
+	httpmock.ActivateNonDefault(client.Client)
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products/1.json",
+		httpmock.NewStringResponder(200, `{"product": {"id": 1}}`))
+	httpmock.RegisterResponder("PUT", "https://fooshop.myshopify.com/admin/products/1.json",
+		func(req *http.Request) (*http.Response, error) {
+			resp := httpmock.NewStringResponse(200, `{"product": {"id": 1}}`)
+			resp.Header.Set("X-Request-Id", "abc-123")
+			return resp, nil
+		})
+	httpmock.RegisterResponder("DELETE", "https://fooshop.myshopify.com/admin/products/1.json",
+		httpmock.NewStringResponder(404, `{"errors": "Not Found"}`))
+
+	client.Product.Get(1, nil)
+	client.Product.Update(Product{ID: 1, Title: "Shirt"})
+	client.Product.Delete(1)
+
+	if len(entries) != 2 {
+		t.Fatalf("Recorded %d entries, expected 2: %v", len(entries), entries)
+	}
+
+	payload, _ := json.Marshal(ProductResource{Product: &Product{ID: 1, Title: "Shirt"}})
+	hash := sha256.Sum256(payload)
+	update := entries[0]
+	if update.Method != "PUT" || update.Shop != "fooshop.myshopify.com" || update.Path != "/admin/products/1.json" {
+		t.Errorf("Update recorded as %v %v%v", update.Method, update.Shop, update.Path)
+	}
+	if update.PayloadHash != hex.EncodeToString(hash[:]) {
+		t.Errorf("Update recorded with payload hash %v, expected the hash of %s", update.PayloadHash, payload)
+	}
+	if update.Status != 200 || update.RequestID != "abc-123" || update.Time.IsZero() {
+		t.Errorf("Update recorded with status %v, request id %v and time %v", update.Status, update.RequestID, update.Time)
+	}
+
+	if entries[1].Method != "DELETE" || entries[1].Status != 404 {
+		t.Errorf("Delete recorded as %v with status %v, expected DELETE with status 404", entries[1].Method, entries[1].Status)
+	}
+}
+
+func TestJSONAuditSink(t *testing.T) {
+	buf := new(bytes.Buffer)
+	sink := NewJSONAuditSink(buf)
+	sink.Record(AuditEntry{Method: "POST", Path: "/admin/products.json", Status: 201})
+	sink.Record(AuditEntry{Method: "DELETE", Path: "/admin/products/1.json", Status: 200})
+
+	decoder := json.NewDecoder(buf)
+	for _, expected := range []string{"POST", "DELETE"} {
+		entry := AuditEntry{}
+		err := decoder.Decode(&entry)
+		if err != nil {
+			t.Fatalf("Cannot decode audit entry: %v", err)
+		}
+		if entry.Method != expected {
+			t.Errorf("Audit entry has method %v, expected %v", entry.Method, expected)
+		}
+	}
+}
+
+// A writer that always fails
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("no space left on device")
+}
+
+func TestJSONAuditSinkFailed(t *testing.T) {
+	sink := NewJSONAuditSink(failingWriter{})
+	var failed []AuditEntry
+	sink.Failed = func(entry AuditEntry, err error) {
+		failed = append(failed, entry)
+	}
+
+	sink.Record(AuditEntry{Method: "POST", Path: "/admin/products.json", Status: 201})
+
+	if len(failed) != 1 || failed[0].Method != "POST" {
+		t.Errorf("JSONAuditSink.Failed was called with %v, expected the POST entry", failed)
+	}
+	if sink.Err() == nil {
+		t.Errorf("JSONAuditSink.Err() returned nil after a failed write")
+	}
+}
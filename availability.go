@@ -0,0 +1,228 @@
+package goshopify
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The number of variants looked up per GraphQL request, kept low along with
+// the number of their locations for the cost of a query to stay below the
+// limit.
+const availabilityBatchSize = 20
+
+const availabilityFields = `id sku inventoryItem {
+	id tracked
+	inventoryLevels(first: 20) {
+		pageInfo { hasNextPage }
+		edges { cursor node { available location { id name } } }
+	}
+}`
+
+const availabilityByVariantsQuery = `query availabilityByVariants($ids: [ID!]!) {
+	nodes(ids: $ids) { ... on ProductVariant { ` + availabilityFields + ` } }
+}`
+
+const availabilityBySKUsQuery = `query availabilityBySKUs($query: String!, $after: String) {
+	productVariants(first: 20, query: $query, after: $after) {
+		pageInfo { hasNextPage }
+		edges { cursor node { ` + availabilityFields + ` } }
+	}
+}`
+
+const inventoryLevelsQuery = `query inventoryLevels($id: ID!, $after: String) {
+	inventoryItem(id: $id) {
+		inventoryLevels(first: 50, after: $after) {
+			pageInfo { hasNextPage }
+			edges { cursor node { available location { id name } } }
+		}
+	}
+}`
+
+// Availability is the inventory of a variant available across the shop's
+// locations. Variants whose inventory is not tracked are always available,
+// whatever the quantities say.
+type Availability struct {
+	VariantID       uint64
+	SKU             string
+	InventoryItemID uint64
+	Tracked         bool
+	Available       int
+	Locations       []LocationAvailability
+}
+
+// LocationAvailability is the inventory of a variant available at a location.
+type LocationAvailability struct {
+	LocationID   uint64
+	LocationName string
+	Available    int
+}
+
+// A page of inventory levels in a GraphQL response
+type inventoryLevelsConnection struct {
+	PageInfo struct {
+		HasNextPage bool `json:"hasNextPage"`
+	} `json:"pageInfo"`
+	Edges []struct {
+		Cursor string `json:"cursor"`
+		Node   struct {
+			Available int `json:"available"`
+			Location  struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"location"`
+		} `json:"node"`
+	} `json:"edges"`
+}
+
+// A variant with its inventory in a GraphQL response
+type availabilityNode struct {
+	ID            string `json:"id"`
+	SKU           string `json:"sku"`
+	InventoryItem struct {
+		ID              string                    `json:"id"`
+		Tracked         bool                      `json:"tracked"`
+		InventoryLevels inventoryLevelsConnection `json:"inventoryLevels"`
+	} `json:"inventoryItem"`
+}
+
+// AvailabilityByVariants returns the availability of the variants with the
+// given ids, in the order they are given. Variants that do not exist are left
+// out. Variants are looked up 20 at a time with GraphQL, instead of fetching
+// the inventory levels of every variant.
+func AvailabilityByVariants(c *Client, variantIDs []uint64) ([]Availability, error) {
+	availability := []Availability{}
+	for start := 0; start < len(variantIDs); start += availabilityBatchSize {
+		end := start + availabilityBatchSize
+		if end > len(variantIDs) {
+			end = len(variantIDs)
+		}
+
+		ids := make([]string, end-start)
+		for i, id := range variantIDs[start:end] {
+			ids[i] = FormatGID("ProductVariant", id)
+		}
+
+		data := struct {
+			Nodes []*availabilityNode `json:"nodes"`
+		}{}
+		err := c.GraphQL.Query(availabilityByVariantsQuery, map[string]interface{}{"ids": ids}, &data)
+		if err != nil {
+			return availability, err
+		}
+
+		for _, node := range data.Nodes {
+			// Variants that do not exist come back as null
+			if node == nil {
+				continue
+			}
+			a, err := node.availability(c)
+			if err != nil {
+				return availability, err
+			}
+			availability = append(availability, a)
+		}
+	}
+	return availability, nil
+}
+
+// AvailabilityBySKUs returns the availability of the variants with the given
+// SKUs. There is one for every variant with one of the SKUs, so there may be
+// more than one per SKU, or none.
+func AvailabilityBySKUs(c *Client, skus []string) ([]Availability, error) {
+	availability := []Availability{}
+	for start := 0; start < len(skus); start += availabilityBatchSize {
+		end := start + availabilityBatchSize
+		if end > len(skus) {
+			end = len(skus)
+		}
+
+		terms := make([]string, end-start)
+		requested := make(map[string]bool)
+		for i, sku := range skus[start:end] {
+			terms[i] = fmt.Sprintf("sku:%q", sku)
+			requested[sku] = true
+		}
+		variables := map[string]interface{}{"query": strings.Join(terms, " OR ")}
+
+		for {
+			data := struct {
+				ProductVariants struct {
+					PageInfo struct {
+						HasNextPage bool `json:"hasNextPage"`
+					} `json:"pageInfo"`
+					Edges []struct {
+						Cursor string           `json:"cursor"`
+						Node   availabilityNode `json:"node"`
+					} `json:"edges"`
+				} `json:"productVariants"`
+			}{}
+			err := c.GraphQL.Query(availabilityBySKUsQuery, variables, &data)
+			if err != nil {
+				return availability, err
+			}
+
+			for _, edge := range data.ProductVariants.Edges {
+				// The search is by tokens of the SKU, so it also finds
+				// variants whose SKU only starts with or contains one
+				if !requested[edge.Node.SKU] {
+					continue
+				}
+				a, err := edge.Node.availability(c)
+				if err != nil {
+					return availability, err
+				}
+				availability = append(availability, a)
+			}
+
+			edges := data.ProductVariants.Edges
+			if !data.ProductVariants.PageInfo.HasNextPage || len(edges) == 0 {
+				break
+			}
+			variables["after"] = edges[len(edges)-1].Cursor
+		}
+	}
+	return availability, nil
+}
+
+// Sum up the inventory levels of a variant, fetching those of locations that
+// did not fit in the first page.
+func (n availabilityNode) availability(c *Client) (Availability, error) {
+	a := Availability{
+		VariantID:       ParseGID(n.ID),
+		SKU:             n.SKU,
+		InventoryItemID: ParseGID(n.InventoryItem.ID),
+		Tracked:         n.InventoryItem.Tracked,
+		Locations:       []LocationAvailability{},
+	}
+
+	levels := n.InventoryItem.InventoryLevels
+	for {
+		for _, edge := range levels.Edges {
+			a.Available += edge.Node.Available
+			a.Locations = append(a.Locations, LocationAvailability{
+				LocationID:   ParseGID(edge.Node.Location.ID),
+				LocationName: edge.Node.Location.Name,
+				Available:    edge.Node.Available,
+			})
+		}
+
+		if !levels.PageInfo.HasNextPage || len(levels.Edges) == 0 {
+			return a, nil
+		}
+
+		data := struct {
+			InventoryItem struct {
+				InventoryLevels inventoryLevelsConnection `json:"inventoryLevels"`
+			} `json:"inventoryItem"`
+		}{}
+		variables := map[string]interface{}{
+			"id":    n.InventoryItem.ID,
+			"after": levels.Edges[len(levels.Edges)-1].Cursor,
+		}
+		err := c.GraphQL.Query(inventoryLevelsQuery, variables, &data)
+		if err != nil {
+			return a, err
+		}
+		levels = data.InventoryItem.InventoryLevels
+	}
+}
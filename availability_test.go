@@ -0,0 +1,127 @@
+package goshopify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+// The inventory of a variant in GraphQL responses, at two locations of which
+// the second is on another page when paged
+func availabilityNodeJSON(id string, paged bool) string {
+	levels := `{"pageInfo": {"hasNextPage": false}, "edges": [
+		{"cursor": "a", "node": {"available": 3, "location": {"id": "gid://shopify/Location/1", "name": "Warehouse"}}},
+		{"cursor": "b", "node": {"available": -1, "location": {"id": "gid://shopify/Location/2", "name": "Store"}}}]}`
+	if paged {
+		levels = `{"pageInfo": {"hasNextPage": true}, "edges": [
+			{"cursor": "a", "node": {"available": 3, "location": {"id": "gid://shopify/Location/1", "name": "Warehouse"}}}]}`
+	}
+	return fmt.Sprintf(`{"id": "gid://shopify/ProductVariant/%s", "sku": "SKU-%s", "inventoryItem": {
+		"id": "gid://shopify/InventoryItem/%s0", "tracked": true, "inventoryLevels": %s}}`, id, id, id, levels)
+}
+
+func registerAvailabilityResponder(queries *[]string) {
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/graphql.json",
+		func(req *http.Request) (*http.Response, error) {
+			body := struct {
+				Query     string `json:"query"`
+				Variables struct {
+					IDs   []string `json:"ids"`
+					Query string   `json:"query"`
+					After string   `json:"after"`
+				} `json:"variables"`
+			}{}
+			json.NewDecoder(req.Body).Decode(&body)
+			*queries = append(*queries, body.Variables.Query+body.Variables.After)
+
+			switch {
+			case strings.Contains(body.Query, "availabilityByVariants"):
+				nodes := []string{}
+				for _, id := range body.Variables.IDs {
+					if id == "gid://shopify/ProductVariant/404" {
+						nodes = append(nodes, "null")
+						continue
+					}
+					nodes = append(nodes, availabilityNodeJSON(id[len("gid://shopify/ProductVariant/"):], id == "gid://shopify/ProductVariant/2"))
+				}
+				return httpmock.NewStringResponse(200, `{"data": {"nodes": [`+strings.Join(nodes, ",")+`]}}`), nil
+			case strings.Contains(body.Query, "availabilityBySKUs"):
+				if body.Variables.After == "" {
+					return httpmock.NewStringResponse(200, `{"data": {"productVariants": {"pageInfo": {"hasNextPage": true},
+						"edges": [{"cursor": "v1", "node": `+availabilityNodeJSON("1", false)+`}]}}}`), nil
+				}
+				// SKU-30 is found by the search for SKU-3 too
+				return httpmock.NewStringResponse(200, `{"data": {"productVariants": {"pageInfo": {"hasNextPage": false},
+					"edges": [{"cursor": "v3", "node": `+availabilityNodeJSON("3", false)+`},
+						{"cursor": "v30", "node": `+availabilityNodeJSON("30", false)+`}]}}}`), nil
+			default:
+				return httpmock.NewStringResponse(200, `{"data": {"inventoryItem": {"inventoryLevels": {"pageInfo": {"hasNextPage": false},
+					"edges": [{"cursor": "b", "node": {"available": -1, "location": {"id": "gid://shopify/Location/2", "name": "Store"}}}]}}}}`), nil
+			}
+		})
+}
+
+func expectedAvailability(id uint64, sku string) Availability {
+	return Availability{
+		VariantID:       id,
+		SKU:             sku,
+		InventoryItemID: id * 10,
+		Tracked:         true,
+		Available:       2,
+		Locations: []LocationAvailability{
+			{LocationID: 1, LocationName: "Warehouse", Available: 3},
+			{LocationID: 2, LocationName: "Store", Available: -1},
+		},
+	}
+}
+
+func TestAvailabilityByVariants(t *testing.T) {
+	setup()
+	defer teardown()
+
+	queries := []string{}
+	registerAvailabilityResponder(&queries)
+
+	availability, err := AvailabilityByVariants(client, []uint64{1, 404, 2})
+	if err != nil {
+		t.Fatalf("AvailabilityByVariants returned error: %v", err)
+	}
+
+	expected := []Availability{expectedAvailability(1, "SKU-1"), expectedAvailability(2, "SKU-2")}
+	if !reflect.DeepEqual(availability, expected) {
+		t.Errorf("AvailabilityByVariants returned %+v, expected %+v", availability, expected)
+	}
+	// One for the variants and one for the second page of locations
+	if len(queries) != 2 {
+		t.Errorf("AvailabilityByVariants made %d queries, expected 2", len(queries))
+	}
+}
+
+func TestAvailabilityBySKUs(t *testing.T) {
+	setup()
+	defer teardown()
+
+	queries := []string{}
+	registerAvailabilityResponder(&queries)
+
+	availability, err := AvailabilityBySKUs(client, []string{"SKU-1", "SKU-3", `SKU "4"`})
+	if err != nil {
+		t.Fatalf("AvailabilityBySKUs returned error: %v", err)
+	}
+
+	expected := []Availability{expectedAvailability(1, "SKU-1"), expectedAvailability(3, "SKU-3")}
+	if !reflect.DeepEqual(availability, expected) {
+		t.Errorf("AvailabilityBySKUs returned %+v, expected %+v", availability, expected)
+	}
+
+	query := `sku:"SKU-1" OR sku:"SKU-3" OR sku:"SKU \"4\""`
+	expectedQueries := []string{query, query + "v1"}
+	if !reflect.DeepEqual(queries, expectedQueries) {
+		t.Errorf("AvailabilityBySKUs made queries %v, expected %v", queries, expectedQueries)
+	}
+}
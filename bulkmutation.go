@@ -0,0 +1,241 @@
+package goshopify
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"time"
+)
+
+const stagedUploadsCreateMutation = `mutation stagedUploadsCreate($input: [StagedUploadInput!]!) {
+	stagedUploadsCreate(input: $input) {
+		stagedTargets { url resourceUrl parameters { name value } }
+		userErrors { field message }
+	}
+}`
+
+const bulkOperationRunMutationMutation = `mutation bulkOperationRunMutation($mutation: String!, $stagedUploadPath: String!) {
+	bulkOperationRunMutation(mutation: $mutation, stagedUploadPath: $stagedUploadPath) {
+		bulkOperation {` + bulkOperationFields + `}
+		userErrors { field message }
+	}
+}`
+
+// BulkMutationResult is the result of running the mutation of a bulk
+// operation with one line of its variables. Line is the number of that line,
+// starting at 0.
+type BulkMutationResult struct {
+	Line int `json:"__lineNumber"`
+	// The data of the mutation, e.g. {"productCreate": {"product": ...}}
+	Data json.RawMessage `json:"data"`
+	// Errors of the GraphQL request
+	Errors []GraphQLError `json:"errors"`
+	// Errors reported by the mutation in its userErrors field
+	UserErrors []UserError `json:"-"`
+}
+
+// Err returns the errors of the line as an error, or nil if it succeeded.
+func (r BulkMutationResult) Err() error {
+	if err := graphQLErrorsToError(r.Errors); err != nil {
+		return err
+	}
+	return userErrorsToError(r.UserErrors)
+}
+
+// The place a staged upload is sent to, and the form fields to send along
+type stagedTarget struct {
+	URL        string `json:"url"`
+	Parameters []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"parameters"`
+}
+
+// RunMutation starts a bulk operation that runs a mutation once for each line
+// of a JSONL file of variables, such as
+//
+//	{"input": {"title": "Sweet new snowboard"}}
+//
+// The variables are uploaded to Shopify first. Use WaitMutation to wait for
+// the operation and MutationResults to go through the result of each line.
+// On clients with a production safeguard, the call has to be made through
+// Confirm.
+func (s *BulkOperationServiceOp) RunMutation(mutation string, variables io.Reader) (*BulkOperation, error) {
+	// Checked before anything is uploaded, as the upload is of no use if the
+	// operation cannot run
+	err := s.client.guardMutations(bulkOperationRunMutationMutation)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := s.stageVariables(variables)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := struct {
+		BulkOperationRunMutation struct {
+			BulkOperation *BulkOperation `json:"bulkOperation"`
+			UserErrors    []UserError    `json:"userErrors"`
+		} `json:"bulkOperationRunMutation"`
+	}{}
+
+	err = s.client.GraphQL.Query(bulkOperationRunMutationMutation, map[string]interface{}{
+		"mutation":         mutation,
+		"stagedUploadPath": path,
+	}, &resource)
+	if err != nil {
+		return nil, err
+	}
+	result := resource.BulkOperationRunMutation
+	return result.BulkOperation, userErrorsToError(result.UserErrors)
+}
+
+// Upload a variables file and return the path to run a bulk mutation with.
+func (s *BulkOperationServiceOp) stageVariables(variables io.Reader) (string, error) {
+	resource := struct {
+		StagedUploadsCreate struct {
+			StagedTargets []stagedTarget `json:"stagedTargets"`
+			UserErrors    []UserError    `json:"userErrors"`
+		} `json:"stagedUploadsCreate"`
+	}{}
+
+	err := s.client.GraphQL.Query(stagedUploadsCreateMutation, map[string]interface{}{
+		"input": []map[string]string{{
+			"resource":   "BULK_MUTATION_VARIABLES",
+			"filename":   "bulk_op_vars.jsonl",
+			"mimeType":   "text/jsonl",
+			"httpMethod": "POST",
+		}},
+	}, &resource)
+	if err != nil {
+		return "", err
+	}
+	result := resource.StagedUploadsCreate
+	err = userErrorsToError(result.UserErrors)
+	if err != nil {
+		return "", err
+	}
+	if len(result.StagedTargets) == 0 {
+		return "", fmt.Errorf("no staged upload target for the bulk mutation variables")
+	}
+
+	target := result.StagedTargets[0]
+	path := ""
+	for _, parameter := range target.Parameters {
+		if parameter.Name == "key" {
+			path = parameter.Value
+		}
+	}
+
+	// The form is spooled to a temporary file rather than held in memory,
+	// since the storage wants its length up front and large files of
+	// variables are common
+	file, err := ioutil.TempFile("", "bulk_op_vars")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	form := multipart.NewWriter(file)
+	err = writeStagedUpload(form, target, variables)
+	if err != nil {
+		return "", err
+	}
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", err
+	}
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return "", err
+	}
+
+	// The upload goes to storage outside of Shopify, so it is made without
+	// the client's credentials.
+	req, err := http.NewRequest("POST", target.URL, file)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := s.client.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", ResponseError{Status: resp.StatusCode, Message: fmt.Sprintf("uploading bulk mutation variables: %s", resp.Status)}
+	}
+	return path, nil
+}
+
+// Write the form of a staged upload with the file last, as the storage
+// expects.
+func writeStagedUpload(form *multipart.Writer, target stagedTarget, file io.Reader) error {
+	for _, parameter := range target.Parameters {
+		err := form.WriteField(parameter.Name, parameter.Value)
+		if err != nil {
+			return err
+		}
+	}
+	part, err := form.CreateFormFile("file", "bulk_op_vars.jsonl")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, file)
+	if err != nil {
+		return err
+	}
+	return form.Close()
+}
+
+// CurrentMutation returns the most recent bulk mutation of the shop, or nil
+// if there is none.
+func (s *BulkOperationServiceOp) CurrentMutation() (*BulkOperation, error) {
+	return s.current("MUTATION")
+}
+
+// WaitMutation polls the current bulk mutation every interval until it is
+// done and returns it. An error is returned along with the operation if it
+// did not complete successfully.
+func (s *BulkOperationServiceOp) WaitMutation(interval time.Duration) (*BulkOperation, error) {
+	return s.wait("MUTATION", interval)
+}
+
+// MutationResults downloads the results of a completed bulk mutation and
+// calls fn with the result of each line of variables. Lines that failed are
+// passed to fn as well, with their errors; going through the results stops
+// only at an error returned by fn or a failed download.
+func (s *BulkOperationServiceOp) MutationResults(op *BulkOperation, fn func(BulkMutationResult) error) error {
+	return s.Records(op, func(line json.RawMessage) error {
+		result := BulkMutationResult{}
+		err := json.Unmarshal(line, &result)
+		if err != nil {
+			return err
+		}
+
+		// The data holds a single mutation, whatever its name
+		mutations := map[string]*struct {
+			UserErrors []UserError `json:"userErrors"`
+		}{}
+		if len(result.Data) > 0 {
+			json.Unmarshal(result.Data, &mutations)
+		}
+		for _, mutation := range mutations {
+			if mutation != nil {
+				result.UserErrors = append(result.UserErrors, mutation.UserErrors...)
+			}
+		}
+
+		return fn(result)
+	})
+}
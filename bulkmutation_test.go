@@ -0,0 +1,215 @@
+package goshopify
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestBulkOperationRunMutation(t *testing.T) {
+	setup()
+	defer teardown()
+
+	uploadURL := "https://shopify-staged-uploads.storage.googleapis.com/"
+	path := "tmp/21759409/bulk/89e620e1-0252-43b0-8f3b-3b7075ba4a23/bulk_op_vars.jsonl"
+	var runVariables map[string]interface{}
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/graphql.json",
+		func(req *http.Request) (*http.Response, error) {
+			body := struct {
+				Query     string                 `json:"query"`
+				Variables map[string]interface{} `json:"variables"`
+			}{}
+			json.NewDecoder(req.Body).Decode(&body)
+
+			if strings.Contains(body.Query, "stagedUploadsCreate") {
+				return httpmock.NewStringResponse(200, `{"data": {"stagedUploadsCreate": {
+					"stagedTargets": [{"url": "`+uploadURL+`", "resourceUrl": null, "parameters": [
+						{"name": "key", "value": "`+path+`"},
+						{"name": "Content-Type", "value": "text/jsonl"},
+						{"name": "policy", "value": "abc"}
+					]}],
+					"userErrors": []
+				}}}`), nil
+			}
+			runVariables = body.Variables
+			return httpmock.NewStringResponse(200, `{"data": {"bulkOperationRunMutation": {
+				"bulkOperation": {"id": "gid://shopify/BulkOperation/2", "type": "MUTATION", "status": "CREATED"},
+				"userErrors": []
+			}}}`), nil
+		})
+
+	var uploaded, key string
+	httpmock.RegisterResponder("POST", uploadURL,
+		func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Shopify-Access-Token") != "" {
+				return httpmock.NewStringResponse(403, ""), nil
+			}
+			// Storage rejects uploads without a length
+			if req.ContentLength <= 0 {
+				return httpmock.NewStringResponse(411, ""), nil
+			}
+			file, _, err := req.FormFile("file")
+			if err != nil {
+				return httpmock.NewStringResponse(400, ""), nil
+			}
+			data, _ := ioutil.ReadAll(file)
+			uploaded = string(data)
+			key = req.FormValue("key")
+			return httpmock.NewStringResponse(201, ""), nil
+		})
+
+	mutation := `mutation call($input: ProductInput!) { productCreate(input: $input) { product { id } userErrors { field message } } }`
+	variables := `{"input": {"title": "Sweet new snowboard"}}` + "\n" + `{"input": {"title": ""}}` + "\n"
+	op, err := client.BulkOperation.RunMutation(mutation, strings.NewReader(variables))
+	if err != nil {
+		t.Fatalf("BulkOperation.RunMutation returned error: %v", err)
+	}
+
+	if op.ID != "gid://shopify/BulkOperation/2" || op.Type != "MUTATION" {
+		t.Errorf("BulkOperation.RunMutation returned %+v, expected a created mutation", op)
+	}
+	if uploaded != variables || key != path {
+		t.Errorf("BulkOperation.RunMutation uploaded %q with key %q", uploaded, key)
+	}
+	if runVariables["mutation"] != mutation || runVariables["stagedUploadPath"] != path {
+		t.Errorf("BulkOperation.RunMutation ran with variables %v", runVariables)
+	}
+}
+
+func TestBulkOperationRunMutationUploadFailure(t *testing.T) {
+	setup()
+	defer teardown()
+
+	uploadURL := "https://shopify-staged-uploads.storage.googleapis.com/"
+	mutations := 0
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/graphql.json",
+		func(req *http.Request) (*http.Response, error) {
+			mutations++
+			return httpmock.NewStringResponse(200, `{"data": {"stagedUploadsCreate": {
+				"stagedTargets": [{"url": "`+uploadURL+`", "parameters": [{"name": "key", "value": "tmp/vars.jsonl"}]}],
+				"userErrors": []
+			}}}`), nil
+		})
+	httpmock.RegisterResponder("POST", uploadURL, httpmock.NewStringResponder(403, ""))
+
+	_, err := client.BulkOperation.RunMutation("mutation { }", strings.NewReader("{}\n"))
+	if e, ok := err.(ResponseError); !ok || e.Status != 403 {
+		t.Errorf("BulkOperation.RunMutation returned error %v, expected a 403 ResponseError", err)
+	}
+	if mutations != 1 {
+		t.Errorf("BulkOperation.RunMutation ran %d mutations, expected only the staged upload", mutations)
+	}
+}
+
+func TestBulkOperationRunMutationSafeguard(t *testing.T) {
+	setupSafeguard("enterprise")
+	defer teardown()
+
+	requests := 0
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/graphql.json",
+		func(req *http.Request) (*http.Response, error) {
+			requests++
+			return httpmock.NewStringResponse(500, ""), nil
+		})
+
+	_, err := client.BulkOperation.RunMutation("mutation { }", strings.NewReader("{}\n"))
+	if _, ok := err.(SafeguardError); !ok {
+		t.Errorf("BulkOperation.RunMutation on a production store returned %v, expected a SafeguardError", err)
+	}
+	if requests != 0 {
+		t.Errorf("BulkOperation.RunMutation made %d GraphQL requests, expected none", requests)
+	}
+}
+
+func TestBulkOperationWaitMutation(t *testing.T) {
+	setup()
+	defer teardown()
+
+	statuses := []string{"RUNNING", "COMPLETED"}
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/graphql.json",
+		func(req *http.Request) (*http.Response, error) {
+			body := struct {
+				Variables map[string]interface{} `json:"variables"`
+			}{}
+			json.NewDecoder(req.Body).Decode(&body)
+			if body.Variables["type"] != "MUTATION" {
+				return httpmock.NewStringResponse(200, `{"data": {"currentBulkOperation": null}}`), nil
+			}
+
+			status := statuses[0]
+			statuses = statuses[1:]
+			return httpmock.NewStringResponse(200, `{"data": {"currentBulkOperation": {
+				"id": "gid://shopify/BulkOperation/2", "type": "MUTATION", "status": "`+status+`"
+			}}}`), nil
+		})
+
+	op, err := client.BulkOperation.WaitMutation(time.Millisecond)
+	if err != nil {
+		t.Errorf("BulkOperation.WaitMutation returned error: %v", err)
+	}
+	if op == nil || op.Status != "COMPLETED" {
+		t.Errorf("BulkOperation.WaitMutation returned %+v, expected a completed operation", op)
+	}
+}
+
+func TestBulkOperationMutationResults(t *testing.T) {
+	setup()
+	defer teardown()
+
+	url := "https://storage.googleapis.com/shopify/bulk-operation-results.jsonl"
+	httpmock.RegisterResponder("GET", url,
+		httpmock.NewBytesResponder(200, loadFixture("bulk_mutation_results.jsonl")))
+
+	results := []BulkMutationResult{}
+	err := client.BulkOperation.MutationResults(&BulkOperation{URL: url}, func(result BulkMutationResult) error {
+		results = append(results, result)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("BulkOperation.MutationResults returned error: %v", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("BulkOperation.MutationResults returned %d results, expected 3", len(results))
+	}
+
+	cases := []struct {
+		line     int
+		expected string
+	}{
+		{0, ""},
+		{1, "title: Title can't be blank"},
+		{2, "Internal error"},
+	}
+	for i, c := range cases {
+		result := results[i]
+		if result.Line != c.line {
+			t.Errorf("BulkMutationResult.Line returned %d, expected %d", result.Line, c.line)
+		}
+
+		actual := ""
+		if err := result.Err(); err != nil {
+			actual = err.Error()
+		}
+		if actual != c.expected {
+			t.Errorf("BulkMutationResult.Err() for line %d returned %q, expected %q", c.line, actual, c.expected)
+		}
+	}
+
+	product := struct {
+		ProductCreate struct {
+			Product struct {
+				ID string `json:"id"`
+			} `json:"product"`
+		} `json:"productCreate"`
+	}{}
+	json.Unmarshal(results[0].Data, &product)
+	if product.ProductCreate.Product.ID != "gid://shopify/Product/1" {
+		t.Errorf("BulkMutationResult.Data returned %s", results[0].Data)
+	}
+}
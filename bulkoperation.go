@@ -0,0 +1,362 @@
+package goshopify
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// DefaultBulkOperationPollInterval is the time Wait sleeps between checks
+// of a running bulk operation.
+const DefaultBulkOperationPollInterval = 5 * time.Second
+
+// Defaults for resuming broken downloads of bulk operation results
+const (
+	DefaultBulkDownloadRetries    = 5
+	DefaultBulkDownloadRetryDelay = time.Second
+)
+
+const bulkOperationFields = `
+	id
+	type
+	status
+	errorCode
+	createdAt
+	completedAt
+	objectCount
+	fileSize
+	url
+	partialDataUrl
+`
+
+// BulkOperationService is an interface for interfacing with the bulk
+// operation mutations and queries of the GraphQL Admin API.
+// See: https://help.shopify.com/api/guides/bulk-operations
+type BulkOperationService interface {
+	RunQuery(string) (*BulkOperation, error)
+	Current() (*BulkOperation, error)
+	Cancel(string) (*BulkOperation, error)
+	Wait(time.Duration) (*BulkOperation, error)
+	Records(*BulkOperation, func(json.RawMessage) error) error
+	Reader(*BulkOperation, int64) *BulkOperationReader
+	RunMutation(string, io.Reader) (*BulkOperation, error)
+	CurrentMutation() (*BulkOperation, error)
+	WaitMutation(time.Duration) (*BulkOperation, error)
+	MutationResults(*BulkOperation, func(BulkMutationResult) error) error
+}
+
+// BulkOperationServiceOp handles communication with the bulk operation
+// related methods of the Shopify API.
+type BulkOperationServiceOp struct {
+	client *Client
+}
+
+// BulkOperation represents a Shopify bulk operation. Only one bulk operation
+// can run at a time for a shop.
+type BulkOperation struct {
+	ID             string     `json:"id"`
+	Type           string     `json:"type"`
+	Status         string     `json:"status"`
+	ErrorCode      string     `json:"errorCode"`
+	CreatedAt      *time.Time `json:"createdAt"`
+	CompletedAt    *time.Time `json:"completedAt"`
+	ObjectCount    string     `json:"objectCount"`
+	FileSize       string     `json:"fileSize"`
+	URL            string     `json:"url"`
+	PartialDataURL string     `json:"partialDataUrl"`
+}
+
+// Done reports whether the operation has stopped running, successfully or
+// not.
+func (op BulkOperation) Done() bool {
+	switch op.Status {
+	case "CREATED", "RUNNING", "CANCELING":
+		return false
+	}
+	return true
+}
+
+// RunQuery starts a bulk operation for the given query.
+func (s *BulkOperationServiceOp) RunQuery(query string) (*BulkOperation, error) {
+	mutation := `mutation bulkOperationRunQuery($query: String!) {
+		bulkOperationRunQuery(query: $query) {
+			bulkOperation {` + bulkOperationFields + `}
+			userErrors { field message }
+		}
+	}`
+
+	resource := struct {
+		BulkOperationRunQuery struct {
+			BulkOperation *BulkOperation `json:"bulkOperation"`
+			UserErrors    []UserError    `json:"userErrors"`
+		} `json:"bulkOperationRunQuery"`
+	}{}
+
+	err := s.client.GraphQL.Query(mutation, map[string]interface{}{"query": query}, &resource)
+	if err != nil {
+		return nil, err
+	}
+	result := resource.BulkOperationRunQuery
+	return result.BulkOperation, userErrorsToError(result.UserErrors)
+}
+
+// Current returns the most recent bulk query of the shop, or nil if there is
+// none.
+func (s *BulkOperationServiceOp) Current() (*BulkOperation, error) {
+	return s.current("QUERY")
+}
+
+// Return the most recent bulk operation of a type, QUERY or MUTATION.
+func (s *BulkOperationServiceOp) current(opType string) (*BulkOperation, error) {
+	query := `query currentBulkOperation($type: BulkOperationType) {
+		currentBulkOperation(type: $type) {` + bulkOperationFields + `}
+	}`
+
+	resource := struct {
+		CurrentBulkOperation *BulkOperation `json:"currentBulkOperation"`
+	}{}
+
+	err := s.client.GraphQL.Query(query, map[string]interface{}{"type": opType}, &resource)
+	return resource.CurrentBulkOperation, err
+}
+
+// Cancel a running bulk operation
+func (s *BulkOperationServiceOp) Cancel(id string) (*BulkOperation, error) {
+	mutation := `mutation bulkOperationCancel($id: ID!) {
+		bulkOperationCancel(id: $id) {
+			bulkOperation {` + bulkOperationFields + `}
+			userErrors { field message }
+		}
+	}`
+
+	resource := struct {
+		BulkOperationCancel struct {
+			BulkOperation *BulkOperation `json:"bulkOperation"`
+			UserErrors    []UserError    `json:"userErrors"`
+		} `json:"bulkOperationCancel"`
+	}{}
+
+	err := s.client.GraphQL.Query(mutation, map[string]interface{}{"id": id}, &resource)
+	if err != nil {
+		return nil, err
+	}
+	result := resource.BulkOperationCancel
+	return result.BulkOperation, userErrorsToError(result.UserErrors)
+}
+
+// Wait polls the current bulk query every interval until it is done and
+// returns it. An error is returned along with the operation if it did not
+// complete successfully.
+func (s *BulkOperationServiceOp) Wait(interval time.Duration) (*BulkOperation, error) {
+	return s.wait("QUERY", interval)
+}
+
+// Poll the current bulk operation of a type until it is done.
+func (s *BulkOperationServiceOp) wait(opType string, interval time.Duration) (*BulkOperation, error) {
+	if interval <= 0 {
+		interval = DefaultBulkOperationPollInterval
+	}
+
+	for {
+		op, err := s.current(opType)
+		if err != nil {
+			return op, err
+		}
+		if op == nil {
+			return nil, fmt.Errorf("no bulk operation is running")
+		}
+
+		if op.Done() {
+			if op.Status != "COMPLETED" {
+				return op, fmt.Errorf("bulk operation %s is %s: %s", op.ID, op.Status, op.ErrorCode)
+			}
+			return op, nil
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+// Records downloads the results of a completed bulk operation and calls fn
+// with each line of the JSONL file. Child objects, such as the variants of a
+// product, are on their own line and refer to their parent with __parentId.
+func (s *BulkOperationServiceOp) Records(op *BulkOperation, fn func(json.RawMessage) error) error {
+	reader := s.Reader(op, 0)
+	defer reader.Close()
+
+	for {
+		line := json.RawMessage{}
+		err := reader.Next(&line)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = fn(line)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// BulkOperationReader streams the records of a bulk operation's results.
+// When the download breaks it is resumed with a ranged request from the last
+// complete record, so a large file does not have to be downloaded again.
+type BulkOperationReader struct {
+	// Times a broken or failing download is attempted again before Next
+	// gives up, and the time to wait before the first attempt, which doubles
+	// with each attempt. Attempts draw from the retry budget of the client.
+	MaxRetries int
+	RetryDelay time.Duration
+
+	client *Client
+	url    string
+	offset int64
+	body   io.ReadCloser
+	reader *bufio.Reader
+}
+
+// Reader returns a reader for the results of a completed bulk operation,
+// starting at the given byte offset. Use 0 to read from the start, or the
+// Offset of an earlier reader to continue where it stopped.
+func (s *BulkOperationServiceOp) Reader(op *BulkOperation, offset int64) *BulkOperationReader {
+	reader := &BulkOperationReader{
+		MaxRetries: DefaultBulkDownloadRetries,
+		RetryDelay: DefaultBulkDownloadRetryDelay,
+		client:     s.client,
+		offset:     offset,
+	}
+	if op != nil {
+		reader.url = op.URL
+	}
+	return reader
+}
+
+// Offset returns the position in the results file of the next record. It
+// can be saved as a checkpoint and passed to Reader to resume later.
+func (r *BulkOperationReader) Offset() int64 {
+	return r.offset
+}
+
+// Next decodes the next record into v, usually a pointer to a struct. It
+// returns io.EOF when there are no more records.
+func (r *BulkOperationReader) Next(v interface{}) error {
+	if r.url == "" {
+		// Operations without results have no file
+		return io.EOF
+	}
+
+	retries := 0
+	for {
+		if r.body == nil {
+			if retries == 0 {
+				r.client.budgetRequest()
+			}
+			err := r.open()
+			if err == io.EOF {
+				return err
+			}
+			if err != nil {
+				if !retryableDownload(err) || retries >= r.MaxRetries || !r.client.budgetRetry() {
+					return err
+				}
+				r.wait(retries)
+				retries++
+				continue
+			}
+		}
+
+		line, err := r.reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			// Drop the partial line and resume from the last complete one
+			r.Close()
+			if retries >= r.MaxRetries || !r.client.budgetRetry() {
+				return err
+			}
+			r.wait(retries)
+			retries++
+			continue
+		}
+
+		r.offset += int64(len(line))
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			return json.Unmarshal(line, v)
+		}
+		if err == io.EOF {
+			return io.EOF
+		}
+	}
+}
+
+// Wait before attempting the download again, twice as long as before the
+// last attempt.
+func (r *BulkOperationReader) wait(retries int) {
+	time.Sleep(r.RetryDelay << uint(retries))
+}
+
+// Whether opening the download may succeed when attempted again: the storage
+// was rate limited or had an error, or there was no response at all.
+func retryableDownload(err error) bool {
+	if responseError, ok := err.(ResponseError); ok {
+		return responseError.Status == http.StatusTooManyRequests || responseError.Status >= 500
+	}
+	return true
+}
+
+// Close the download. The reader can still be used, and reopens the download
+// on the next call to Next.
+func (r *BulkOperationReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	r.reader = nil
+	return err
+}
+
+// Start downloading the results from the current offset.
+func (r *BulkOperationReader) open() error {
+	// The results are stored outside of Shopify so the request is made
+	// without the client's credentials.
+	req, err := http.NewRequest("GET", r.url, nil)
+	if err != nil {
+		return err
+	}
+	if r.offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", r.offset))
+	}
+
+	resp, err := r.client.Client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && r.offset > 0:
+		// The offset is at the end of the file
+		resp.Body.Close()
+		return io.EOF
+	case resp.StatusCode == http.StatusOK && r.offset > 0:
+		// The range was ignored, so skip what was already read
+		_, err := io.CopyN(ioutil.Discard, resp.Body, r.offset)
+		if err != nil {
+			resp.Body.Close()
+			return err
+		}
+	case resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent:
+		resp.Body.Close()
+		return ResponseError{Status: resp.StatusCode, Message: fmt.Sprintf("downloading bulk operation results: %s", resp.Status)}
+	}
+
+	r.body = resp.Body
+	r.reader = bufio.NewReader(resp.Body)
+	return nil
+}
@@ -0,0 +1,329 @@
+package goshopify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestBulkOperationRunQuery(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/graphql.json",
+		httpmock.NewStringResponder(200, `{"data": {"bulkOperationRunQuery": {
+			"bulkOperation": {"id": "gid://shopify/BulkOperation/1", "status": "CREATED"},
+			"userErrors": []
+		}}}`))
+
+	op, err := client.BulkOperation.RunQuery(`{ products { edges { node { id } } } }`)
+	if err != nil {
+		t.Errorf("BulkOperation.RunQuery returned error: %v", err)
+	}
+
+	if op.ID != "gid://shopify/BulkOperation/1" || op.Status != "CREATED" {
+		t.Errorf("BulkOperation.RunQuery returned %+v, expected a created operation", op)
+	}
+}
+
+func TestBulkOperationRunQueryUserErrors(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/graphql.json",
+		httpmock.NewStringResponder(200, `{"data": {"bulkOperationRunQuery": {
+			"bulkOperation": null,
+			"userErrors": [{"field": ["query"], "message": "A bulk query operation for this app and shop is already in progress"}]
+		}}}`))
+
+	_, err := client.BulkOperation.RunQuery(`{ products { edges { node { id } } } }`)
+	expected := "query: A bulk query operation for this app and shop is already in progress"
+	if err == nil || err.Error() != expected {
+		t.Errorf("BulkOperation.RunQuery returned error %v, expected %v", err, expected)
+	}
+}
+
+func TestBulkOperationCurrent(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/graphql.json",
+		httpmock.NewBytesResponder(200, loadFixture("bulkoperation.json")))
+
+	op, err := client.BulkOperation.Current()
+	if err != nil {
+		t.Errorf("BulkOperation.Current returned error: %v", err)
+	}
+
+	completedAt := time.Date(2019, time.August, 29, 17, 23, 25, 0, time.UTC)
+	cases := []struct {
+		field    string
+		expected interface{}
+		actual   interface{}
+	}{
+		{"ID", "gid://shopify/BulkOperation/720918", op.ID},
+		{"Status", "COMPLETED", op.Status},
+		{"ObjectCount", "57", op.ObjectCount},
+		{"URL", "https://storage.googleapis.com/shopify/bulk-operation-results.jsonl", op.URL},
+		{"Done", true, op.Done()},
+		{"CompletedAt", true, completedAt.Equal(*op.CompletedAt)},
+	}
+
+	for _, c := range cases {
+		if c.expected != c.actual {
+			t.Errorf("BulkOperation.%v returned %v, expected %v", c.field, c.actual, c.expected)
+		}
+	}
+}
+
+func TestBulkOperationWait(t *testing.T) {
+	setup()
+	defer teardown()
+
+	statuses := []string{"RUNNING", "RUNNING", "FAILED"}
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/graphql.json",
+		func(req *http.Request) (*http.Response, error) {
+			status := statuses[0]
+			statuses = statuses[1:]
+			return httpmock.NewStringResponse(200, `{"data": {"currentBulkOperation": {
+				"id": "gid://shopify/BulkOperation/1", "status": "`+status+`", "errorCode": "TIMEOUT"
+			}}}`), nil
+		})
+
+	op, err := client.BulkOperation.Wait(time.Millisecond)
+	expected := "bulk operation gid://shopify/BulkOperation/1 is FAILED: TIMEOUT"
+	if err == nil || err.Error() != expected {
+		t.Errorf("BulkOperation.Wait returned error %v, expected %v", err, expected)
+	}
+	if op == nil || op.Status != "FAILED" {
+		t.Errorf("BulkOperation.Wait returned %+v, expected a failed operation", op)
+	}
+	if len(statuses) != 0 {
+		t.Errorf("BulkOperation.Wait stopped with %d statuses left", len(statuses))
+	}
+}
+
+func TestBulkOperationRecords(t *testing.T) {
+	setup()
+	defer teardown()
+
+	url := "https://storage.googleapis.com/shopify/bulk-operation-results.jsonl"
+	httpmock.RegisterResponder("GET", url,
+		func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Shopify-Access-Token") != "" {
+				return httpmock.NewStringResponse(403, ""), nil
+			}
+			return httpmock.NewBytesResponse(200, loadFixture("bulk_products.jsonl")), nil
+		})
+
+	ids := []string{}
+	err := client.BulkOperation.Records(&BulkOperation{URL: url}, func(data json.RawMessage) error {
+		line := struct {
+			ID string `json:"id"`
+		}{}
+		err := json.Unmarshal(data, &line)
+		ids = append(ids, line.ID)
+		return err
+	})
+	if err != nil {
+		t.Errorf("BulkOperation.Records returned error: %v", err)
+	}
+
+	expected := "gid://shopify/Product/1,gid://shopify/ProductVariant/11,gid://shopify/Metafield/21"
+	if strings.Join(ids, ",") != expected {
+		t.Errorf("BulkOperation.Records returned %v, expected %v", ids, expected)
+	}
+
+	// Operations without results have nothing to download
+	err = client.BulkOperation.Records(&BulkOperation{}, func(data json.RawMessage) error {
+		t.Errorf("BulkOperation.Records called fn with %s, expected no calls", data)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("BulkOperation.Records returned error: %v", err)
+	}
+}
+
+func TestBulkOperationRecordsError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	url := "https://storage.googleapis.com/shopify/bulk-operation-results.jsonl"
+	httpmock.RegisterResponder("GET", url, httpmock.NewStringResponder(410, ""))
+
+	err := client.BulkOperation.Records(&BulkOperation{URL: url}, func(data json.RawMessage) error {
+		return nil
+	})
+	if responseError, ok := err.(ResponseError); !ok || responseError.Status != 410 {
+		t.Errorf("BulkOperation.Records returned error %#v, expected a 410 ResponseError", err)
+	}
+}
+
+// brokenBody returns its data up to a point and then fails like a dropped
+// connection.
+type brokenBody struct {
+	data []byte
+}
+
+func (b *brokenBody) Read(p []byte) (int, error) {
+	if len(b.data) == 0 {
+		return 0, errors.New("connection reset by peer")
+	}
+	n := copy(p, b.data)
+	b.data = b.data[n:]
+	return n, nil
+}
+
+func (b *brokenBody) Close() error {
+	return nil
+}
+
+func TestBulkOperationReaderResume(t *testing.T) {
+	setup()
+	defer teardown()
+
+	data := loadFixture("bulk_products.jsonl")
+	firstLine := bytes.IndexByte(data, '\n') + 1
+
+	url := "https://storage.googleapis.com/shopify/bulk-operation-results.jsonl"
+	ranges := []string{}
+	httpmock.RegisterResponder("GET", url,
+		func(req *http.Request) (*http.Response, error) {
+			ranges = append(ranges, req.Header.Get("Range"))
+			if len(ranges) == 1 {
+				// Break off in the middle of the second line
+				resp := httpmock.NewStringResponse(200, "")
+				resp.Body = &brokenBody{data[:firstLine+10]}
+				return resp, nil
+			}
+
+			var start int
+			fmt.Sscanf(req.Header.Get("Range"), "bytes=%d-", &start)
+			return httpmock.NewBytesResponse(206, data[start:]), nil
+		})
+
+	reader := client.BulkOperation.Reader(&BulkOperation{URL: url}, 0)
+	reader.RetryDelay = 0
+	defer reader.Close()
+
+	ids := []string{}
+	for {
+		line := struct {
+			ID string `json:"id"`
+		}{}
+		err := reader.Next(&line)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("BulkOperationReader.Next returned error: %v", err)
+		}
+		ids = append(ids, line.ID)
+	}
+
+	expected := "gid://shopify/Product/1,gid://shopify/ProductVariant/11,gid://shopify/Metafield/21"
+	if strings.Join(ids, ",") != expected {
+		t.Errorf("BulkOperationReader returned %v, expected %v", ids, expected)
+	}
+
+	expectedRanges := []string{"", fmt.Sprintf("bytes=%d-", firstLine)}
+	if !reflect.DeepEqual(ranges, expectedRanges) {
+		t.Errorf("BulkOperationReader requested ranges %q, expected %q", ranges, expectedRanges)
+	}
+
+	if reader.Offset() != int64(len(data)) {
+		t.Errorf("BulkOperationReader.Offset returned %d, expected %d", reader.Offset(), len(data))
+	}
+}
+
+func TestBulkOperationReaderOffset(t *testing.T) {
+	setup()
+	defer teardown()
+
+	data := loadFixture("bulk_products.jsonl")
+	firstLine := bytes.IndexByte(data, '\n') + 1
+
+	// A server that ignores the requested range
+	url := "https://storage.googleapis.com/shopify/bulk-operation-results.jsonl"
+	httpmock.RegisterResponder("GET", url, httpmock.NewBytesResponder(200, data))
+
+	reader := client.BulkOperation.Reader(&BulkOperation{URL: url}, int64(firstLine))
+	defer reader.Close()
+
+	line := struct {
+		ID string `json:"id"`
+	}{}
+	err := reader.Next(&line)
+	if err != nil {
+		t.Fatalf("BulkOperationReader.Next returned error: %v", err)
+	}
+	if line.ID != "gid://shopify/ProductVariant/11" {
+		t.Errorf("BulkOperationReader.Next returned %v, expected gid://shopify/ProductVariant/11", line.ID)
+	}
+}
+
+func TestBulkOperationReaderRetriesOpen(t *testing.T) {
+	setup()
+	defer teardown()
+
+	url := "https://storage.googleapis.com/shopify/bulk-operation-results.jsonl"
+	statuses := []int{503, 429, 200}
+	httpmock.RegisterResponder("GET", url,
+		func(req *http.Request) (*http.Response, error) {
+			status := statuses[0]
+			statuses = statuses[1:]
+			return httpmock.NewStringResponse(status, `{"id": "gid://shopify/Product/1"}`+"\n"), nil
+		})
+
+	reader := client.BulkOperation.Reader(&BulkOperation{URL: url}, 0)
+	reader.RetryDelay = 0
+	defer reader.Close()
+
+	line := struct {
+		ID string `json:"id"`
+	}{}
+	err := reader.Next(&line)
+	if err != nil {
+		t.Fatalf("BulkOperationReader.Next returned error: %v", err)
+	}
+	if line.ID != "gid://shopify/Product/1" || len(statuses) != 0 {
+		t.Errorf("BulkOperationReader.Next returned %v with %d responses left, expected product 1 after 3 requests", line.ID, len(statuses))
+	}
+}
+
+func TestBulkOperationReaderGivesUp(t *testing.T) {
+	setup()
+	defer teardown()
+
+	url := "https://storage.googleapis.com/shopify/bulk-operation-results.jsonl"
+	requests := 0
+	httpmock.RegisterResponder("GET", url,
+		func(req *http.Request) (*http.Response, error) {
+			requests++
+			resp := httpmock.NewStringResponse(200, "")
+			resp.Body = &brokenBody{}
+			return resp, nil
+		})
+
+	reader := client.BulkOperation.Reader(&BulkOperation{URL: url}, 0)
+	reader.MaxRetries = 2
+	reader.RetryDelay = 0
+
+	line := json.RawMessage{}
+	err := reader.Next(&line)
+	if err == nil || err == io.EOF {
+		t.Errorf("BulkOperationReader.Next returned %v, expected the read error", err)
+	}
+	if requests != 3 {
+		t.Errorf("BulkOperationReader made %d requests, expected 3", requests)
+	}
+}
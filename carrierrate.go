@@ -0,0 +1,161 @@
+package goshopify
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/shopspring/decimal"
+)
+
+// RateRequest is what Shopify asks a carrier service for shipping rates of.
+type RateRequest struct {
+	Origin      RateAddress `json:"origin"`
+	Destination RateAddress `json:"destination"`
+	Items       []RateItem  `json:"items"`
+	Currency    string      `json:"currency"`
+	Locale      string      `json:"locale"`
+}
+
+// RateAddress is the origin or destination of a rate request.
+type RateAddress struct {
+	Country     string `json:"country"`
+	PostalCode  string `json:"postal_code"`
+	Province    string `json:"province"`
+	City        string `json:"city"`
+	Name        string `json:"name"`
+	Address1    string `json:"address1"`
+	Address2    string `json:"address2"`
+	Address3    string `json:"address3"`
+	Phone       string `json:"phone"`
+	Fax         string `json:"fax"`
+	Email       string `json:"email"`
+	AddressType string `json:"address_type"`
+	CompanyName string `json:"company_name"`
+}
+
+// RateItem is an item to ship. Price is in the currency of the request, not
+// in minor units as Shopify sends it.
+type RateItem struct {
+	Name               string            `json:"name"`
+	SKU                string            `json:"sku"`
+	Quantity           int               `json:"quantity"`
+	Grams              int               `json:"grams"`
+	Price              decimal.Decimal   `json:"price"`
+	Vendor             string            `json:"vendor"`
+	RequiresShipping   bool              `json:"requires_shipping"`
+	Taxable            bool              `json:"taxable"`
+	FulfillmentService string            `json:"fulfillment_service"`
+	Properties         map[string]string `json:"properties"`
+	ProductID          uint64            `json:"product_id"`
+	VariantID          uint64            `json:"variant_id"`
+}
+
+// Rate is a shipping rate offered at checkout. TotalPrice is in Currency, or
+// the currency of the request if it is empty. ServiceCode defaults to the
+// ServiceName in upper case, e.g. "EXPRESS_SHIPPING".
+type Rate struct {
+	ServiceName     string
+	ServiceCode     string
+	Description     string
+	TotalPrice      decimal.Decimal
+	Currency        string
+	MinDeliveryDate *time.Time
+	MaxDeliveryDate *time.Time
+}
+
+// A rate as Shopify expects it, with the price in minor units
+type rateJSON struct {
+	ServiceName     string     `json:"service_name"`
+	ServiceCode     string     `json:"service_code"`
+	Description     string     `json:"description,omitempty"`
+	TotalPrice      string     `json:"total_price"`
+	Currency        string     `json:"currency"`
+	MinDeliveryDate *time.Time `json:"min_delivery_date,omitempty"`
+	MaxDeliveryDate *time.Time `json:"max_delivery_date,omitempty"`
+}
+
+// RateFunc returns the shipping rates for a request. When it returns an
+// error, Shopify shows its backup rates.
+type RateFunc func(request RateRequest) ([]Rate, error)
+
+// CarrierRateHandler returns a handler for the callback URL of a carrier
+// service, which verifies that requests come from Shopify and responds with
+// the rates returned by fn.
+func (app App) CarrierRateHandler(fn RateFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !app.VerifyWebhookRequest(r) {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		payload := struct {
+			Rate RateRequest `json:"rate"`
+		}{}
+		err := json.NewDecoder(r.Body).Decode(&payload)
+		if err != nil {
+			http.Error(w, "Cannot decode rate request", http.StatusBadRequest)
+			return
+		}
+
+		request := payload.Rate
+		minorUnit := decimal.New(1, -CurrencyPlaces(request.Currency))
+		for i := range request.Items {
+			request.Items[i].Price = request.Items[i].Price.Mul(minorUnit)
+		}
+
+		rates, err := fn(request)
+		if err != nil {
+			http.Error(w, "Cannot get rates", http.StatusInternalServerError)
+			return
+		}
+
+		response := struct {
+			Rates []rateJSON `json:"rates"`
+		}{Rates: make([]rateJSON, len(rates))}
+		for i, rate := range rates {
+			response.Rates[i] = rate.encode(request.Currency)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	})
+}
+
+// Convert a rate to what Shopify expects, with the price in minor units of
+// its currency.
+func (r Rate) encode(currency string) rateJSON {
+	if r.Currency != "" {
+		currency = r.Currency
+	}
+	code := r.ServiceCode
+	if code == "" {
+		code = serviceCode(r.ServiceName)
+	}
+	minorUnits := decimal.New(1, CurrencyPlaces(currency))
+
+	return rateJSON{
+		ServiceName:     r.ServiceName,
+		ServiceCode:     code,
+		Description:     r.Description,
+		TotalPrice:      r.TotalPrice.Mul(minorUnits).Round(0).String(),
+		Currency:        currency,
+		MinDeliveryDate: r.MinDeliveryDate,
+		MaxDeliveryDate: r.MaxDeliveryDate,
+	}
+}
+
+// Turn a service name into a code, e.g. "Express (2 days)" into
+// "EXPRESS_2_DAYS".
+func serviceCode(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.ToUpper(strings.Join(words, "_"))
+}
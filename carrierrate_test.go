@@ -0,0 +1,83 @@
+package goshopify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// Make a rate request to the handler, signed with the given secret.
+func requestRates(handler http.Handler, secret string, body []byte) *httptest.ResponseRecorder {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	req := httptest.NewRequest("POST", "/rates", bytes.NewReader(body))
+	req.Header.Set("X-Shopify-Hmac-Sha256", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestCarrierRateHandler(t *testing.T) {
+	var request RateRequest
+	handler := App{ApiSecret: "hush"}.CarrierRateHandler(func(r RateRequest) ([]Rate, error) {
+		request = r
+		return []Rate{
+			{ServiceName: "Express (2 days)", TotalPrice: decimal.New(1295, -2)},
+			{ServiceName: "Ground", ServiceCode: "GND", TotalPrice: decimal.New(5, 0), Currency: "JPY"},
+		}, nil
+	})
+
+	recorder := requestRates(handler, "hush", loadFixture("carrier_rate_request.json"))
+	if recorder.Code != 200 {
+		t.Fatalf("CarrierRateHandler responded with status %d, expected 200", recorder.Code)
+	}
+
+	if request.Destination.PostalCode != "K1M1M4" || request.Currency != "USD" {
+		t.Errorf("RateFunc got request %+v", request)
+	}
+	if len(request.Items) != 1 || !request.Items[0].Price.Equal(decimal.New(1999, -2)) {
+		t.Errorf("RateFunc got items %+v, expected one priced 19.99", request.Items)
+	}
+
+	response := map[string][]map[string]interface{}{}
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+	expected := []map[string]interface{}{
+		{"service_name": "Express (2 days)", "service_code": "EXPRESS_2_DAYS", "total_price": "1295", "currency": "USD"},
+		{"service_name": "Ground", "service_code": "GND", "total_price": "5", "currency": "JPY"},
+	}
+	if !reflect.DeepEqual(response["rates"], expected) {
+		t.Errorf("CarrierRateHandler responded with %v, expected %v", response["rates"], expected)
+	}
+}
+
+func TestCarrierRateHandlerErrors(t *testing.T) {
+	handler := App{ApiSecret: "hush"}.CarrierRateHandler(func(r RateRequest) ([]Rate, error) {
+		return nil, ResponseError{Message: "no rates"}
+	})
+
+	cases := []struct {
+		secret   string
+		body     []byte
+		expected int
+	}{
+		{"wrong", loadFixture("carrier_rate_request.json"), 401},
+		{"hush", []byte("not json"), 400},
+		{"hush", loadFixture("carrier_rate_request.json"), 500},
+	}
+
+	for _, c := range cases {
+		recorder := requestRates(handler, c.secret, c.body)
+		if recorder.Code != c.expected {
+			t.Errorf("CarrierRateHandler responded with status %d, expected %d", recorder.Code, c.expected)
+		}
+	}
+}
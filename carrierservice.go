@@ -0,0 +1,87 @@
+package goshopify
+
+import (
+	"fmt"
+)
+
+const carrierServicesBasePath = "admin/carrier_services"
+
+// CarrierServiceService is an interface for interfacing with the carrier
+// service endpoints of the Shopify API. A carrier service provides shipping
+// rates at checkout from a callback, see CarrierRateHandler.
+// See: https://help.shopify.com/api/reference/carrierservice
+type CarrierServiceService interface {
+	List() ([]CarrierService, error)
+	Get(uint64) (*CarrierService, error)
+	Create(CarrierService) (*CarrierService, error)
+	Update(CarrierService) (*CarrierService, error)
+	Delete(uint64) error
+}
+
+// CarrierServiceServiceOp handles communication with the carrier service
+// related methods of the Shopify API.
+type CarrierServiceServiceOp struct {
+	client *Client
+}
+
+// CarrierService represents a Shopify carrier service.
+type CarrierService struct {
+	ID                 uint64 `json:"id,omitempty"`
+	Name               string `json:"name,omitempty"`
+	Active             bool   `json:"active"`
+	ServiceDiscovery   bool   `json:"service_discovery"`
+	CarrierServiceType string `json:"carrier_service_type,omitempty"`
+	Format             string `json:"format,omitempty"`
+	CallbackURL        string `json:"callback_url,omitempty"`
+}
+
+// CarrierServiceResource represents the result from the
+// admin/carrier_services/{#id}.json endpoint.
+type CarrierServiceResource struct {
+	CarrierService *CarrierService `json:"carrier_service"`
+}
+
+// CarrierServicesResource represents the result from the
+// admin/carrier_services.json endpoint.
+type CarrierServicesResource struct {
+	CarrierServices []CarrierService `json:"carrier_services"`
+}
+
+// List carrier services
+func (s *CarrierServiceServiceOp) List() ([]CarrierService, error) {
+	path := fmt.Sprintf("%s.json", carrierServicesBasePath)
+	resource := &CarrierServicesResource{}
+	err := s.client.Get(path, resource, nil)
+	return resource.CarrierServices, err
+}
+
+// Get individual carrier service
+func (s *CarrierServiceServiceOp) Get(carrierServiceID uint64) (*CarrierService, error) {
+	path := fmt.Sprintf("%s/%d.json", carrierServicesBasePath, carrierServiceID)
+	resource := &CarrierServiceResource{}
+	err := s.client.Get(path, resource, nil)
+	return resource.CarrierService, err
+}
+
+// Create a new carrier service
+func (s *CarrierServiceServiceOp) Create(carrierService CarrierService) (*CarrierService, error) {
+	path := fmt.Sprintf("%s.json", carrierServicesBasePath)
+	wrappedData := CarrierServiceResource{CarrierService: &carrierService}
+	resource := &CarrierServiceResource{}
+	err := s.client.Post(path, wrappedData, resource)
+	return resource.CarrierService, err
+}
+
+// Update an existing carrier service
+func (s *CarrierServiceServiceOp) Update(carrierService CarrierService) (*CarrierService, error) {
+	path := fmt.Sprintf("%s/%d.json", carrierServicesBasePath, carrierService.ID)
+	wrappedData := CarrierServiceResource{CarrierService: &carrierService}
+	resource := &CarrierServiceResource{}
+	err := s.client.Put(path, wrappedData, resource)
+	return resource.CarrierService, err
+}
+
+// Delete an existing carrier service
+func (s *CarrierServiceServiceOp) Delete(carrierServiceID uint64) error {
+	return s.client.Delete(fmt.Sprintf("%s/%d.json", carrierServicesBasePath, carrierServiceID))
+}
@@ -0,0 +1,103 @@
+package goshopify
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+func carrierServiceTests(t *testing.T, carrierService *CarrierService) {
+	expected := &CarrierService{
+		ID:                 1036894966,
+		Name:               "Shipping Rate Provider",
+		Active:             true,
+		ServiceDiscovery:   true,
+		CarrierServiceType: "api",
+		Format:             "json",
+		CallbackURL:        "https://fakerateprovider.com/",
+	}
+	if !reflect.DeepEqual(carrierService, expected) {
+		t.Errorf("CarrierService is %+v, expected %+v", carrierService, expected)
+	}
+}
+
+func TestCarrierServiceList(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/carrier_services.json",
+		httpmock.NewStringResponder(200, `{"carrier_services": [{"id": 1}, {"id": 2}]}`))
+
+	carrierServices, err := client.CarrierService.List()
+	if err != nil {
+		t.Errorf("CarrierService.List returned error: %v", err)
+	}
+
+	expected := []CarrierService{{ID: 1}, {ID: 2}}
+	if !reflect.DeepEqual(carrierServices, expected) {
+		t.Errorf("CarrierService.List returned %+v, expected %+v", carrierServices, expected)
+	}
+}
+
+func TestCarrierServiceGet(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/carrier_services/1036894966.json",
+		httpmock.NewBytesResponder(200, loadFixture("carrier_service.json")))
+
+	carrierService, err := client.CarrierService.Get(1036894966)
+	if err != nil {
+		t.Errorf("CarrierService.Get returned error: %v", err)
+	}
+
+	carrierServiceTests(t, carrierService)
+}
+
+func TestCarrierServiceCreate(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/carrier_services.json",
+		httpmock.NewBytesResponder(200, loadFixture("carrier_service.json")))
+
+	carrierService, err := client.CarrierService.Create(CarrierService{
+		Name:             "Shipping Rate Provider",
+		CallbackURL:      "https://fakerateprovider.com/",
+		ServiceDiscovery: true,
+	})
+	if err != nil {
+		t.Errorf("CarrierService.Create returned error: %v", err)
+	}
+
+	carrierServiceTests(t, carrierService)
+}
+
+func TestCarrierServiceUpdate(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("PUT", "https://fooshop.myshopify.com/admin/carrier_services/1036894966.json",
+		httpmock.NewBytesResponder(200, loadFixture("carrier_service.json")))
+
+	carrierService, err := client.CarrierService.Update(CarrierService{ID: 1036894966, Active: true})
+	if err != nil {
+		t.Errorf("CarrierService.Update returned error: %v", err)
+	}
+
+	carrierServiceTests(t, carrierService)
+}
+
+func TestCarrierServiceDelete(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("DELETE", "https://fooshop.myshopify.com/admin/carrier_services/1036894966.json",
+		httpmock.NewStringResponder(200, "{}"))
+
+	err := client.CarrierService.Delete(1036894966)
+	if err != nil {
+		t.Errorf("CarrierService.Delete returned error: %v", err)
+	}
+}
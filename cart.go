@@ -0,0 +1,260 @@
+package goshopify
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const cartFields = `id checkoutUrl createdAt updatedAt note totalQuantity
+buyerIdentity { email phone countryCode }
+attributes { key value }
+cost { subtotalAmount { amount currencyCode } totalAmount { amount currencyCode } totalTaxAmount { amount currencyCode } }
+lines(first: 250) {
+	edges { node {
+		id quantity
+		merchandise { ... on ProductVariant { id } }
+		attributes { key value }
+		cost { amountPerQuantity { amount currencyCode } totalAmount { amount currencyCode } }
+	} }
+}`
+
+const cartQuery = `query cart($id: ID!) { cart(id: $id) { ` + cartFields + ` } }`
+
+const cartCreateMutation = `mutation cartCreate($input: CartInput) {
+	cartCreate(input: $input) { cart { ` + cartFields + ` } userErrors { field message } }
+}`
+
+const cartLinesAddMutation = `mutation cartLinesAdd($cartId: ID!, $lines: [CartLineInput!]!) {
+	cartLinesAdd(cartId: $cartId, lines: $lines) { cart { ` + cartFields + ` } userErrors { field message } }
+}`
+
+const cartLinesUpdateMutation = `mutation cartLinesUpdate($cartId: ID!, $lines: [CartLineUpdateInput!]!) {
+	cartLinesUpdate(cartId: $cartId, lines: $lines) { cart { ` + cartFields + ` } userErrors { field message } }
+}`
+
+const cartLinesRemoveMutation = `mutation cartLinesRemove($cartId: ID!, $lineIds: [ID!]!) {
+	cartLinesRemove(cartId: $cartId, lineIds: $lineIds) { cart { ` + cartFields + ` } userErrors { field message } }
+}`
+
+const cartBuyerIdentityUpdateMutation = `mutation cartBuyerIdentityUpdate($cartId: ID!, $buyerIdentity: CartBuyerIdentityInput!) {
+	cartBuyerIdentityUpdate(cartId: $cartId, buyerIdentity: $buyerIdentity) { cart { ` + cartFields + ` } userErrors { field message } }
+}`
+
+// CartService is an interface for interfacing with the cart queries and
+// mutations of the Storefront API. Carts and their lines are identified by
+// their global ids, e.g. "gid://shopify/Cart/c1-abc".
+// See: https://shopify.dev/custom-storefronts/cart
+type CartService interface {
+	Get(string) (*Cart, error)
+	Create(CartInput) (*Cart, error)
+	AddLines(string, []CartLineInput) (*Cart, error)
+	UpdateLines(string, []CartLineUpdateInput) (*Cart, error)
+	RemoveLines(string, []string) (*Cart, error)
+	UpdateBuyerIdentity(string, CartBuyerIdentityInput) (*Cart, error)
+}
+
+// CartServiceOp handles communication with the cart related methods of the
+// Storefront API.
+type CartServiceOp struct {
+	client *StorefrontClient
+}
+
+// Cart represents a Storefront API cart. CheckoutURL is where the customer
+// completes the purchase.
+type Cart struct {
+	ID            string
+	CheckoutURL   string
+	CreatedAt     *time.Time
+	UpdatedAt     *time.Time
+	Note          string
+	TotalQuantity int
+	BuyerIdentity CartBuyerIdentity
+	Attributes    []CartAttribute
+	Cost          CartCost
+	Lines         []CartLine
+}
+
+// CartLine is a variant in a cart.
+type CartLine struct {
+	ID                string
+	MerchandiseID     string
+	Quantity          int
+	Attributes        []CartAttribute
+	AmountPerQuantity StorefrontMoney
+	TotalAmount       StorefrontMoney
+}
+
+// CartCost is what a cart costs, estimated until checkout.
+type CartCost struct {
+	SubtotalAmount StorefrontMoney `json:"subtotalAmount"`
+	TotalAmount    StorefrontMoney `json:"totalAmount"`
+	TotalTaxAmount StorefrontMoney `json:"totalTaxAmount"`
+}
+
+// StorefrontMoney is an amount in a given currency, as the Storefront API
+// returns it.
+type StorefrontMoney struct {
+	Amount       decimal.Decimal `json:"amount"`
+	CurrencyCode string          `json:"currencyCode"`
+}
+
+// CartAttribute is a custom key and value of a cart or cart line.
+type CartAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// CartBuyerIdentity is who a cart is for, which decides its prices and
+// taxes.
+type CartBuyerIdentity struct {
+	Email       string `json:"email"`
+	Phone       string `json:"phone"`
+	CountryCode string `json:"countryCode"`
+}
+
+// CartInput is a cart to create.
+type CartInput struct {
+	Lines         []CartLineInput         `json:"lines,omitempty"`
+	Attributes    []CartAttribute         `json:"attributes,omitempty"`
+	Note          string                  `json:"note,omitempty"`
+	DiscountCodes []string                `json:"discountCodes,omitempty"`
+	BuyerIdentity *CartBuyerIdentityInput `json:"buyerIdentity,omitempty"`
+}
+
+// CartLineInput is a line to add to a cart, with the global id of a variant
+// as MerchandiseID.
+type CartLineInput struct {
+	MerchandiseID string          `json:"merchandiseId"`
+	Quantity      int             `json:"quantity,omitempty"`
+	Attributes    []CartAttribute `json:"attributes,omitempty"`
+}
+
+// CartLineUpdateInput changes a line of a cart. A quantity of 0 leaves it as
+// it is; use RemoveLines to remove a line.
+type CartLineUpdateInput struct {
+	ID            string          `json:"id"`
+	MerchandiseID string          `json:"merchandiseId,omitempty"`
+	Quantity      int             `json:"quantity,omitempty"`
+	Attributes    []CartAttribute `json:"attributes,omitempty"`
+}
+
+// CartBuyerIdentityInput sets who a cart is for. CustomerAccessToken links the
+// cart to a logged in customer.
+type CartBuyerIdentityInput struct {
+	Email               string `json:"email,omitempty"`
+	Phone               string `json:"phone,omitempty"`
+	CountryCode         string `json:"countryCode,omitempty"`
+	CustomerAccessToken string `json:"customerAccessToken,omitempty"`
+}
+
+// A cart as the Storefront API returns it
+type cartJSON struct {
+	ID            string            `json:"id"`
+	CheckoutURL   string            `json:"checkoutUrl"`
+	CreatedAt     *time.Time        `json:"createdAt"`
+	UpdatedAt     *time.Time        `json:"updatedAt"`
+	Note          string            `json:"note"`
+	TotalQuantity int               `json:"totalQuantity"`
+	BuyerIdentity CartBuyerIdentity `json:"buyerIdentity"`
+	Attributes    []CartAttribute   `json:"attributes"`
+	Cost          CartCost          `json:"cost"`
+	Lines         struct {
+		Edges []struct {
+			Node struct {
+				ID          string `json:"id"`
+				Quantity    int    `json:"quantity"`
+				Merchandise struct {
+					ID string `json:"id"`
+				} `json:"merchandise"`
+				Attributes []CartAttribute `json:"attributes"`
+				Cost       struct {
+					AmountPerQuantity StorefrontMoney `json:"amountPerQuantity"`
+					TotalAmount       StorefrontMoney `json:"totalAmount"`
+				} `json:"cost"`
+			} `json:"node"`
+		} `json:"edges"`
+	} `json:"lines"`
+}
+
+func (c *cartJSON) cart() *Cart {
+	if c == nil {
+		return nil
+	}
+
+	cart := &Cart{
+		ID:            c.ID,
+		CheckoutURL:   c.CheckoutURL,
+		CreatedAt:     c.CreatedAt,
+		UpdatedAt:     c.UpdatedAt,
+		Note:          c.Note,
+		TotalQuantity: c.TotalQuantity,
+		BuyerIdentity: c.BuyerIdentity,
+		Attributes:    c.Attributes,
+		Cost:          c.Cost,
+		Lines:         make([]CartLine, len(c.Lines.Edges)),
+	}
+	for i, edge := range c.Lines.Edges {
+		cart.Lines[i] = CartLine{
+			ID:                edge.Node.ID,
+			MerchandiseID:     edge.Node.Merchandise.ID,
+			Quantity:          edge.Node.Quantity,
+			Attributes:        edge.Node.Attributes,
+			AmountPerQuantity: edge.Node.Cost.AmountPerQuantity,
+			TotalAmount:       edge.Node.Cost.TotalAmount,
+		}
+	}
+	return cart
+}
+
+// Run a cart mutation and return the cart it results in.
+func (s *CartServiceOp) mutate(mutation, name string, variables map[string]interface{}) (*Cart, error) {
+	data := map[string]*struct {
+		Cart       *cartJSON   `json:"cart"`
+		UserErrors []UserError `json:"userErrors"`
+	}{}
+	err := s.client.Query(mutation, variables, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := data[name]
+	if result == nil {
+		return nil, nil
+	}
+	return result.Cart.cart(), userErrorsToError(result.UserErrors)
+}
+
+// Get a cart, or nil if it does not exist
+func (s *CartServiceOp) Get(cartID string) (*Cart, error) {
+	data := struct {
+		Cart *cartJSON `json:"cart"`
+	}{}
+	err := s.client.Query(cartQuery, map[string]interface{}{"id": cartID}, &data)
+	return data.Cart.cart(), err
+}
+
+// Create a new cart
+func (s *CartServiceOp) Create(input CartInput) (*Cart, error) {
+	return s.mutate(cartCreateMutation, "cartCreate", map[string]interface{}{"input": input})
+}
+
+// AddLines adds lines to a cart
+func (s *CartServiceOp) AddLines(cartID string, lines []CartLineInput) (*Cart, error) {
+	return s.mutate(cartLinesAddMutation, "cartLinesAdd", map[string]interface{}{"cartId": cartID, "lines": lines})
+}
+
+// UpdateLines changes lines of a cart
+func (s *CartServiceOp) UpdateLines(cartID string, lines []CartLineUpdateInput) (*Cart, error) {
+	return s.mutate(cartLinesUpdateMutation, "cartLinesUpdate", map[string]interface{}{"cartId": cartID, "lines": lines})
+}
+
+// RemoveLines removes lines from a cart
+func (s *CartServiceOp) RemoveLines(cartID string, lineIDs []string) (*Cart, error) {
+	return s.mutate(cartLinesRemoveMutation, "cartLinesRemove", map[string]interface{}{"cartId": cartID, "lineIds": lineIDs})
+}
+
+// UpdateBuyerIdentity sets who a cart is for
+func (s *CartServiceOp) UpdateBuyerIdentity(cartID string, buyerIdentity CartBuyerIdentityInput) (*Cart, error) {
+	return s.mutate(cartBuyerIdentityUpdateMutation, "cartBuyerIdentityUpdate", map[string]interface{}{"cartId": cartID, "buyerIdentity": buyerIdentity})
+}
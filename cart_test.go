@@ -0,0 +1,153 @@
+package goshopify
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+const storefrontGraphQLURL = "https://fooshop.myshopify.com/api/" + StorefrontAPIVersion + "/graphql.json"
+
+// A request made to the Storefront API
+type storefrontRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// Set up a storefront client whose requests are recorded and answered with
+// the cart fixture under the given name, e.g. "cartCreate".
+func setupCart(name string) (*StorefrontClient, *[]storefrontRequest) {
+	storefront := NewStorefrontClient("fooshop", "storefronttoken")
+	httpmock.ActivateNonDefault(storefront.Client)
+
+	requests := []storefrontRequest{}
+	httpmock.RegisterResponder("POST", storefrontGraphQLURL,
+		func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Shopify-Storefront-Access-Token") != "storefronttoken" {
+				return httpmock.NewStringResponse(401, `{"errors": "Unauthorized"}`), nil
+			}
+			body := storefrontRequest{}
+			json.NewDecoder(req.Body).Decode(&body)
+			requests = append(requests, body)
+
+			cart := string(loadFixture("cart.json"))
+			if name == "cart" {
+				return httpmock.NewStringResponse(200, `{"data": {"cart": `+cart+`}}`), nil
+			}
+			return httpmock.NewStringResponse(200, `{"data": {"`+name+`": {"cart": `+cart+`, "userErrors": []}}}`), nil
+		})
+	return storefront, &requests
+}
+
+func cartTests(t *testing.T, cart *Cart) {
+	if cart == nil {
+		t.Fatalf("Cart is nil")
+	}
+
+	createdAt := time.Date(2023, 1, 10, 12, 0, 0, 0, time.UTC)
+	expected := CartLine{
+		ID:                "gid://shopify/CartLine/1",
+		MerchandiseID:     "gid://shopify/ProductVariant/808950810",
+		Quantity:          2,
+		Attributes:        []CartAttribute{},
+		AmountPerQuantity: StorefrontMoney{Amount: decimal.New(1999, -2), CurrencyCode: "CAD"},
+		TotalAmount:       StorefrontMoney{Amount: decimal.New(3998, -2), CurrencyCode: "CAD"},
+	}
+	if cart.ID != "gid://shopify/Cart/c1-7a2abe82733a34e84aa472d57fb5c3c1" || !cart.CreatedAt.Equal(createdAt) || cart.TotalQuantity != 2 {
+		t.Errorf("Cart is %+v", cart)
+	}
+	if cart.BuyerIdentity.CountryCode != "CA" || !cart.Cost.TotalAmount.Amount.Equal(decimal.New(4518, -2)) {
+		t.Errorf("Cart has buyer identity %+v and cost %+v", cart.BuyerIdentity, cart.Cost)
+	}
+	if len(cart.Lines) != 1 || cart.Lines[0].ID != expected.ID || !cart.Lines[0].TotalAmount.Amount.Equal(expected.TotalAmount.Amount) ||
+		cart.Lines[0].MerchandiseID != expected.MerchandiseID || cart.Lines[0].Quantity != expected.Quantity {
+		t.Errorf("Cart has lines %+v, expected %+v", cart.Lines, expected)
+	}
+}
+
+func TestCartGet(t *testing.T) {
+	storefront, requests := setupCart("cart")
+	defer teardown()
+
+	cart, err := storefront.Cart.Get("gid://shopify/Cart/c1-7a2abe82733a34e84aa472d57fb5c3c1")
+	if err != nil {
+		t.Fatalf("Cart.Get returned error: %v", err)
+	}
+
+	cartTests(t, cart)
+	if (*requests)[0].Variables["id"] != "gid://shopify/Cart/c1-7a2abe82733a34e84aa472d57fb5c3c1" {
+		t.Errorf("Cart.Get sent variables %v", (*requests)[0].Variables)
+	}
+}
+
+func TestCartMutations(t *testing.T) {
+	cartID := "gid://shopify/Cart/c1-7a2abe82733a34e84aa472d57fb5c3c1"
+	line := CartLineInput{MerchandiseID: "gid://shopify/ProductVariant/808950810", Quantity: 2}
+
+	cases := []struct {
+		name      string
+		mutate    func(CartService) (*Cart, error)
+		variables string
+	}{
+		{"cartCreate", func(s CartService) (*Cart, error) {
+			return s.Create(CartInput{Lines: []CartLineInput{line}, Note: "Gift wrap please"})
+		}, `{"input": {"lines": [{"merchandiseId": "gid://shopify/ProductVariant/808950810", "quantity": 2}], "note": "Gift wrap please"}}`},
+		{"cartLinesAdd", func(s CartService) (*Cart, error) {
+			return s.AddLines(cartID, []CartLineInput{line})
+		}, `{"cartId": "` + cartID + `", "lines": [{"merchandiseId": "gid://shopify/ProductVariant/808950810", "quantity": 2}]}`},
+		{"cartLinesUpdate", func(s CartService) (*Cart, error) {
+			return s.UpdateLines(cartID, []CartLineUpdateInput{{ID: "gid://shopify/CartLine/1", Quantity: 2}})
+		}, `{"cartId": "` + cartID + `", "lines": [{"id": "gid://shopify/CartLine/1", "quantity": 2}]}`},
+		{"cartLinesRemove", func(s CartService) (*Cart, error) {
+			return s.RemoveLines(cartID, []string{"gid://shopify/CartLine/2"})
+		}, `{"cartId": "` + cartID + `", "lineIds": ["gid://shopify/CartLine/2"]}`},
+		{"cartBuyerIdentityUpdate", func(s CartService) (*Cart, error) {
+			return s.UpdateBuyerIdentity(cartID, CartBuyerIdentityInput{Email: "bob@example.com", CountryCode: "CA"})
+		}, `{"cartId": "` + cartID + `", "buyerIdentity": {"email": "bob@example.com", "countryCode": "CA"}}`},
+	}
+
+	for _, c := range cases {
+		storefront, requests := setupCart(c.name)
+
+		cart, err := c.mutate(storefront.Cart)
+		if err != nil {
+			t.Errorf("%v returned error: %v", c.name, err)
+		}
+		cartTests(t, cart)
+
+		request := (*requests)[0]
+		expected := map[string]interface{}{}
+		json.Unmarshal([]byte(c.variables), &expected)
+		if !strings.Contains(request.Query, c.name+"(") || !reflect.DeepEqual(request.Variables, expected) {
+			t.Errorf("%v sent %v with %v, expected variables %v", c.name, request.Query, request.Variables, expected)
+		}
+
+		teardown()
+	}
+}
+
+func TestCartUserErrors(t *testing.T) {
+	storefront := NewStorefrontClient("fooshop", "storefronttoken")
+	httpmock.ActivateNonDefault(storefront.Client)
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", storefrontGraphQLURL,
+		httpmock.NewStringResponder(200, `{"data": {"cartLinesAdd": {"cart": null, "userErrors": [
+			{"field": ["lines", "0", "merchandiseId"], "message": "The merchandise with id 1 does not exist."}]}}}`))
+
+	_, err := storefront.Cart.AddLines("gid://shopify/Cart/1", []CartLineInput{{MerchandiseID: "1"}})
+	expected := ResponseError{
+		Status:  200,
+		Message: "lines.0.merchandiseId: The merchandise with id 1 does not exist.",
+		Errors:  []string{"lines.0.merchandiseId: The merchandise with id 1 does not exist."},
+	}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("Cart.AddLines returned error %#v, expected %#v", err, expected)
+	}
+}
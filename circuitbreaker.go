@@ -0,0 +1,134 @@
+package goshopify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CircuitOpenError is returned without making a request while the circuit
+// breaker of a client is open.
+type CircuitOpenError struct {
+	Shop    string
+	RetryAt time.Time
+}
+
+func (e CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker for %s is open until %s", e.Shop, e.RetryAt.Format(time.RFC3339))
+}
+
+const (
+	circuitClosed = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreaker stops requests to a shop after a number of consecutive
+// failures, and lets a single probe through once the cooldown has passed.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	state    int
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// WithCircuitBreaker makes the client stop making requests for cooldown after
+// threshold consecutive requests failed, i.e. timed out, could not connect or
+// got a server error, and return a CircuitOpenError instead. After the
+// cooldown one request is let through, and the breaker closes when it
+// succeeds. Since a client is for one shop, a slow or failing shop does not
+// hold up workers that serve other shops.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *Client) {
+		c.breaker = &circuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+	}
+}
+
+// WithEndpointTimeouts sets timeouts for requests by path, e.g.
+// "admin/orders" for all orders endpoints, or "admin/shop.json". The
+// timeout of the longest matching path is used, and the one of "" for
+// requests that match none.
+func WithEndpointTimeouts(timeouts map[string]time.Duration) Option {
+	return func(c *Client) {
+		c.timeouts = make(map[string]time.Duration)
+		for path, timeout := range timeouts {
+			c.timeouts[strings.TrimPrefix(path, "/")] = timeout
+		}
+	}
+}
+
+// Return the timeout for a request, or 0 if it has none.
+func (c *Client) timeout(req *http.Request) time.Duration {
+	path := strings.TrimPrefix(req.URL.Path, "/")
+	match, timeout := -1, time.Duration(0)
+	for prefix, t := range c.timeouts {
+		if strings.HasPrefix(path, prefix) && len(prefix) > match {
+			match, timeout = len(prefix), t
+		}
+	}
+	return timeout
+}
+
+// Return a CircuitOpenError if a request may not be made now.
+func (b *circuitBreaker) allow(shop string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		retryAt := b.openedAt.Add(b.cooldown)
+		if b.now().Before(retryAt) {
+			return CircuitOpenError{Shop: shop, RetryAt: retryAt}
+		}
+		b.state = circuitHalfOpen
+		b.probing = true
+	case circuitHalfOpen:
+		if b.probing {
+			return CircuitOpenError{Shop: shop, RetryAt: b.now().Add(b.cooldown)}
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Record the outcome of a request that was allowed.
+func (b *circuitBreaker) done(ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ok {
+		b.state = circuitClosed
+		b.failures = 0
+		b.probing = false
+		return
+	}
+
+	b.failures++
+	if b.state == circuitHalfOpen || b.failures >= b.threshold {
+		b.state = circuitOpen
+		b.openedAt = b.now()
+		b.probing = false
+	}
+}
+
+// Whether a request failed in a way that counts against the shop: no
+// response, e.g. because it timed out, or a server error. Rate limits, other
+// client errors and requests canceled by the caller do not count.
+func requestFailed(resp *http.Response, err error) bool {
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+	if err != nil {
+		return err != context.Canceled
+	}
+	return resp.StatusCode >= 500
+}
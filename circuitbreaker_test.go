@@ -0,0 +1,149 @@
+package goshopify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	setup()
+	defer teardown()
+
+	client = NewClient(app, "fooshop", "abcd", WithCircuitBreaker(2, time.Minute))
+	httpmock.ActivateNonDefault(client.Client)
+
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	client.breaker.now = func() time.Time { return now }
+
+	status := 500
+	requests := 0
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/shop.json",
+		func(req *http.Request) (*http.Response, error) {
+			requests++
+			return httpmock.NewStringResponse(status, `{"shop": {"id": 1}}`), nil
+		})
+
+	client.Shop.Get(nil)
+	client.Shop.Get(nil)
+	_, err := client.Shop.Get(nil)
+	expected := CircuitOpenError{Shop: "fooshop.myshopify.com", RetryAt: now.Add(time.Minute)}
+	if err != expected {
+		t.Errorf("Shop.Get returned error %#v, expected %#v", err, expected)
+	}
+	if requests != 2 {
+		t.Errorf("Made %d requests while the circuit was open, expected 2", requests)
+	}
+
+	// A failed probe opens the circuit again
+	now = now.Add(time.Minute)
+	client.Shop.Get(nil)
+	if requests != 3 {
+		t.Errorf("Made %d requests after the cooldown, expected 3", requests)
+	}
+	if _, err := client.Shop.Get(nil); err == nil {
+		t.Errorf("Shop.Get did not return an error after a failed probe")
+	}
+
+	// A successful probe closes it
+	now = now.Add(time.Minute)
+	status = 200
+	if _, err := client.Shop.Get(nil); err != nil {
+		t.Errorf("Shop.Get returned error %v after the cooldown", err)
+	}
+	if _, err := client.Shop.Get(nil); err != nil {
+		t.Errorf("Shop.Get returned error %v after a successful probe", err)
+	}
+	if requests != 5 {
+		t.Errorf("Made %d requests, expected 5", requests)
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := &circuitBreaker{threshold: 1, cooldown: time.Minute, now: func() time.Time { return now }}
+
+	b.allow("fooshop")
+	b.done(false)
+	now = now.Add(time.Minute)
+
+	if err := b.allow("fooshop"); err != nil {
+		t.Errorf("circuitBreaker.allow returned error %v for the probe", err)
+	}
+	if err := b.allow("fooshop"); err == nil {
+		t.Errorf("circuitBreaker.allow let a second request through while probing")
+	}
+	b.done(true)
+	if err := b.allow("fooshop"); err != nil {
+		t.Errorf("circuitBreaker.allow returned error %v after the probe succeeded", err)
+	}
+}
+
+func TestRequestFailed(t *testing.T) {
+	cases := []struct {
+		status   int
+		err      error
+		expected bool
+	}{
+		{200, nil, false},
+		{404, nil, false},
+		{429, nil, false},
+		{500, nil, true},
+		{503, nil, true},
+		{0, errors.New("connection refused"), true},
+		{0, context.DeadlineExceeded, true},
+		{0, context.Canceled, false},
+	}
+
+	for _, c := range cases {
+		var resp *http.Response
+		if c.err == nil {
+			resp = &http.Response{StatusCode: c.status}
+		}
+		if actual := requestFailed(resp, c.err); actual != c.expected {
+			t.Errorf("requestFailed(%d, %v) returned %v, expected %v", c.status, c.err, actual, c.expected)
+		}
+	}
+}
+
+func TestEndpointTimeouts(t *testing.T) {
+	setup()
+	defer teardown()
+
+	client = NewClient(app, "fooshop", "abcd", WithEndpointTimeouts(map[string]time.Duration{
+		"":                 time.Minute,
+		"/admin/orders":    time.Millisecond,
+		"admin/orders/123": time.Hour,
+	}))
+	httpmock.ActivateNonDefault(client.Client)
+
+	cases := []struct {
+		path     string
+		expected time.Duration
+	}{
+		{"/admin/shop.json", time.Minute},
+		{"/admin/orders.json", time.Millisecond},
+		{"/admin/orders/123.json", time.Hour},
+	}
+	for _, c := range cases {
+		req, _ := client.NewRequest("GET", c.path, nil, nil)
+		if actual := client.timeout(req); actual != c.expected {
+			t.Errorf("Client.timeout(%s) returned %v, expected %v", c.path, actual, c.expected)
+		}
+	}
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/orders.json",
+		func(req *http.Request) (*http.Response, error) {
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		})
+
+	_, err := client.Order.List(nil)
+	if err == nil {
+		t.Errorf("Order.List did not time out")
+	}
+}
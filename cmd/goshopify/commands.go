@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	goshopify "github.com/getconversio/go-shopify"
+)
+
+var errUsage = errors.New("wrong number of arguments, see goshopify -h")
+
+// Run a command with its arguments, reading JSON input from stdin when no file
+// is given and writing the results to stdout.
+func run(client *goshopify.Client, args []string, stdin io.Reader, stdout io.Writer) error {
+	command, args := args[0], args[1:]
+
+	switch command {
+	case "list":
+		if len(args) < 1 {
+			return errUsage
+		}
+		query, err := parseQuery(args[1:])
+		if err != nil {
+			return err
+		}
+		path := fmt.Sprintf("admin/%s.json", args[0])
+		if query != "" {
+			path += "?" + query
+		}
+		return get(client, path, stdout)
+	case "get":
+		if len(args) != 2 {
+			return errUsage
+		}
+		return get(client, fmt.Sprintf("admin/%s/%s.json", args[0], args[1]), stdout)
+	case "create":
+		if len(args) < 1 || len(args) > 2 {
+			return errUsage
+		}
+		data, err := readRecord(args[0], args[1:], stdin)
+		if err != nil {
+			return err
+		}
+		var result interface{}
+		err = client.Post(fmt.Sprintf("admin/%s.json", args[0]), data, &result)
+		if err != nil {
+			return err
+		}
+		return write(stdout, result)
+	case "update":
+		if len(args) < 2 || len(args) > 3 {
+			return errUsage
+		}
+		data, err := readRecord(args[0], args[2:], stdin)
+		if err != nil {
+			return err
+		}
+		var result interface{}
+		err = client.Put(fmt.Sprintf("admin/%s/%s.json", args[0], args[1]), data, &result)
+		if err != nil {
+			return err
+		}
+		return write(stdout, result)
+	case "delete":
+		if len(args) != 2 {
+			return errUsage
+		}
+		return client.Delete(fmt.Sprintf("admin/%s/%s.json", args[0], args[1]))
+	case "graphql":
+		if len(args) > 1 {
+			return errUsage
+		}
+		query, err := readInput(args, stdin)
+		if err != nil {
+			return err
+		}
+		var result interface{}
+		err = client.GraphQL.Query(string(query), nil, &result)
+		if err != nil {
+			return err
+		}
+		return write(stdout, result)
+	case "bulk":
+		if len(args) > 1 {
+			return errUsage
+		}
+		query, err := readInput(args, stdin)
+		if err != nil {
+			return err
+		}
+		return bulk(client, string(query), stdout)
+	}
+
+	return fmt.Errorf("unknown command %v, see goshopify -h", command)
+}
+
+func get(client *goshopify.Client, path string, stdout io.Writer) error {
+	var result interface{}
+	err := client.Get(path, &result, nil)
+	if err != nil {
+		return err
+	}
+	return write(stdout, result)
+}
+
+// Run a bulk query, wait for it and write its results as JSONL.
+func bulk(client *goshopify.Client, query string, stdout io.Writer) error {
+	_, err := client.BulkOperation.RunQuery(query)
+	if err != nil {
+		return err
+	}
+
+	op, err := client.BulkOperation.Wait(goshopify.DefaultBulkOperationPollInterval)
+	if err != nil {
+		return err
+	}
+
+	return client.BulkOperation.Records(op, func(line json.RawMessage) error {
+		_, err := fmt.Fprintf(stdout, "%s\n", line)
+		return err
+	})
+}
+
+// Turn key=value arguments into a query string.
+func parseQuery(args []string) (string, error) {
+	query := url.Values{}
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			return "", fmt.Errorf("option %v is not of the form key=value", arg)
+		}
+		query.Add(parts[0], parts[1])
+	}
+	return query.Encode(), nil
+}
+
+// Read the contents of the file named in args, or stdin if there is none.
+func readInput(args []string, stdin io.Reader) ([]byte, error) {
+	if len(args) > 0 {
+		return ioutil.ReadFile(args[0])
+	}
+	return ioutil.ReadAll(stdin)
+}
+
+// Read a JSON record of the resource and wrap it in its root object, unless
+// it already is.
+func readRecord(resource string, args []string, stdin io.Reader) (interface{}, error) {
+	data, err := readInput(args, stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	record := map[string]interface{}{}
+	err = json.Unmarshal(data, &record)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read record: %v", err)
+	}
+
+	root := singular(resource)
+	if _, ok := record[root]; ok && len(record) == 1 {
+		return record, nil
+	}
+	return map[string]interface{}{root: record}, nil
+}
+
+// Return the name of a single record of a resource, e.g. "variant" for
+// "products/1/variants".
+func singular(resource string) string {
+	name := resource[strings.LastIndex(resource, "/")+1:]
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"):
+		return strings.TrimSuffix(name, "es")
+	}
+	return strings.TrimSuffix(name, "s")
+}
+
+func write(stdout io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(stdout, "%s\n", data)
+	return err
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	goshopify "github.com/getconversio/go-shopify"
+	"github.com/getconversio/go-shopify/testserver"
+)
+
+func TestRun(t *testing.T) {
+	server := testserver.New()
+	defer server.Close()
+	client := server.NewClient(goshopify.App{}, "fooshop", "abcd")
+
+	// Run a command and decode its output
+	runJSON := func(stdin string, args ...string) map[string]interface{} {
+		stdout := new(bytes.Buffer)
+		err := run(client, args, strings.NewReader(stdin), stdout)
+		if err != nil {
+			t.Fatalf("run(%v) returned error: %v", args, err)
+		}
+		result := map[string]interface{}{}
+		json.Unmarshal(stdout.Bytes(), &result)
+		return result
+	}
+
+	created := runJSON(`{"title": "Shirt"}`, "create", "products")
+	product := created["product"].(map[string]interface{})
+	if product["title"] != "Shirt" {
+		t.Errorf("create returned %v, expected a product titled Shirt", created)
+	}
+	id := fmt.Sprintf("%.0f", product["id"])
+
+	runJSON(`{"product": {"title": "T-Shirt"}}`, "update", "products", id)
+	got := runJSON("", "get", "products", id)
+	if got["product"].(map[string]interface{})["title"] != "T-Shirt" {
+		t.Errorf("get returned %v, expected a product titled T-Shirt", got)
+	}
+
+	runJSON(`{"title": "Hat"}`, "create", "products")
+	list := runJSON("", "list", "products", "limit=1", "page=2")
+	products := list["products"].([]interface{})
+	if len(products) != 1 || products[0].(map[string]interface{})["title"] != "Hat" {
+		t.Errorf("list returned %v, expected the hat", list)
+	}
+
+	err := run(client, []string{"delete", "products", id}, nil, new(bytes.Buffer))
+	if err != nil {
+		t.Errorf("delete returned error: %v", err)
+	}
+	if server.Count("products") != 1 {
+		t.Errorf("delete left %d products, expected 1", server.Count("products"))
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	cases := [][]string{
+		{"list"},
+		{"get", "products"},
+		{"list", "products", "limit"},
+		{"frobnicate"},
+	}
+
+	for _, args := range cases {
+		err := run(nil, args, nil, new(bytes.Buffer))
+		if err == nil {
+			t.Errorf("run(%v) returned no error", args)
+		}
+	}
+}
+
+func TestSingular(t *testing.T) {
+	cases := []struct {
+		in, expected string
+	}{
+		{"products", "product"},
+		{"custom_collections", "custom_collection"},
+		{"products/1/variants", "variant"},
+		{"customers/1/addresses", "address"},
+		{"countries", "country"},
+	}
+
+	for _, c := range cases {
+		actual := singular(c.in)
+		if actual != c.expected {
+			t.Errorf("singular(%s): expected %s, actual %s", c.in, c.expected, actual)
+		}
+	}
+}
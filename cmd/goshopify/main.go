@@ -0,0 +1,53 @@
+// Command goshopify makes ad-hoc calls to a shop's admin API.
+//
+// Usage:
+//
+//	goshopify [-shop name] [-token token] command [arguments]
+//
+// The commands are:
+//
+//	list <resource> [key=value...]    list records, e.g. list products limit=5
+//	get <resource> <id>               get a record
+//	create <resource> [file]          create a record from JSON
+//	update <resource> <id> [file]     update a record from JSON
+//	delete <resource> <id>            delete a record
+//	graphql [file]                    run a GraphQL query
+//	bulk [file]                       run a bulk query and write its JSONL results
+//
+// Resources are named as in the REST paths, e.g. products, custom_collections
+// or products/632910392/variants. JSON is read from the file, or from standard
+// input if no file is given, and is either the record itself or wrapped as
+// Shopify returns it. The shop and token default to the SHOPIFY_SHOP and
+// SHOPIFY_TOKEN environment variables.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	goshopify "github.com/getconversio/go-shopify"
+)
+
+func main() {
+	shop := flag.String("shop", os.Getenv("SHOPIFY_SHOP"), "shop name, e.g. theshop or theshop.myshopify.com")
+	token := flag.String("token", os.Getenv("SHOPIFY_TOKEN"), "access token of the shop")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: goshopify [-shop name] [-token token] command [arguments]\n\n")
+		fmt.Fprintf(os.Stderr, "commands: list, get, create, update, delete, graphql, bulk\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *shop == "" || *token == "" || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	client := goshopify.NewClient(goshopify.App{}, *shop, *token)
+	err := run(client, flag.Args(), os.Stdin, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "goshopify: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,52 @@
+// Command shopifyschema writes the snapshot of an API version that the
+// contract tests check the models against.
+//
+// Usage:
+//
+//	shopifyschema [-shop name] [-token token] [-o file] version
+//
+// To refresh a snapshot, run it against a development store from the root of
+// the repository:
+//
+//	go run ./cmd/shopifyschema -o fixtures/schema/2019-04.json 2019-04
+//
+// The GraphQL types are taken from the introspection of the version's Admin
+// API schema. Shopify does not publish the REST resources in a form that can
+// be read, so their attributes are taken from the version's responses, and the
+// store needs at least one of each resource, including an order with a
+// fulfillment, a transaction and a risk. Write-only and contextual attributes
+// are never or not always returned, so they are kept from the snapshot being
+// refreshed. The shop and token default to the SHOPIFY_SHOP and SHOPIFY_TOKEN
+// environment variables.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	goshopify "github.com/getconversio/go-shopify"
+)
+
+func main() {
+	shop := flag.String("shop", os.Getenv("SHOPIFY_SHOP"), "shop name, e.g. theshop or theshop.myshopify.com")
+	token := flag.String("token", os.Getenv("SHOPIFY_TOKEN"), "access token of the shop")
+	output := flag.String("o", "", "snapshot file to refresh, instead of writing to standard output")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: shopifyschema [-shop name] [-token token] [-o file] version\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *shop == "" || *token == "" || flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	client := goshopify.NewClient(goshopify.App{}, *shop, *token)
+	err := run(client, flag.Arg(0), *output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "shopifyschema: %v\n", err)
+		os.Exit(1)
+	}
+}
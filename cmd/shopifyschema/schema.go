@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	goshopify "github.com/getconversio/go-shopify"
+)
+
+// The snapshot of an API version, as read by the contract tests
+type snapshot struct {
+	Version   string                     `json:"version"`
+	Resources map[string]*resourceSchema `json:"resources"`
+	GraphQL   map[string][]string        `json:"graphql"`
+}
+
+type resourceSchema struct {
+	Fields     []string `json:"fields"`
+	WriteOnly  []string `json:"write_only,omitempty"`
+	Contextual []string `json:"contextual,omitempty"`
+}
+
+// Where the records of each REST resource are listed, and the key they are
+// returned under. {order_id} and {product_id} stand for the first order and
+// product of the store, and {inventory_item_id} for the item of the product's
+// first variant.
+var restResources = map[string]struct{ path, key string }{
+	"product":           {"products.json", "products"},
+	"variant":           {"products/{product_id}/variants.json", "variants"},
+	"image":             {"products/{product_id}/images.json", "images"},
+	"customer":          {"customers.json", "customers"},
+	"custom_collection": {"custom_collections.json", "custom_collections"},
+	"smart_collection":  {"smart_collections.json", "smart_collections"},
+	"webhook":           {"webhooks.json", "webhooks"},
+	"metafield":         {"metafields.json", "metafields"},
+	"script_tag":        {"script_tags.json", "script_tags"},
+	"order":             {"orders.json?status=any", "orders"},
+	"order_risk":        {"orders/{order_id}/risks.json", "risks"},
+	"transaction":       {"orders/{order_id}/transactions.json", "transactions"},
+	"fulfillment":       {"orders/{order_id}/fulfillments.json", "fulfillments"},
+	"carrier_service":   {"carrier_services.json", "carrier_services"},
+	"shop":              {"shop.json", "shop"},
+	"collect":           {"collects.json", "collects"},
+	"inventory_level":   {"inventory_levels.json?inventory_item_ids={inventory_item_id}", "inventory_levels"},
+}
+
+// The GraphQL types that models are decoded from
+var graphQLTypes = []string{
+	"Customer",
+	"InventoryItem",
+	"InventoryLevel",
+	"Location",
+	"Metafield",
+	"MoneyBag",
+	"MoneyV2",
+	"Order",
+	"Product",
+	"ProductVariant",
+}
+
+const typeFieldsQuery = `query($name: String!) {
+  __type(name: $name) {
+    fields(includeDeprecated: true) {
+      name
+    }
+  }
+}`
+
+// Build the snapshot of the version and write it to the output file, or to
+// stdout if there is none.
+func run(client *goshopify.Client, version, output string) error {
+	previous := snapshot{}
+	if output != "" {
+		data, err := ioutil.ReadFile(output)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err == nil {
+			err = json.Unmarshal(data, &previous)
+			if err != nil {
+				return fmt.Errorf("cannot read %v: %v", output, err)
+			}
+		}
+	}
+
+	current, err := build(client, version, previous)
+	if err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	err = writeSnapshot(buf, current)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		_, err = io.Copy(os.Stdout, buf)
+		return err
+	}
+	return ioutil.WriteFile(output, buf.Bytes(), 0644)
+}
+
+// Take the attributes of the REST resources from their responses and the
+// fields of the GraphQL types from the schema, keeping the write-only and
+// contextual attributes of the previous snapshot.
+func build(client *goshopify.Client, version string, previous snapshot) (snapshot, error) {
+	current := snapshot{
+		Version:   version,
+		Resources: make(map[string]*resourceSchema),
+		GraphQL:   make(map[string][]string),
+	}
+
+	ids := map[string]string{}
+	for _, parent := range []string{"order", "product"} {
+		records, err := list(client, version, restResources[parent].path, restResources[parent].key)
+		if err != nil {
+			return current, err
+		}
+		if len(records) == 0 {
+			return current, fmt.Errorf("the shop has no %v to list the resources of", parent)
+		}
+		ids["{"+parent+"_id}"] = string(records[0]["id"])
+		if parent == "product" {
+			variants := []struct {
+				InventoryItemID json.Number `json:"inventory_item_id"`
+			}{}
+			json.Unmarshal(records[0]["variants"], &variants)
+			if len(variants) == 0 {
+				return current, fmt.Errorf("the first product of the shop has no variants")
+			}
+			ids["{inventory_item_id}"] = variants[0].InventoryItemID.String()
+		}
+	}
+
+	for name, source := range restResources {
+		path := source.path
+		for placeholder, id := range ids {
+			path = strings.Replace(path, placeholder, id, 1)
+		}
+		records, err := list(client, version, path, source.key)
+		if err != nil {
+			return current, err
+		}
+		if len(records) == 0 {
+			return current, fmt.Errorf("the shop has no %v to take the attributes from", name)
+		}
+
+		resource := &resourceSchema{Fields: attributes(records)}
+		if old, ok := previous.Resources[name]; ok {
+			resource.WriteOnly = old.WriteOnly
+			resource.Contextual = old.Contextual
+		}
+		current.Resources[name] = resource
+	}
+
+	for _, name := range graphQLTypes {
+		fields, err := typeFields(client, version, name)
+		if err != nil {
+			return current, err
+		}
+		current.GraphQL[name] = fields
+	}
+	return current, nil
+}
+
+// List the records at the path, which are either a list or a single record.
+func list(client *goshopify.Client, version, path, key string) ([]map[string]json.RawMessage, error) {
+	body := map[string]json.RawMessage{}
+	err := client.Get(fmt.Sprintf("admin/api/%s/%s", version, path), &body, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := body[key]
+	records := []map[string]json.RawMessage{}
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{")) {
+		record := map[string]json.RawMessage{}
+		err = json.Unmarshal(raw, &record)
+		return append(records, record), err
+	}
+	err = json.Unmarshal(raw, &records)
+	return records, err
+}
+
+// Return the sorted attributes of any of the records.
+func attributes(records []map[string]json.RawMessage) []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, record := range records {
+		for name := range record {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Return the sorted names of the fields of a GraphQL type.
+func typeFields(client *goshopify.Client, version, name string) ([]string, error) {
+	request := map[string]interface{}{
+		"query":     typeFieldsQuery,
+		"variables": map[string]string{"name": name},
+	}
+	response := struct {
+		Data struct {
+			Type *struct {
+				Fields []struct {
+					Name string `json:"name"`
+				} `json:"fields"`
+			} `json:"__type"`
+		} `json:"data"`
+	}{}
+	err := client.Post(fmt.Sprintf("admin/api/%s/graphql.json", version), request, &response)
+	if err != nil {
+		return nil, err
+	}
+	if response.Data.Type == nil {
+		return nil, fmt.Errorf("API version %v has no GraphQL type %v", version, name)
+	}
+
+	names := []string{}
+	for _, field := range response.Data.Type.Fields {
+		names = append(names, field.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Write the snapshot with each list of attributes on a line of its own.
+func writeSnapshot(w io.Writer, s snapshot) error {
+	line := func(v interface{}) string {
+		data, _ := json.Marshal(v)
+		return strings.Replace(string(data), `","`, `", "`, -1)
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "{\n  \"version\": %s,\n  \"resources\": {\n", line(s.Version))
+	for i, name := range sortedKeys(s.Resources) {
+		resource := s.Resources[name]
+		lists := []string{fmt.Sprintf(`      "fields": %s`, line(resource.Fields))}
+		if len(resource.WriteOnly) > 0 {
+			lists = append(lists, fmt.Sprintf(`      "write_only": %s`, line(resource.WriteOnly)))
+		}
+		if len(resource.Contextual) > 0 {
+			lists = append(lists, fmt.Sprintf(`      "contextual": %s`, line(resource.Contextual)))
+		}
+		fmt.Fprintf(buf, "    %s: {\n%s\n    }%s\n", line(name), strings.Join(lists, ",\n"), separator(i, len(s.Resources)))
+	}
+	fmt.Fprintf(buf, "  },\n  \"graphql\": {\n")
+	for i, name := range sortedKeys(s.GraphQL) {
+		fmt.Fprintf(buf, "    %s: %s%s\n", line(name), line(s.GraphQL[name]), separator(i, len(s.GraphQL)))
+	}
+	fmt.Fprintf(buf, "  }\n}\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func separator(i, n int) string {
+	if i < n-1 {
+		return ","
+	}
+	return ""
+}
+
+func sortedKeys(m interface{}) []string {
+	keys := []string{}
+	switch m := m.(type) {
+	case map[string]*resourceSchema:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	case map[string][]string:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+
+	goshopify "github.com/getconversio/go-shopify"
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestBuild(t *testing.T) {
+	client := goshopify.NewClient(goshopify.App{}, "fooshop", "abcd")
+	httpmock.ActivateNonDefault(client.Client)
+	defer httpmock.DeactivateAndReset()
+
+	for name, source := range restResources {
+		body := fmt.Sprintf(`{%q: [{"id": 1, "%s_only": true}, {"id": 2, "title": "Two"}]}`, source.key, name)
+		if name == "shop" {
+			body = `{"shop": {"id": 1, "name": "Foo"}}`
+		}
+		path := source.path
+		switch name {
+		case "variant", "image":
+			path = "products/1/" + source.key + ".json"
+		case "order_risk", "transaction", "fulfillment":
+			path = "orders/1/" + source.key + ".json"
+		case "product":
+			body = `{"products": [{"id": 1, "product_only": true, "variants": [{"inventory_item_id": 3}]}, {"id": 2, "title": "Two"}]}`
+		case "inventory_level":
+			path = "inventory_levels.json?inventory_item_ids=3"
+		}
+		httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/api/2019-04/"+path,
+			httpmock.NewStringResponder(200, body))
+	}
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/2019-04/graphql.json",
+		func(req *http.Request) (*http.Response, error) {
+			data, _ := ioutil.ReadAll(req.Body)
+			request := struct {
+				Variables struct {
+					Name string `json:"name"`
+				} `json:"variables"`
+			}{}
+			json.Unmarshal(data, &request)
+			return httpmock.NewStringResponse(200, fmt.Sprintf(`{"data": {"__type": {"fields": [{"name": "title"}, {"name": "id%s"}]}}}`, request.Variables.Name)), nil
+		})
+
+	previous := snapshot{Resources: map[string]*resourceSchema{
+		"product": {Fields: []string{"id"}, WriteOnly: []string{"published"}},
+	}}
+	current, err := build(client, "2019-04", previous)
+	if err != nil {
+		t.Fatalf("build returned error: %v", err)
+	}
+
+	expected := &resourceSchema{Fields: []string{"id", "product_only", "title", "variants"}, WriteOnly: []string{"published"}}
+	if !reflect.DeepEqual(current.Resources["product"], expected) {
+		t.Errorf("build returned product %+v, expected %+v", current.Resources["product"], expected)
+	}
+	if shop := current.Resources["shop"].Fields; !reflect.DeepEqual(shop, []string{"id", "name"}) {
+		t.Errorf("build returned shop attributes %v, expected [id name]", shop)
+	}
+	if fields := current.GraphQL["Order"]; !reflect.DeepEqual(fields, []string{"idOrder", "title"}) {
+		t.Errorf("build returned Order fields %v, expected [idOrder title]", fields)
+	}
+
+	// The written snapshot reads back as the same
+	buf := new(bytes.Buffer)
+	err = writeSnapshot(buf, current)
+	if err != nil {
+		t.Fatalf("writeSnapshot returned error: %v", err)
+	}
+	written := snapshot{}
+	err = json.Unmarshal(buf.Bytes(), &written)
+	if err != nil || !reflect.DeepEqual(written, current) {
+		t.Errorf("writeSnapshot wrote %s, which does not read back as the snapshot", buf)
+	}
+}
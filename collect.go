@@ -0,0 +1,53 @@
+package goshopify
+
+import (
+	"fmt"
+	"time"
+)
+
+const collectsBasePath = "admin/collects"
+
+// CollectService is an interface for interfacing with the collect endpoints
+// of the Shopify API.
+// See: https://help.shopify.com/api/reference/products/collect
+type CollectService interface {
+	List(interface{}) ([]Collect, error)
+	Count(interface{}) (int, error)
+}
+
+// CollectServiceOp handles communication with the collect related methods of
+// the Shopify API.
+type CollectServiceOp struct {
+	client *Client
+}
+
+// Collect represents a Shopify collect, which puts a product in a custom
+// collection.
+type Collect struct {
+	ID           int        `json:"id,omitempty"`
+	CollectionID int        `json:"collection_id,omitempty"`
+	ProductID    int        `json:"product_id,omitempty"`
+	Position     int        `json:"position,omitempty"`
+	SortValue    string     `json:"sort_value,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+}
+
+// CollectsResource represents the result from the collects.json endpoint
+type CollectsResource struct {
+	Collects []Collect `json:"collects"`
+}
+
+// List collects
+func (s *CollectServiceOp) List(options interface{}) ([]Collect, error) {
+	path := fmt.Sprintf("%s.json", collectsBasePath)
+	resource := new(CollectsResource)
+	err := s.client.Get(path, resource, options)
+	return resource.Collects, err
+}
+
+// Count collects
+func (s *CollectServiceOp) Count(options interface{}) (int, error) {
+	path := fmt.Sprintf("%s/count.json", collectsBasePath)
+	return s.client.Count(path, options)
+}
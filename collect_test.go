@@ -0,0 +1,44 @@
+package goshopify
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestCollectList(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/collects.json",
+		httpmock.NewStringResponder(200, `{"collects": [{"id": 1, "collection_id": 11, "product_id": 21},{"id": 2}]}`))
+
+	collects, err := client.Collect.List(nil)
+	if err != nil {
+		t.Errorf("Collect.List returned error: %v", err)
+	}
+
+	expected := []Collect{{ID: 1, CollectionID: 11, ProductID: 21}, {ID: 2}}
+	if !reflect.DeepEqual(collects, expected) {
+		t.Errorf("Collect.List returned %+v, expected %+v", collects, expected)
+	}
+}
+
+func TestCollectCount(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/collects/count.json",
+		httpmock.NewStringResponder(200, `{"count": 5}`))
+
+	cnt, err := client.Collect.Count(nil)
+	if err != nil {
+		t.Errorf("Collect.Count returned error: %v", err)
+	}
+
+	expected := 5
+	if cnt != expected {
+		t.Errorf("Collect.Count returned %d, expected %d", cnt, expected)
+	}
+}
@@ -0,0 +1,164 @@
+package goshopify
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// The models checked against the API schema, keyed by the name of the
+// resource in the schema.
+var contractModels = map[string]interface{}{
+	"product":           Product{},
+	"variant":           Variant{},
+	"image":             Image{},
+	"customer":          Customer{},
+	"custom_collection": CustomCollection{},
+	"webhook":           Webhook{},
+	"metafield":         Metafield{},
+	"script_tag":        ScriptTag{},
+	"order_risk":        OrderRisk{},
+	"transaction":       Transaction{},
+	"carrier_service":   CarrierService{},
+	"order":             Order{},
+	"shop":              Shop{},
+	"smart_collection":  SmartCollection{},
+	"fulfillment":       Fulfillment{},
+	"collect":           Collect{},
+	"inventory_level":   InventoryLevel{},
+}
+
+// The structs decoded from GraphQL responses, keyed by the name of their type
+// in the API schema.
+var contractGraphQLModels = map[string][]reflect.Type{
+	"Product":        {reflect.TypeOf(productNode{})},
+	"ProductVariant": {edgeNode(productNode{}.Variants.Edges), reflect.TypeOf(availabilityNode{})},
+	"InventoryItem":  {reflect.TypeOf(availabilityNode{}.InventoryItem)},
+	"InventoryLevel": {edgeNode(inventoryLevelsConnection{}.Edges)},
+	"Location":       {fieldType(edgeNode(inventoryLevelsConnection{}.Edges), "Location")},
+	"Order":          {reflect.TypeOf(orderNode{})},
+	"Customer":       {reflect.TypeOf(orderNode{}.Customer).Elem()},
+	"MoneyBag":       {reflect.TypeOf(moneyBagJSON{})},
+	"MoneyV2":        {reflect.TypeOf(moneyBagJSON{}.ShopMoney)},
+	"Metafield":      {edgeNode(metafieldConnection{}.Edges)},
+}
+
+// Return the type of the nodes of a connection's edges.
+func edgeNode(edges interface{}) reflect.Type {
+	return fieldType(reflect.TypeOf(edges).Elem(), "Node")
+}
+
+func fieldType(t reflect.Type, name string) reflect.Type {
+	field, _ := t.FieldByName(name)
+	return field.Type
+}
+
+// A snapshot of the attributes of the REST resources and the fields of the
+// GraphQL types of one API version. Write-only attributes are accepted when
+// creating or updating a resource but not returned, and contextual ones are
+// only returned by some endpoints. Snapshots are written by
+// cmd/shopifyschema, e.g. to refresh the one of 2019-04:
+//
+//	go run ./cmd/shopifyschema -o fixtures/schema/2019-04.json 2019-04
+type apiSchema struct {
+	Version   string `json:"version"`
+	Resources map[string]struct {
+		Fields     []string `json:"fields"`
+		WriteOnly  []string `json:"write_only"`
+		Contextual []string `json:"contextual"`
+	} `json:"resources"`
+	GraphQL map[string][]string `json:"graphql"`
+}
+
+// Load the schema snapshot of the API version given in SHOPIFY_API_VERSION,
+// or of the latest version there is a snapshot of.
+func loadAPISchema(t *testing.T) apiSchema {
+	version := os.Getenv("SHOPIFY_API_VERSION")
+	if version == "" {
+		snapshots, _ := filepath.Glob("fixtures/schema/*.json")
+		if len(snapshots) == 0 {
+			t.Fatalf("no API schema snapshots in fixtures/schema")
+		}
+		sort.Strings(snapshots)
+		version = filepath.Base(snapshots[len(snapshots)-1])
+		version = version[:len(version)-len(".json")]
+	}
+
+	schema := apiSchema{}
+	err := json.Unmarshal(loadFixture(filepath.Join("schema", version+".json")), &schema)
+	if err != nil {
+		t.Fatalf("cannot decode API schema %v: %v", version, err)
+	}
+	return schema
+}
+
+// Check that the json tags of the models are attributes of the resources in
+// the API schema, so that a renamed or removed attribute is noticed. Set
+// SHOPIFY_CONTRACT_STRICT to also fail on attributes the models do not have.
+func TestContract(t *testing.T) {
+	schema := loadAPISchema(t)
+	strict := os.Getenv("SHOPIFY_CONTRACT_STRICT") != ""
+
+	for name, model := range contractModels {
+		resource, ok := schema.Resources[name]
+		if !ok {
+			t.Errorf("API schema %v has no resource %v", schema.Version, name)
+			continue
+		}
+
+		known := make(map[string]bool)
+		for _, list := range [][]string{resource.Fields, resource.WriteOnly, resource.Contextual} {
+			for _, field := range list {
+				known[field] = true
+			}
+		}
+
+		tags := make(map[string]bool)
+		for _, tag := range fieldNames(reflect.TypeOf(model)) {
+			tags[tag] = true
+			if !known[tag] {
+				t.Errorf("%T has attribute %v, which %v does not have in API version %v", model, tag, name, schema.Version)
+			}
+		}
+
+		for _, field := range resource.Fields {
+			if tags[field] {
+				continue
+			}
+			if strict {
+				t.Errorf("%T is missing attribute %v of API version %v", model, field, schema.Version)
+			} else {
+				t.Logf("%T is missing attribute %v of API version %v", model, field, schema.Version)
+			}
+		}
+	}
+}
+
+// Check that the json tags of the structs decoded from GraphQL responses are
+// fields of their types in the API schema.
+func TestContractGraphQL(t *testing.T) {
+	schema := loadAPISchema(t)
+
+	for name, models := range contractGraphQLModels {
+		fields, ok := schema.GraphQL[name]
+		if !ok {
+			t.Errorf("API schema %v has no GraphQL type %v", schema.Version, name)
+			continue
+		}
+
+		known := make(map[string]bool)
+		for _, field := range fields {
+			known[field] = true
+		}
+		for _, model := range models {
+			for _, tag := range fieldNames(model) {
+				if !known[tag] {
+					t.Errorf("%v has field %v, which %v does not have in API version %v", model, tag, name, schema.Version)
+				}
+			}
+		}
+	}
+}
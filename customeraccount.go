@@ -0,0 +1,370 @@
+package goshopify
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CustomerAccountAPIVersion is the version of the Customer Account API used
+// by CustomerAccountClient.
+const CustomerAccountAPIVersion = "2024-07"
+
+// DefaultCustomerAccountScope is the scope customers are asked to grant when
+// CustomerAccountApp.Scope is empty.
+const DefaultCustomerAccountScope = "openid email customer-account-api:full"
+
+// The number of orders and addresses returned per query
+const customerAccountPageSize = 50
+
+const customerProfileQuery = `query customerProfile {
+	customer { id firstName lastName displayName creationDate emailAddress { emailAddress } phoneNumber { phoneNumber } }
+}`
+
+const customerAddressesQuery = `query customerAddresses($after: String) {
+	customer {
+		defaultAddress { id }
+		addresses(first: 50, after: $after) {
+			pageInfo { hasNextPage endCursor }
+			nodes { id firstName lastName company address1 address2 city province zip country territoryCode phoneNumber }
+		}
+	}
+}`
+
+const customerOrdersQuery = `query customerOrders($first: Int!, $after: String) {
+	customer {
+		orders(first: $first, after: $after, sortKey: PROCESSED_AT, reverse: true) {
+			pageInfo { hasNextPage endCursor }
+			nodes { id name number processedAt financialStatus fulfillmentStatus totalPrice { amount currencyCode } }
+		}
+	}
+}`
+
+// CustomerAccountApp holds the settings of an app's client for the Customer
+// Account API, found in the Headless or Customer Account API settings of the
+// shop. ClientSecret is left empty for public clients, which authenticate
+// with PKCE alone. Token requests are made with HTTPClient, or
+// http.DefaultClient if it is nil.
+type CustomerAccountApp struct {
+	ShopID       uint64
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scope        string
+	HTTPClient   *http.Client
+}
+
+// CustomerToken is a customer's access token for the Customer Account API.
+type CustomerToken struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	IDToken      string    `json:"id_token"`
+	ExpiresIn    int       `json:"expires_in"`
+	ExpiresAt    time.Time `json:"-"`
+}
+
+// NewCodeVerifier returns a random PKCE code verifier, to be kept by the app
+// between AuthorizeURL and GetToken.
+func NewCodeVerifier() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (app CustomerAccountApp) authenticationURL(endpoint string) string {
+	return fmt.Sprintf("https://shopify.com/authentication/%d/oauth/%s", app.ShopID, endpoint)
+}
+
+// AuthorizeURL returns the URL to send a customer to for logging in. State
+// and nonce are unique values to check the callback and the ID token with,
+// and codeVerifier is from NewCodeVerifier.
+func (app CustomerAccountApp) AuthorizeURL(state, nonce, codeVerifier string) string {
+	challenge := sha256.Sum256([]byte(codeVerifier))
+
+	scope := app.Scope
+	if scope == "" {
+		scope = DefaultCustomerAccountScope
+	}
+
+	query := url.Values{}
+	query.Set("client_id", app.ClientID)
+	query.Set("response_type", "code")
+	query.Set("redirect_uri", app.RedirectURL)
+	query.Set("scope", scope)
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	return app.authenticationURL("authorize") + "?" + query.Encode()
+}
+
+// GetToken exchanges the code Shopify passes to the redirect URL for a
+// customer's access token.
+func (app CustomerAccountApp) GetToken(code, codeVerifier string) (*CustomerToken, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("client_id", app.ClientID)
+	form.Set("redirect_uri", app.RedirectURL)
+	form.Set("code", code)
+	form.Set("code_verifier", codeVerifier)
+	return app.requestToken(form)
+}
+
+// RefreshToken returns a new access token for a customer's refresh token.
+func (app CustomerAccountApp) RefreshToken(refreshToken string) (*CustomerToken, error) {
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("client_id", app.ClientID)
+	form.Set("refresh_token", refreshToken)
+	return app.requestToken(form)
+}
+
+func (app CustomerAccountApp) requestToken(form url.Values) (*CustomerToken, error) {
+	req, err := http.NewRequest("POST", app.authenticationURL("token"), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("User-Agent", UserAgent)
+	if app.ClientSecret != "" {
+		req.SetBasicAuth(app.ClientID, app.ClientSecret)
+	}
+
+	httpClient := app.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	err = CheckResponseError(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	token := &CustomerToken{}
+	err = json.NewDecoder(resp.Body).Decode(token)
+	if err != nil {
+		return nil, err
+	}
+	token.ExpiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return token, nil
+}
+
+// CustomerAccountClient manages communication with the Customer Account API
+// on behalf of a logged in customer.
+// See: https://shopify.dev/docs/api/customer
+type CustomerAccountClient struct {
+	// HTTP client used to communicate with the Customer Account API.
+	Client *http.Client
+
+	shopID uint64
+
+	// The customer's access token
+	token string
+}
+
+// CustomerProfile is the logged in customer.
+type CustomerProfile struct {
+	ID           uint64
+	FirstName    string
+	LastName     string
+	DisplayName  string
+	Email        string
+	Phone        string
+	CreationDate *time.Time
+}
+
+// CustomerAccountAddress is an address of the logged in customer.
+type CustomerAccountAddress struct {
+	ID            uint64 `json:"-"`
+	FirstName     string `json:"firstName"`
+	LastName      string `json:"lastName"`
+	Company       string `json:"company"`
+	Address1      string `json:"address1"`
+	Address2      string `json:"address2"`
+	City          string `json:"city"`
+	Province      string `json:"province"`
+	Zip           string `json:"zip"`
+	Country       string `json:"country"`
+	TerritoryCode string `json:"territoryCode"`
+	PhoneNumber   string `json:"phoneNumber"`
+	Default       bool   `json:"-"`
+}
+
+// CustomerAccountOrder is an order of the logged in customer.
+type CustomerAccountOrder struct {
+	ID                uint64
+	Name              string
+	Number            int
+	ProcessedAt       *time.Time
+	FinancialStatus   string
+	FulfillmentStatus string
+	TotalPrice        decimal.Decimal
+	Currency          string
+}
+
+// NewCustomerAccountClient returns a client for the Customer Account API of
+// the shop with the given id, using a customer's access token.
+func NewCustomerAccountClient(shopID uint64, accessToken string) *CustomerAccountClient {
+	return &CustomerAccountClient{Client: http.DefaultClient, shopID: shopID, token: accessToken}
+}
+
+// Query runs a GraphQL query or mutation against the Customer Account API
+// with the given variables and saves the data of the response in the given
+// resource. Errors reported by the GraphQL endpoint are returned as a
+// ResponseError.
+func (c *CustomerAccountClient) Query(q string, variables, resource interface{}) error {
+	u := fmt.Sprintf("https://shopify.com/%d/account/customer/api/%s/graphql", c.shopID, CustomerAccountAPIVersion)
+	header := http.Header{}
+	header.Set("Authorization", c.token)
+	return postGraphQL(c.Client, u, header, q, variables, resource)
+}
+
+// Profile returns the logged in customer.
+func (c *CustomerAccountClient) Profile() (*CustomerProfile, error) {
+	data := struct {
+		Customer struct {
+			ID           string     `json:"id"`
+			FirstName    string     `json:"firstName"`
+			LastName     string     `json:"lastName"`
+			DisplayName  string     `json:"displayName"`
+			CreationDate *time.Time `json:"creationDate"`
+			EmailAddress *struct {
+				EmailAddress string `json:"emailAddress"`
+			} `json:"emailAddress"`
+			PhoneNumber *struct {
+				PhoneNumber string `json:"phoneNumber"`
+			} `json:"phoneNumber"`
+		} `json:"customer"`
+	}{}
+	err := c.Query(customerProfileQuery, nil, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	customer := data.Customer
+	profile := &CustomerProfile{
+		ID:           ParseGID(customer.ID),
+		FirstName:    customer.FirstName,
+		LastName:     customer.LastName,
+		DisplayName:  customer.DisplayName,
+		CreationDate: customer.CreationDate,
+	}
+	if customer.EmailAddress != nil {
+		profile.Email = customer.EmailAddress.EmailAddress
+	}
+	if customer.PhoneNumber != nil {
+		profile.Phone = customer.PhoneNumber.PhoneNumber
+	}
+	return profile, nil
+}
+
+// Addresses returns all addresses of the logged in customer.
+func (c *CustomerAccountClient) Addresses() ([]CustomerAccountAddress, error) {
+	addresses := []CustomerAccountAddress{}
+	variables := map[string]interface{}{}
+	for {
+		data := struct {
+			Customer struct {
+				DefaultAddress *struct {
+					ID string `json:"id"`
+				} `json:"defaultAddress"`
+				Addresses struct {
+					PageInfo PageInfo `json:"pageInfo"`
+					Nodes    []struct {
+						CustomerAccountAddress
+						ID string `json:"id"`
+					} `json:"nodes"`
+				} `json:"addresses"`
+			} `json:"customer"`
+		}{}
+		err := c.Query(customerAddressesQuery, variables, &data)
+		if err != nil {
+			return addresses, err
+		}
+
+		for _, node := range data.Customer.Addresses.Nodes {
+			address := node.CustomerAccountAddress
+			address.ID = ParseGID(node.ID)
+			address.Default = data.Customer.DefaultAddress != nil && data.Customer.DefaultAddress.ID == node.ID
+			addresses = append(addresses, address)
+		}
+
+		pageInfo := data.Customer.Addresses.PageInfo
+		if !pageInfo.HasNextPage {
+			return addresses, nil
+		}
+		if pageInfo.EndCursor == "" {
+			return addresses, errors.New("the next page of addresses has no cursor")
+		}
+		variables["after"] = pageInfo.EndCursor
+	}
+}
+
+// Orders returns a page of the logged in customer's orders, most recent
+// first, along with the cursor to pass as after for the next page. The cursor
+// is empty on the last page.
+func (c *CustomerAccountClient) Orders(after string) ([]CustomerAccountOrder, string, error) {
+	variables := map[string]interface{}{"first": customerAccountPageSize}
+	if after != "" {
+		variables["after"] = after
+	}
+
+	data := struct {
+		Customer struct {
+			Orders struct {
+				PageInfo PageInfo `json:"pageInfo"`
+				Nodes    []struct {
+					ID                string          `json:"id"`
+					Name              string          `json:"name"`
+					Number            int             `json:"number"`
+					ProcessedAt       *time.Time      `json:"processedAt"`
+					FinancialStatus   string          `json:"financialStatus"`
+					FulfillmentStatus string          `json:"fulfillmentStatus"`
+					TotalPrice        StorefrontMoney `json:"totalPrice"`
+				} `json:"nodes"`
+			} `json:"orders"`
+		} `json:"customer"`
+	}{}
+	err := c.Query(customerOrdersQuery, variables, &data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	orders := make([]CustomerAccountOrder, len(data.Customer.Orders.Nodes))
+	for i, node := range data.Customer.Orders.Nodes {
+		orders[i] = CustomerAccountOrder{
+			ID:                ParseGID(node.ID),
+			Name:              node.Name,
+			Number:            node.Number,
+			ProcessedAt:       node.ProcessedAt,
+			FinancialStatus:   node.FinancialStatus,
+			FulfillmentStatus: node.FulfillmentStatus,
+			TotalPrice:        node.TotalPrice.Amount,
+			Currency:          node.TotalPrice.CurrencyCode,
+		}
+	}
+
+	next := ""
+	if data.Customer.Orders.PageInfo.HasNextPage {
+		next = data.Customer.Orders.PageInfo.EndCursor
+	}
+	return orders, next, nil
+}
@@ -0,0 +1,247 @@
+package goshopify
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+const customerAccountGraphQLURL = "https://shopify.com/1234/account/customer/api/" + CustomerAccountAPIVersion + "/graphql"
+
+var customerAccountApp = CustomerAccountApp{
+	ShopID:       1234,
+	ClientID:     "shp_abc",
+	ClientSecret: "hush",
+	RedirectURL:  "https://example.com/account/callback",
+}
+
+func TestCustomerAccountAppAuthorizeURL(t *testing.T) {
+	authorizeURL, err := url.Parse(customerAccountApp.AuthorizeURL("state1", "nonce1", "verifier"))
+	if err != nil {
+		t.Fatalf("CustomerAccountApp.AuthorizeURL returned an invalid URL: %v", err)
+	}
+
+	challenge := sha256.Sum256([]byte("verifier"))
+	expected := url.Values{
+		"client_id":             {"shp_abc"},
+		"response_type":         {"code"},
+		"redirect_uri":          {"https://example.com/account/callback"},
+		"scope":                 {DefaultCustomerAccountScope},
+		"state":                 {"state1"},
+		"nonce":                 {"nonce1"},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	if authorizeURL.Host != "shopify.com" || authorizeURL.Path != "/authentication/1234/oauth/authorize" {
+		t.Errorf("CustomerAccountApp.AuthorizeURL returned %v", authorizeURL)
+	}
+	if !reflect.DeepEqual(authorizeURL.Query(), expected) {
+		t.Errorf("CustomerAccountApp.AuthorizeURL has query %v, expected %v", authorizeURL.Query(), expected)
+	}
+}
+
+func TestNewCodeVerifier(t *testing.T) {
+	first, err := NewCodeVerifier()
+	if err != nil {
+		t.Fatalf("NewCodeVerifier returned error: %v", err)
+	}
+	second, _ := NewCodeVerifier()
+	if len(first) != 43 || first == second {
+		t.Errorf("NewCodeVerifier returned %v and %v, expected two random 43 character verifiers", first, second)
+	}
+}
+
+func TestCustomerAccountAppGetToken(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var form url.Values
+	httpmock.RegisterResponder("POST", "https://shopify.com/authentication/1234/oauth/token",
+		func(req *http.Request) (*http.Response, error) {
+			if user, password, ok := req.BasicAuth(); !ok || user != "shp_abc" || password != "hush" {
+				return httpmock.NewStringResponse(401, `{"error": "invalid_client"}`), nil
+			}
+			req.ParseForm()
+			form = req.PostForm
+			return httpmock.NewStringResponse(200, `{"access_token": "shcat_abc", "refresh_token": "shcrt_abc",
+				"id_token": "eyJ", "expires_in": 3600}`), nil
+		})
+
+	token, err := customerAccountApp.GetToken("code1", "verifier")
+	if err != nil {
+		t.Fatalf("CustomerAccountApp.GetToken returned error: %v", err)
+	}
+	if token.AccessToken != "shcat_abc" || token.RefreshToken != "shcrt_abc" || time.Until(token.ExpiresAt) < 59*time.Minute {
+		t.Errorf("CustomerAccountApp.GetToken returned %+v", token)
+	}
+	if form.Get("grant_type") != "authorization_code" || form.Get("code") != "code1" || form.Get("code_verifier") != "verifier" {
+		t.Errorf("CustomerAccountApp.GetToken sent %v", form)
+	}
+
+	_, err = customerAccountApp.RefreshToken("shcrt_abc")
+	if err != nil {
+		t.Fatalf("CustomerAccountApp.RefreshToken returned error: %v", err)
+	}
+	if form.Get("grant_type") != "refresh_token" || form.Get("refresh_token") != "shcrt_abc" {
+		t.Errorf("CustomerAccountApp.RefreshToken sent %v", form)
+	}
+
+	// With the app's own HTTP client
+	transport := &countingTransport{RoundTripper: httpmock.DefaultTransport}
+	configured := customerAccountApp
+	configured.HTTPClient = &http.Client{Transport: transport}
+	_, err = configured.GetToken("code1", "verifier")
+	if err != nil || transport.requests != 1 {
+		t.Errorf("CustomerAccountApp.GetToken returned %v after %d requests with its HTTPClient, expected 1", err, transport.requests)
+	}
+
+	wrongSecret := customerAccountApp
+	wrongSecret.ClientSecret = "wrong"
+	_, err = wrongSecret.GetToken("code1", "verifier")
+	if responseError, ok := err.(ResponseError); !ok || responseError.Message != "invalid_client" {
+		t.Errorf("CustomerAccountApp.GetToken returned error %#v, expected invalid_client", err)
+	}
+}
+
+type countingTransport struct {
+	http.RoundTripper
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	return t.RoundTripper.RoundTrip(req)
+}
+
+// Set up a customer account client answering queries with the given data.
+func setupCustomerAccount(responses ...string) (*CustomerAccountClient, *[]map[string]interface{}) {
+	customerAccount := NewCustomerAccountClient(1234, "shcat_abc")
+	httpmock.ActivateNonDefault(customerAccount.Client)
+
+	variables := []map[string]interface{}{}
+	httpmock.RegisterResponder("POST", customerAccountGraphQLURL,
+		func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Authorization") != "shcat_abc" {
+				return httpmock.NewStringResponse(401, `{"errors": "Unauthorized"}`), nil
+			}
+			body := struct {
+				Variables map[string]interface{} `json:"variables"`
+			}{}
+			json.NewDecoder(req.Body).Decode(&body)
+			variables = append(variables, body.Variables)
+
+			response := responses[0]
+			responses = responses[1:]
+			return httpmock.NewStringResponse(200, `{"data": `+response+`}`), nil
+		})
+	return customerAccount, &variables
+}
+
+func TestCustomerAccountClientProfile(t *testing.T) {
+	customerAccount, _ := setupCustomerAccount(`{"customer": {"id": "gid://shopify/Customer/207119551",
+		"firstName": "Bob", "lastName": "Norman", "displayName": "Bob Norman", "creationDate": "2023-01-10T12:00:00Z",
+		"emailAddress": {"emailAddress": "bob.norman@example.com"}, "phoneNumber": null}}`)
+	defer teardown()
+
+	profile, err := customerAccount.Profile()
+	if err != nil {
+		t.Fatalf("CustomerAccountClient.Profile returned error: %v", err)
+	}
+
+	creationDate := time.Date(2023, 1, 10, 12, 0, 0, 0, time.UTC)
+	expected := &CustomerProfile{
+		ID:           207119551,
+		FirstName:    "Bob",
+		LastName:     "Norman",
+		DisplayName:  "Bob Norman",
+		Email:        "bob.norman@example.com",
+		CreationDate: &creationDate,
+	}
+	if !reflect.DeepEqual(profile, expected) {
+		t.Errorf("CustomerAccountClient.Profile returned %+v, expected %+v", profile, expected)
+	}
+}
+
+func TestCustomerAccountClientAddresses(t *testing.T) {
+	customerAccount, variables := setupCustomerAccount(
+		`{"customer": {"defaultAddress": {"id": "gid://shopify/CustomerAddress/2"}, "addresses": {
+			"pageInfo": {"hasNextPage": true, "endCursor": "c1"},
+			"nodes": [{"id": "gid://shopify/CustomerAddress/1", "city": "Ottawa", "territoryCode": "CA"}]}}}`,
+		`{"customer": {"defaultAddress": {"id": "gid://shopify/CustomerAddress/2"}, "addresses": {
+			"pageInfo": {"hasNextPage": false, "endCursor": "c2"},
+			"nodes": [{"id": "gid://shopify/CustomerAddress/2", "city": "Montreal", "territoryCode": "CA"}]}}}`)
+	defer teardown()
+
+	addresses, err := customerAccount.Addresses()
+	if err != nil {
+		t.Fatalf("CustomerAccountClient.Addresses returned error: %v", err)
+	}
+
+	expected := []CustomerAccountAddress{
+		{ID: 1, City: "Ottawa", TerritoryCode: "CA"},
+		{ID: 2, City: "Montreal", TerritoryCode: "CA", Default: true},
+	}
+	if !reflect.DeepEqual(addresses, expected) {
+		t.Errorf("CustomerAccountClient.Addresses returned %+v, expected %+v", addresses, expected)
+	}
+	if (*variables)[1]["after"] != "c1" {
+		t.Errorf("CustomerAccountClient.Addresses asked for the second page with %v", (*variables)[1])
+	}
+}
+
+func TestCustomerAccountClientAddressesWithoutCursor(t *testing.T) {
+	customerAccount, _ := setupCustomerAccount(`{"customer": {"addresses": {
+		"pageInfo": {"hasNextPage": true, "endCursor": null},
+		"nodes": [{"id": "gid://shopify/CustomerAddress/1", "city": "Ottawa"}]}}}`)
+	defer teardown()
+
+	addresses, err := customerAccount.Addresses()
+	if err == nil || len(addresses) != 1 {
+		t.Errorf("CustomerAccountClient.Addresses returned %+v and %v, expected the first page and an error", addresses, err)
+	}
+}
+
+func TestCustomerAccountClientOrders(t *testing.T) {
+	customerAccount, variables := setupCustomerAccount(`{"customer": {"orders": {
+		"pageInfo": {"hasNextPage": true, "endCursor": "c2"},
+		"nodes": [{"id": "gid://shopify/Order/450789469", "name": "#1001", "number": 1001,
+			"processedAt": "2023-01-10T12:00:00Z", "financialStatus": "PAID",
+			"fulfillmentStatus": "PARTIALLY_FULFILLED",
+			"totalPrice": {"amount": "409.94", "currencyCode": "USD"}}]}}}`)
+	defer teardown()
+
+	orders, next, err := customerAccount.Orders("c1")
+	if err != nil {
+		t.Fatalf("CustomerAccountClient.Orders returned error: %v", err)
+	}
+
+	processedAt := time.Date(2023, 1, 10, 12, 0, 0, 0, time.UTC)
+	expected := []CustomerAccountOrder{{
+		ID:                450789469,
+		Name:              "#1001",
+		Number:            1001,
+		ProcessedAt:       &processedAt,
+		FinancialStatus:   "PAID",
+		FulfillmentStatus: "PARTIALLY_FULFILLED",
+		TotalPrice:        decimal.New(40994, -2),
+		Currency:          "USD",
+	}}
+	if len(orders) != 1 || !orders[0].TotalPrice.Equal(expected[0].TotalPrice) {
+		t.Fatalf("CustomerAccountClient.Orders returned %+v, expected %+v", orders, expected)
+	}
+	orders[0].TotalPrice = expected[0].TotalPrice
+	if !reflect.DeepEqual(orders, expected) || next != "c2" {
+		t.Errorf("CustomerAccountClient.Orders returned %+v and cursor %v, expected %+v and c2", orders, next, expected)
+	}
+	if (*variables)[0]["after"] != "c1" {
+		t.Errorf("CustomerAccountClient.Orders sent variables %v", (*variables)[0])
+	}
+}
@@ -0,0 +1,100 @@
+package goshopify
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultDeltaSyncInterval is the time a DeltaSyncer waits between polls
+// unless its Interval is changed.
+const DefaultDeltaSyncInterval = time.Minute
+
+// CheckpointStore persists the position of a DeltaSyncer so that polling can
+// continue where it left off after a restart. LoadCheckpoint should return the
+// zero time for a resource that has never been saved.
+type CheckpointStore interface {
+	LoadCheckpoint(resource string) (time.Time, error)
+	SaveCheckpoint(resource string, checkpoint time.Time) error
+}
+
+// SyncFunc is called by a DeltaSyncer for every changed record. The resource
+// is a pointer to the record's struct, e.g. a *Product when syncing products.
+type SyncFunc func(id uint64, resource interface{}) error
+
+// DeltaSyncer polls a resource for records updated since its checkpoint and
+// hands them to a SyncFunc. The checkpoint is only saved once every record of
+// a poll was handled without error, so each change is delivered at least
+// once. Records can be delivered more than once and should be handled
+// idempotently.
+type DeltaSyncer struct {
+	// Time to wait between polls in Run
+	Interval time.Duration
+
+	client      *Client
+	name        string
+	resource    syncResource
+	checkpoints CheckpointStore
+}
+
+// NewDeltaSyncer returns a DeltaSyncer for the named resource, e.g.
+// "products", "customers" or "orders".
+func NewDeltaSyncer(client *Client, resource string, checkpoints CheckpointStore) (*DeltaSyncer, error) {
+	kind, ok := syncResources[resource]
+	if !ok {
+		return nil, fmt.Errorf("syncing %s is not supported", resource)
+	}
+
+	return &DeltaSyncer{
+		Interval:    DefaultDeltaSyncInterval,
+		client:      client,
+		name:        resource,
+		resource:    kind,
+		checkpoints: checkpoints,
+	}, nil
+}
+
+// Sync passes every record updated since the stored checkpoint to fn and
+// saves the new checkpoint. If fn returns an error Sync stops and returns it
+// without saving, and the records are delivered again by the next Sync.
+func (d *DeltaSyncer) Sync(fn SyncFunc) error {
+	checkpoint, err := d.checkpoints.LoadCheckpoint(d.name)
+	if err != nil {
+		return err
+	}
+
+	started := time.Now()
+	var newest time.Time
+	err = listAll(d.client, d.resource.list, ListOptions{UpdatedAtMin: checkpoint}, func(item syncItem, _ PageInfo) error {
+		err := fn(item.id, item.resource)
+		if err == nil && item.updatedAt != nil && item.updatedAt.After(newest) {
+			newest = *item.updatedAt
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	next := nextCheckpoint(newest, started)
+	if !next.After(checkpoint) {
+		return nil
+	}
+	return d.checkpoints.SaveCheckpoint(d.name, next)
+}
+
+// Run calls Sync every Interval until stop is closed or Sync returns an
+// error, which Run then returns.
+func (d *DeltaSyncer) Run(stop <-chan struct{}, fn SyncFunc) error {
+	for {
+		err := d.Sync(fn)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(d.Interval):
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package goshopify
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+)
+
+type memoryCheckpoints map[string]time.Time
+
+func (m memoryCheckpoints) LoadCheckpoint(resource string) (time.Time, error) {
+	return m[resource], nil
+}
+
+func (m memoryCheckpoints) SaveCheckpoint(resource string, checkpoint time.Time) error {
+	m[resource] = checkpoint
+	return nil
+}
+
+func TestNewDeltaSyncerUnsupported(t *testing.T) {
+	_, err := NewDeltaSyncer(client, "themes", memoryCheckpoints{})
+	if err == nil {
+		t.Error("NewDeltaSyncer(themes) returned nil error, expected an error")
+	}
+}
+
+func TestDeltaSyncerSync(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/customers.json?limit=250&updated_at_min=2017-01-01T00%3A00%3A00Z",
+		httpmock.NewStringResponder(200, `{"customers": [
+			{"id": 1, "updated_at": "2017-01-05T00:00:00Z"},
+			{"id": 2, "updated_at": "2017-01-03T00:00:00Z"}
+		]}`))
+
+	checkpoints := memoryCheckpoints{"customers": time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	syncer, err := NewDeltaSyncer(client, "customers", checkpoints)
+	if err != nil {
+		t.Fatalf("NewDeltaSyncer returned error: %v", err)
+	}
+
+	ids := []uint64{}
+	err = syncer.Sync(func(id uint64, resource interface{}) error {
+		if resource.(*Customer).ID != id {
+			t.Errorf("SyncFunc received customer %d with id %d", resource.(*Customer).ID, id)
+		}
+		ids = append(ids, id)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("DeltaSyncer.Sync returned error: %v", err)
+	}
+
+	expected := []uint64{1, 2}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("DeltaSyncer.Sync delivered %v, expected %v", ids, expected)
+	}
+
+	checkpoint := time.Date(2017, time.January, 5, 0, 0, 0, 0, time.UTC)
+	if !checkpoints["customers"].Equal(checkpoint) {
+		t.Errorf("DeltaSyncer.Sync saved checkpoint %v, expected %v", checkpoints["customers"], checkpoint)
+	}
+}
+
+func TestDeltaSyncerSyncError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/customers.json?limit=250",
+		httpmock.NewStringResponder(200, `{"customers": [{"id": 1, "updated_at": "2017-01-05T00:00:00Z"}]}`))
+
+	checkpoints := memoryCheckpoints{}
+	syncer, _ := NewDeltaSyncer(client, "customers", checkpoints)
+
+	expected := errors.New("store is down")
+	err := syncer.Sync(func(id uint64, resource interface{}) error {
+		return expected
+	})
+	if err != expected {
+		t.Errorf("DeltaSyncer.Sync returned error %v, expected %v", err, expected)
+	}
+
+	if _, ok := checkpoints["customers"]; ok {
+		t.Errorf("DeltaSyncer.Sync saved checkpoint %v after an error", checkpoints["customers"])
+	}
+}
+
+func TestDeltaSyncerRun(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products.json?limit=250",
+		httpmock.NewStringResponder(200, `{"products": [{"id": 1, "updated_at": "2017-01-05T00:00:00Z"}]}`))
+
+	syncer, _ := NewDeltaSyncer(client, "products", memoryCheckpoints{})
+
+	stop := make(chan struct{})
+	close(stop)
+
+	calls := 0
+	err := syncer.Run(stop, func(id uint64, resource interface{}) error {
+		calls++
+		return nil
+	})
+	if err != nil {
+		t.Errorf("DeltaSyncer.Run returned error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("DeltaSyncer.Run delivered %d records, expected 1", calls)
+	}
+}
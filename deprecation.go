@@ -0,0 +1,137 @@
+package goshopify
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const deprecatedReasonHeader = "X-Shopify-API-Deprecated-Reason"
+
+// Deprecation is a request that Shopify reported as using a deprecated
+// endpoint, parameter or, for GraphQL, field. Reason is usually a link to the
+// changelog entry explaining what to use instead. The ids in Path are
+// replaced with ":id", e.g. "/admin/products/:id.json", so that an endpoint
+// is reported once however many resources it was used for.
+//
+// Shopify reports deprecated GraphQL fields with the same header as REST
+// endpoints, so they are reported for the path of the GraphQL endpoint with
+// the reason naming the field.
+type Deprecation struct {
+	Method string
+	Path   string
+	Reason string
+}
+
+// ResponseMeta is what the responses to a set of calls said besides their
+// results. Unlike Client.Deprecations, Deprecations has an entry for every
+// response that was marked as deprecated, in the order they were received.
+type ResponseMeta struct {
+	Deprecations []Deprecation
+}
+
+// The metadata of the responses to a client's calls
+type responseMeta struct {
+	mu   sync.Mutex
+	meta ResponseMeta
+}
+
+// WithResponseMeta calls fn with a copy of the client, and returns the
+// metadata of the responses to the calls made with the copy until fn returns:
+//
+//	meta, err := client.WithResponseMeta(func(c *goshopify.Client) error {
+//		products, err = c.Product.List(nil)
+//		return err
+//	})
+func (c *Client) WithResponseMeta(fn func(*Client) error) (ResponseMeta, error) {
+	scoped := *c
+	scoped.responseMeta = &responseMeta{}
+	scoped.setServices()
+	err := fn(&scoped)
+
+	scoped.responseMeta.mu.Lock()
+	defer scoped.responseMeta.mu.Unlock()
+	return scoped.responseMeta.meta, err
+}
+
+// Add a deprecation to the metadata, if it is being collected.
+func (m *responseMeta) deprecated(deprecation Deprecation) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.meta.Deprecations = append(m.meta.Deprecations, deprecation)
+}
+
+// The deprecations a client ran into, and who to tell about new ones
+type deprecations struct {
+	mu      sync.Mutex
+	seen    map[Deprecation]bool
+	list    []Deprecation
+	handler func(Deprecation)
+}
+
+// WithDeprecationHandler calls fn the first time a request is reported as
+// deprecated, for every method, path and reason.
+func WithDeprecationHandler(fn func(Deprecation)) Option {
+	return func(c *Client) {
+		c.deprecations.handler = fn
+	}
+}
+
+// WithDeprecationLogger logs deprecated requests to logger, once each.
+func WithDeprecationLogger(logger *log.Logger) Option {
+	return WithDeprecationHandler(func(d Deprecation) {
+		logger.Printf("shopify: %s %s is deprecated: %s", d.Method, d.Path, d.Reason)
+	})
+}
+
+// Deprecations returns the deprecated requests made with the client so far,
+// once for every method, path and reason, in the order they were first made.
+func (c *Client) Deprecations() []Deprecation {
+	c.deprecations.mu.Lock()
+	defer c.deprecations.mu.Unlock()
+	return append([]Deprecation{}, c.deprecations.list...)
+}
+
+// Record a request if the response says it is deprecated, and return the
+// deprecation if it did.
+func (d *deprecations) check(req *http.Request, resp *http.Response) *Deprecation {
+	reason := resp.Header.Get(deprecatedReasonHeader)
+	if reason == "" || d == nil {
+		return nil
+	}
+
+	deprecation := Deprecation{Method: req.Method, Path: endpointPath(req.URL.Path), Reason: reason}
+	d.mu.Lock()
+	if d.seen[deprecation] {
+		d.mu.Unlock()
+		return &deprecation
+	}
+	if d.seen == nil {
+		d.seen = make(map[Deprecation]bool)
+	}
+	d.seen[deprecation] = true
+	d.list = append(d.list, deprecation)
+	handler := d.handler
+	d.mu.Unlock()
+
+	if handler != nil {
+		handler(deprecation)
+	}
+	return &deprecation
+}
+
+// Replace the ids in a path with ":id".
+func endpointPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		name := strings.TrimSuffix(segment, ".json")
+		if name != "" && strings.Trim(name, "0123456789") == "" {
+			segments[i] = ":id" + segment[len(name):]
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,120 @@
+package goshopify
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestDeprecations(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var handled []Deprecation
+	client = NewClient(app, "fooshop", "abcd", WithDeprecationHandler(func(d Deprecation) {
+		handled = append(handled, d)
+	}))
+	httpmock.ActivateNonDefault(client.Client)
+
+	reason := "https://help.shopify.com/en/api/getting-started/api-deprecations"
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products.json",
+		func(req *http.Request) (*http.Response, error) {
+			resp := httpmock.NewStringResponse(200, `{"products": []}`)
+			resp.Header.Set("X-Shopify-API-Deprecated-Reason", reason)
+			return resp, nil
+		})
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products/count.json",
+		httpmock.NewStringResponder(200, `{"count": 0}`))
+	for _, id := range []string{"1", "2"} {
+		httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products/"+id+"/metafields.json",
+			func(req *http.Request) (*http.Response, error) {
+				resp := httpmock.NewStringResponse(200, `{"metafields": []}`)
+				resp.Header.Set("X-Shopify-API-Deprecated-Reason", reason)
+				return resp, nil
+			})
+	}
+
+	client.Product.List(nil)
+	client.Product.List(nil)
+	client.Product.Count(nil)
+	client.Product.ListMetafields(1, nil)
+	client.Product.ListMetafields(2, nil)
+
+	expected := []Deprecation{
+		{Method: "GET", Path: "/admin/products.json", Reason: reason},
+		{Method: "GET", Path: "/admin/products/:id/metafields.json", Reason: reason},
+	}
+	if !reflect.DeepEqual(handled, expected) {
+		t.Errorf("Deprecation handler was called with %v, expected %v", handled, expected)
+	}
+	if !reflect.DeepEqual(client.Deprecations(), expected) {
+		t.Errorf("Client.Deprecations() returned %v, expected %v", client.Deprecations(), expected)
+	}
+}
+
+func TestWithResponseMeta(t *testing.T) {
+	setup()
+	defer teardown()
+
+	reason := "https://help.shopify.com/en/api/getting-started/api-deprecations"
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products.json",
+		func(req *http.Request) (*http.Response, error) {
+			resp := httpmock.NewStringResponse(200, `{"products": [{"id": 1}]}`)
+			resp.Header.Set("X-Shopify-API-Deprecated-Reason", reason)
+			return resp, nil
+		})
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products/count.json",
+		httpmock.NewStringResponder(200, `{"count": 1}`))
+
+	// Seen before, so the client reports it again for the call
+	client.Product.List(nil)
+
+	var products []Product
+	meta, err := client.WithResponseMeta(func(c *Client) error {
+		var err error
+		products, err = c.Product.List(nil)
+		return err
+	})
+	if err != nil || len(products) != 1 {
+		t.Fatalf("Client.WithResponseMeta returned %v and %d products, expected 1 product", err, len(products))
+	}
+	expected := []Deprecation{{Method: "GET", Path: "/admin/products.json", Reason: reason}}
+	if !reflect.DeepEqual(meta.Deprecations, expected) {
+		t.Errorf("Client.WithResponseMeta returned deprecations %v, expected %v", meta.Deprecations, expected)
+	}
+
+	meta, err = client.WithResponseMeta(func(c *Client) error {
+		_, err := c.Product.Count(nil)
+		return err
+	})
+	if err != nil || len(meta.Deprecations) != 0 {
+		t.Errorf("Client.WithResponseMeta returned %v and deprecations %v, expected none", err, meta.Deprecations)
+	}
+}
+
+func TestDeprecationLogger(t *testing.T) {
+	setup()
+	defer teardown()
+
+	buf := new(bytes.Buffer)
+	client = NewClient(app, "fooshop", "abcd", WithDeprecationLogger(log.New(buf, "", 0)))
+	httpmock.ActivateNonDefault(client.Client)
+
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/graphql.json",
+		func(req *http.Request) (*http.Response, error) {
+			resp := httpmock.NewStringResponse(200, `{"data": {}}`)
+			resp.Header.Set("X-Shopify-API-Deprecated-Reason", "https://shopify.dev/changelog")
+			return resp, nil
+		})
+
+	client.GraphQL.Query("{ shop { name } }", nil, nil)
+
+	expected := "shopify: POST /admin/api/graphql.json is deprecated: https://shopify.dev/changelog\n"
+	if buf.String() != expected {
+		t.Errorf("Deprecation logger logged %q, expected %q", buf.String(), expected)
+	}
+}
@@ -0,0 +1,112 @@
+package errors
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BudgetExhaustedError is returned by Retry when a retryable error could not
+// be retried because the budget ran out. IsRetryable reports false for it,
+// while the other functions of the package look at the error it wraps.
+type BudgetExhaustedError struct {
+	Err error
+}
+
+func (e BudgetExhaustedError) Error() string {
+	return fmt.Sprintf("retry budget exhausted: %v", e.Err)
+}
+
+// Unwrap returns the error that was not retried.
+func (e BudgetExhaustedError) Unwrap() error {
+	return e.Err
+}
+
+// Budget limits retries to a share of the requests made. Every request adds
+// Ratio to the budget, up to Max, and every retry takes one away, so that
+// while most requests fail only a few of them are retried, instead of each
+// being retried several times. It is a goshopify.RetryBudget, so the retries
+// the client makes itself can draw from the same budget.
+type Budget struct {
+	mu     sync.Mutex
+	ratio  float64
+	max    float64
+	tokens float64
+}
+
+// DefaultBudget is the budget shared by all calls to Retry that do not pass
+// their own. It allows retrying one in ten requests, with up to 100 retries
+// in a row.
+var DefaultBudget = NewBudget(0.1, 100)
+
+// NewBudget returns a full budget that allows retrying ratio of the requests,
+// and at most max retries without requests in between.
+func NewBudget(ratio float64, max int) *Budget {
+	return &Budget{ratio: ratio, max: float64(max), tokens: float64(max)}
+}
+
+// Request adds a request to the budget.
+func (b *Budget) Request() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens += b.ratio
+	if b.tokens > b.max {
+		b.tokens = b.max
+	}
+}
+
+// Retry reports whether a retry is within the budget, and takes it out of the
+// budget if it is.
+func (b *Budget) Retry() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Retry calls fn until it succeeds, returns an error that is not retryable or
+// has been called attempts times. Before each retry it waits for delay, or
+// for as long as Shopify asked when rate limited, and takes the retry out of
+// the budget. A nil budget means DefaultBudget.
+//
+// A POST that got a server error or no response may still have created its
+// resource, and retrying it can create a second one. Retry creates with
+// RetryUnsent instead, unless they are safe to make twice.
+func Retry(budget *Budget, attempts int, delay time.Duration, fn func() error) error {
+	return retry(budget, attempts, delay, IsRetryable, fn)
+}
+
+// Call fn until it succeeds, returns an error that retryable rejects or has
+// been called attempts times.
+func retry(budget *Budget, attempts int, delay time.Duration, retryable func(error) bool, fn func() error) error {
+	if budget == nil {
+		budget = DefaultBudget
+	}
+
+	budget.Request()
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !retryable(err) || attempt >= attempts {
+			return err
+		}
+		if !budget.Retry() {
+			return BudgetExhaustedError{Err: err}
+		}
+
+		wait := delay
+		if retryAfter := RetryAfter(err); retryAfter > 0 {
+			wait = time.Duration(retryAfter) * time.Second
+		}
+		time.Sleep(wait)
+	}
+}
+
+// RetryUnsent is Retry for requests that must not be made twice, such as a
+// POST creating a resource: only errors for which IsUnsent reports true are
+// retried.
+func RetryUnsent(budget *Budget, attempts int, delay time.Duration, fn func() error) error {
+	return retry(budget, attempts, delay, IsUnsent, fn)
+}
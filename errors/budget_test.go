@@ -0,0 +1,92 @@
+package errors
+
+import (
+	"testing"
+
+	goshopify "github.com/getconversio/go-shopify"
+)
+
+func TestBudget(t *testing.T) {
+	budget := NewBudget(0.5, 2)
+
+	if !budget.Retry() || !budget.Retry() {
+		t.Errorf("Budget.Retry refused a retry of a full budget")
+	}
+	if budget.Retry() {
+		t.Errorf("Budget.Retry allowed a retry of an empty budget")
+	}
+
+	budget.Request()
+	if budget.Retry() {
+		t.Errorf("Budget.Retry allowed a retry after half a request's worth")
+	}
+	budget.Request()
+	if !budget.Retry() {
+		t.Errorf("Budget.Retry refused a retry after two requests")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	serverError := goshopify.ResponseError{Status: 503}
+
+	cases := []struct {
+		description string
+		errs        []error
+		attempts    int
+		calls       int
+		expected    error
+	}{
+		{"succeeds after retries", []error{serverError, serverError, nil}, 5, 3, nil},
+		{"gives up after attempts", []error{serverError, serverError, serverError}, 2, 2, serverError},
+		{"does not retry permanent errors", []error{goshopify.ResponseError{Status: 404}}, 5, 1, goshopify.ResponseError{Status: 404}},
+	}
+
+	for _, c := range cases {
+		calls := 0
+		err := Retry(NewBudget(0.1, 10), c.attempts, 0, func() error {
+			err := c.errs[calls]
+			calls++
+			return err
+		})
+		if calls != c.calls {
+			t.Errorf("Retry %s: called fn %d times, expected %d", c.description, calls, c.calls)
+		}
+		if err == nil && c.expected != nil || err != nil && err.Error() != c.expected.Error() {
+			t.Errorf("Retry %s: returned %v, expected %v", c.description, err, c.expected)
+		}
+	}
+}
+
+func TestRetryBudgetExhausted(t *testing.T) {
+	budget := NewBudget(0.1, 1)
+	calls := 0
+	err := Retry(budget, 5, 0, func() error {
+		calls++
+		return goshopify.ResponseError{Status: 503}
+	})
+
+	if _, ok := err.(BudgetExhaustedError); !ok || calls != 2 {
+		t.Errorf("Retry returned %v after %d calls, expected the budget to run out after 2", err, calls)
+	}
+	if IsRetryable(err) || Status(err) != 503 {
+		t.Errorf("Retry returned %v, which should not be retryable but keep its status", err)
+	}
+}
+
+func TestRetryUnsent(t *testing.T) {
+	rateLimited := goshopify.RateLimitError{ResponseError: goshopify.ResponseError{Status: 429}}
+	serverError := goshopify.ResponseError{Status: 503}
+
+	errs := []error{rateLimited, serverError, nil}
+	calls := 0
+	err := RetryUnsent(NewBudget(0.1, 10), 5, 0, func() error {
+		err := errs[calls]
+		calls++
+		return err
+	})
+
+	// The server error may have created the resource
+	if calls != 2 || Status(err) != 503 {
+		t.Errorf("RetryUnsent returned %v after %d calls, expected the server error after 2", err, calls)
+	}
+}
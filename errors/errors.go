@@ -0,0 +1,157 @@
+// Package errors classifies the errors returned by goshopify, so callers can
+// decide whether to retry a request without looking at the error text, and
+// limits retries with a budget so that they cannot make an outage worse.
+//
+// Import it under another name to keep the standard errors package at hand:
+//
+//	import shopifyerrors "github.com/getconversio/go-shopify/errors"
+package errors
+
+import (
+	"context"
+	"net"
+	"net/url"
+
+	goshopify "github.com/getconversio/go-shopify"
+)
+
+// Status returns the HTTP status of the response an error is about, or 0 if
+// there was no response.
+func Status(err error) int {
+	switch err := cause(err).(type) {
+	case goshopify.RateLimitError:
+		return err.Status
+	case goshopify.ResponseError:
+		return err.Status
+	case goshopify.ResponseDecodingError:
+		return err.Status
+	}
+	return 0
+}
+
+// IsRetryable reports whether a request may succeed when it is made again:
+// it was rate limited, Shopify had a server error, the request timed out or
+// could not reach Shopify, or the circuit breaker of the client was open.
+// Requests that must not be made twice should be retried only if IsUnsent
+// reports true, as the others may have been made.
+func IsRetryable(err error) bool {
+	if _, ok := err.(BudgetExhaustedError); ok || err == nil {
+		return false
+	}
+
+	status := Status(err)
+	if status != 0 {
+		return status == 429 || status >= 500
+	}
+
+	switch err := cause(err).(type) {
+	case *url.Error:
+		// The request did not get a response
+		return err.Err != context.Canceled
+	case goshopify.CircuitOpenError, net.Error:
+		return true
+	default:
+		return err == context.DeadlineExceeded
+	}
+}
+
+// IsUnsent reports whether a request failed without being made: it was rate
+// limited, the circuit breaker of the client was open or Shopify could not be
+// connected to. Unlike other retryable errors, these are safe to retry for
+// requests that must not be made twice.
+func IsUnsent(err error) bool {
+	if _, ok := err.(BudgetExhaustedError); ok || err == nil {
+		return false
+	}
+	if IsRateLimited(err) {
+		return true
+	}
+
+	switch err := cause(err).(type) {
+	case goshopify.CircuitOpenError:
+		return true
+	case *url.Error:
+		opErr, ok := err.Err.(*net.OpError)
+		return ok && opErr.Op == "dial"
+	}
+	return false
+}
+
+// IsPermanent reports whether a request will fail in the same way when it is
+// made again, e.g. because it is not authorized, the resource does not exist
+// or the data is invalid.
+func IsPermanent(err error) bool {
+	if err == nil {
+		return false
+	}
+	if _, ok := cause(err).(goshopify.SafeguardError); ok {
+		return true
+	}
+
+	switch Status(err) {
+	case 400, 401, 402, 403, 404, 406, 422:
+		return true
+	}
+	return false
+}
+
+// IsRateLimited reports whether a request was refused because the client
+// made too many requests.
+func IsRateLimited(err error) bool {
+	return Status(err) == 429
+}
+
+// IsUnauthorized reports whether the access token was missing or invalid,
+// e.g. because the app was uninstalled.
+func IsUnauthorized(err error) bool {
+	return Status(err) == 401
+}
+
+// IsForbidden reports whether the app lacks the access scope for a request.
+func IsForbidden(err error) bool {
+	return Status(err) == 403
+}
+
+// IsNotFound reports whether a resource does not exist.
+func IsNotFound(err error) bool {
+	return Status(err) == 404
+}
+
+// IsValidation reports whether Shopify refused the data of a request. The
+// errors per field are in the Errors of the goshopify.ResponseError.
+func IsValidation(err error) bool {
+	return Status(err) == 422
+}
+
+// RetryAfter returns the seconds Shopify asked to wait before retrying a
+// rate limited request, or 0 if it did not say.
+func RetryAfter(err error) int {
+	if err, ok := cause(err).(goshopify.RateLimitError); ok {
+		return err.RetryAfter
+	}
+	return 0
+}
+
+// Return the error an error is about, for errors that wrap another one.
+func cause(err error) error {
+	for {
+		switch e := err.(type) {
+		case *url.Error:
+			// Kept, as it tells that the request got no response
+			return err
+		case goshopify.QueuedError:
+			if e.Err == nil {
+				return err
+			}
+			err = e.Err
+		case interface{ Unwrap() error }:
+			inner := e.Unwrap()
+			if inner == nil {
+				return err
+			}
+			err = inner
+		default:
+			return err
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+
+	goshopify "github.com/getconversio/go-shopify"
+)
+
+func TestClassification(t *testing.T) {
+	rateLimited := goshopify.RateLimitError{ResponseError: goshopify.ResponseError{Status: 429}, RetryAfter: 2}
+	unreachable := &url.Error{Op: "Get", URL: "https://fooshop.myshopify.com", Err: &net.OpError{Op: "dial", Err: fmt.Errorf("connection refused")}}
+	canceled := &url.Error{Op: "Get", URL: "https://fooshop.myshopify.com", Err: context.Canceled}
+
+	noResponse := &url.Error{Op: "Post", URL: "https://fooshop.myshopify.com", Err: &net.OpError{Op: "read", Err: fmt.Errorf("connection reset by peer")}}
+
+	cases := []struct {
+		err       error
+		retryable bool
+		unsent    bool
+		permanent bool
+		status    int
+	}{
+		{nil, false, false, false, 0},
+		{rateLimited, true, true, false, 429},
+		{goshopify.ResponseError{Status: 500}, true, false, false, 500},
+		{goshopify.ResponseError{Status: 503}, true, false, false, 503},
+		{goshopify.ResponseDecodingError{Status: 502}, true, false, false, 502},
+		{goshopify.ResponseError{Status: 401}, false, false, true, 401},
+		{goshopify.ResponseError{Status: 403}, false, false, true, 403},
+		{goshopify.ResponseError{Status: 404}, false, false, true, 404},
+		{goshopify.ResponseError{Status: 422}, false, false, true, 422},
+		{goshopify.SafeguardError{Operation: "DELETE admin/products/1.json"}, false, false, true, 0},
+		{goshopify.CircuitOpenError{Shop: "fooshop.myshopify.com"}, true, true, false, 0},
+		{unreachable, true, true, false, 0},
+		{noResponse, true, false, false, 0},
+		{canceled, false, false, false, 0},
+		{context.DeadlineExceeded, true, false, false, 0},
+		{&json.SyntaxError{}, false, false, false, 0},
+		{goshopify.QueuedError{Err: goshopify.ResponseError{Status: 503}}, true, false, false, 503},
+		{BudgetExhaustedError{Err: goshopify.ResponseError{Status: 503}}, false, false, false, 503},
+	}
+
+	for _, c := range cases {
+		if actual := IsRetryable(c.err); actual != c.retryable {
+			t.Errorf("IsRetryable(%#v) returned %v, expected %v", c.err, actual, c.retryable)
+		}
+		if actual := IsUnsent(c.err); actual != c.unsent {
+			t.Errorf("IsUnsent(%#v) returned %v, expected %v", c.err, actual, c.unsent)
+		}
+		if actual := IsPermanent(c.err); actual != c.permanent {
+			t.Errorf("IsPermanent(%#v) returned %v, expected %v", c.err, actual, c.permanent)
+		}
+		if actual := Status(c.err); actual != c.status {
+			t.Errorf("Status(%#v) returned %d, expected %d", c.err, actual, c.status)
+		}
+	}
+
+	if !IsRateLimited(rateLimited) || RetryAfter(rateLimited) != 2 {
+		t.Errorf("IsRateLimited or RetryAfter did not recognise %#v", rateLimited)
+	}
+	if !IsNotFound(goshopify.ResponseError{Status: 404}) || IsNotFound(goshopify.ResponseError{Status: 422}) {
+		t.Errorf("IsNotFound did not tell 404 from 422")
+	}
+	if !IsValidation(goshopify.ResponseError{Status: 422}) {
+		t.Errorf("IsValidation did not recognise a 422")
+	}
+	if !IsUnauthorized(goshopify.ResponseError{Status: 401}) || !IsForbidden(goshopify.ResponseError{Status: 403}) {
+		t.Errorf("IsUnauthorized or IsForbidden did not recognise 401 and 403")
+	}
+}
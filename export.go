@@ -0,0 +1,585 @@
+package goshopify
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const metafieldFields = `
+	metafields {
+		edges { node { id namespace key value valueType description createdAt updatedAt } }
+	}
+`
+
+const exportProductsQuery = `{
+	products%s {
+		edges { node {
+			id title handle vendor productType descriptionHtml tags templateSuffix
+			createdAt updatedAt publishedAt
+			variants {
+				edges { node {
+					id title sku barcode position price compareAtPrice
+					inventoryQuantity inventoryPolicy taxable createdAt updatedAt
+					inventoryItem { id }
+				} }
+			}
+			` + metafieldFields + `
+		} }
+	}
+}`
+
+// Bulk queries can only nest connections two deep, so the inventory levels
+// of variants are listed by inventory item.
+const exportInventoryQuery = `{
+	inventoryItems%s {
+		edges { node {
+			id
+			inventoryLevels {
+				edges { node { id available updatedAt item { id } location { id } } }
+			}
+		} }
+	}
+}`
+
+const exportCollectionsQuery = `{
+	collections%s {
+		edges { node {
+			id title handle descriptionHtml sortOrder templateSuffix updatedAt
+			ruleSet { appliedDisjunctively rules { column relation condition } }
+			products {
+				edges { node { id } }
+			}
+			` + metafieldFields + `
+		} }
+	}
+}`
+
+// The lists of a bulk export in the order they are exported, and their
+// queries
+var bulkExportLists = []struct{ name, query string }{
+	{"products", exportProductsQuery},
+	{"inventory_items", exportInventoryQuery},
+	{"collections", exportCollectionsQuery},
+}
+
+// ExportService is an interface for exporting a complete copy of a shop's
+// data.
+type ExportService interface {
+	Catalog(func(ExportRecord) error) error
+	CatalogFrom(PageInfo, func(ExportRecord, PageInfo) error) error
+}
+
+// ExportServiceOp exports shop data with bulk operations when the GraphQL
+// endpoint is available and with the paginated REST endpoints otherwise.
+type ExportServiceOp struct {
+	client *Client
+}
+
+// ExportRecord is a single record of an export. Exactly one of its fields
+// is set.
+type ExportRecord struct {
+	Product          *Product
+	Variant          *Variant
+	CustomCollection *CustomCollection
+	SmartCollection  *SmartCollection
+	Collect          *Collect
+	InventoryLevel   *InventoryLevel
+	Metafield        *Metafield
+}
+
+// A line of a catalog bulk operation result. Products, variants, inventory
+// items and their levels, collections, the products of collections and
+// metafields are all decoded into it and told apart by their id and parent.
+type catalogLine struct {
+	ID                string           `json:"id"`
+	ParentID          string           `json:"__parentId"`
+	Title             string           `json:"title"`
+	Handle            string           `json:"handle"`
+	Vendor            string           `json:"vendor"`
+	ProductType       string           `json:"productType"`
+	DescriptionHTML   string           `json:"descriptionHtml"`
+	Tags              []string         `json:"tags"`
+	TemplateSuffix    string           `json:"templateSuffix"`
+	CreatedAt         *time.Time       `json:"createdAt"`
+	UpdatedAt         *time.Time       `json:"updatedAt"`
+	PublishedAt       *time.Time       `json:"publishedAt"`
+	Sku               string           `json:"sku"`
+	Barcode           string           `json:"barcode"`
+	Position          int              `json:"position"`
+	Price             *decimal.Decimal `json:"price"`
+	CompareAtPrice    *decimal.Decimal `json:"compareAtPrice"`
+	InventoryQuantity int              `json:"inventoryQuantity"`
+	InventoryPolicy   string           `json:"inventoryPolicy"`
+	Taxable           bool             `json:"taxable"`
+	Namespace         string           `json:"namespace"`
+	Key               string           `json:"key"`
+	Value             interface{}      `json:"value"`
+	ValueType         string           `json:"valueType"`
+	Description       string           `json:"description"`
+	SortOrder         string           `json:"sortOrder"`
+	RuleSet           *struct {
+		AppliedDisjunctively bool   `json:"appliedDisjunctively"`
+		Rules                []Rule `json:"rules"`
+	} `json:"ruleSet"`
+	InventoryItem *struct {
+		ID string `json:"id"`
+	} `json:"inventoryItem"`
+	Available *int `json:"available"`
+	Item      *struct {
+		ID string `json:"id"`
+	} `json:"item"`
+	Location *struct {
+		ID string `json:"id"`
+	} `json:"location"`
+}
+
+// Catalog exports the shop's products, variants, collections, collects and
+// the metafields of products and collections, calling fn for each record.
+// Inventory is exported as the InventoryQuantity of each variant and as the
+// inventory levels of its item at each location. With the REST endpoints the
+// levels follow their variant; with bulk operations they follow all of the
+// products. The products of each collection are exported as collects, which
+// only have an id when they are collects of custom collections listed with
+// the REST endpoints.
+//
+// Products are exported without their variants, which follow as records of
+// their own. Exporting stops at the first error returned by fn.
+func (s *ExportServiceOp) Catalog(fn func(ExportRecord) error) error {
+	return s.CatalogFrom(PageInfo{}, func(record ExportRecord, _ PageInfo) error {
+		return fn(record)
+	})
+}
+
+// CatalogFrom is Catalog continuing after a saved position, and passes fn the
+// position to continue from after each record. An empty PageInfo starts at
+// the beginning.
+//
+// Products, inventory items and collections are exported in order of id, so
+// the position is the last one all of whose records were passed to fn. The
+// records of the one that was being exported when the position was saved are
+// passed again when continuing. A position saved while exporting with bulk
+// operations and continued with the REST endpoints, or the other way around,
+// starts the export over unless it is in the products, which both list the
+// same way. CatalogFrom returns nil once the export is complete.
+func (s *ExportServiceOp) CatalogFrom(after PageInfo, fn func(ExportRecord, PageInfo) error) error {
+	start, position := exportStart(len(bulkExportLists), func(i int) string { return bulkExportLists[i].name }, after)
+	for i := start; i < len(bulkExportLists); i++ {
+		_, err := s.client.BulkOperation.RunQuery(bulkExportQuery(bulkExportLists[i].query, position))
+		if i == start && isUnavailable(err) {
+			return s.restCatalog(after, fn)
+		}
+		if err != nil {
+			return err
+		}
+
+		err = s.bulkRecords(bulkExportLists[i].name, position, fn)
+		if err != nil {
+			return err
+		}
+		position = PageInfo{}
+	}
+	return nil
+}
+
+// Find the list an export continues in, given the names of its lists, and the
+// position in it.
+func exportStart(lists int, name func(int) string, after PageInfo) (int, PageInfo) {
+	if after.Resource == "" {
+		return 0, PageInfo{}
+	}
+	for i := 0; i < lists; i++ {
+		if name(i) == after.Resource {
+			return i, after
+		}
+	}
+
+	// Only the products are listed the same way by both kinds of export
+	return 0, PageInfo{}
+}
+
+// Return a bulk query that lists the records after the position.
+func bulkExportQuery(query string, after PageInfo) string {
+	arguments := ""
+	if after.SinceID > 0 {
+		arguments = fmt.Sprintf(`(query: "id:>%d")`, after.SinceID)
+	}
+	return fmt.Sprintf(query, arguments)
+}
+
+// Wait for the running bulk operation and pass its results to fn, with the
+// position in the named list. The lines of a product or collection follow its
+// own line, so it is complete once the next one starts.
+func (s *ExportServiceOp) bulkRecords(list string, after PageInfo, fn func(ExportRecord, PageInfo) error) error {
+	op, err := s.client.BulkOperation.Wait(DefaultBulkOperationPollInterval)
+	if err != nil {
+		return err
+	}
+
+	position := PageInfo{HasNextPage: true, Resource: list, SinceID: after.SinceID}
+	current := after.SinceID
+	return s.client.BulkOperation.Records(op, func(data json.RawMessage) error {
+		line := catalogLine{}
+		err := json.Unmarshal(data, &line)
+		if err != nil {
+			return err
+		}
+
+		if line.ParentID == "" {
+			position.SinceID = current
+			current = ParseGID(line.ID)
+		}
+
+		// Inventory items are only listed for their levels
+		if gidResource(line.ID) == "InventoryItem" {
+			return nil
+		}
+		record, err := line.record()
+		if err != nil {
+			return err
+		}
+		return fn(record, position)
+	})
+}
+
+// Report whether the error means the shop's API version has no bulk
+// operations. A 403 means the app lacks an access scope, which the REST
+// endpoints need just as well, so it is returned.
+func isUnavailable(err error) bool {
+	responseError, ok := err.(ResponseError)
+	return ok && responseError.Status == 404
+}
+
+func (line catalogLine) record() (ExportRecord, error) {
+	id := ParseGID(line.ID)
+
+	switch gidResource(line.ID) {
+	case "Product":
+		if line.ParentID != "" {
+			return ExportRecord{Collect: &Collect{
+				CollectionID: int(ParseGID(line.ParentID)),
+				ProductID:    int(id),
+			}}, nil
+		}
+		return ExportRecord{Product: &Product{
+			ID:             id,
+			Title:          line.Title,
+			BodyHTML:       line.DescriptionHTML,
+			Vendor:         line.Vendor,
+			ProductType:    line.ProductType,
+			Handle:         line.Handle,
+			CreatedAt:      line.CreatedAt,
+			UpdatedAt:      line.UpdatedAt,
+			PublishedAt:    line.PublishedAt,
+			Tags:           strings.Join(line.Tags, ", "),
+			TemplateSuffix: line.TemplateSuffix,
+		}}, nil
+	case "ProductVariant":
+		inventoryItemID := 0
+		if line.InventoryItem != nil {
+			inventoryItemID = int(ParseGID(line.InventoryItem.ID))
+		}
+		return ExportRecord{Variant: &Variant{
+			ID:                id,
+			ProductID:         int(ParseGID(line.ParentID)),
+			Title:             line.Title,
+			Sku:               line.Sku,
+			Barcode:           line.Barcode,
+			Position:          line.Position,
+			Price:             line.Price,
+			CompareAtPrice:    line.CompareAtPrice,
+			InventoryQuantity: line.InventoryQuantity,
+			InventoryPolicy:   strings.ToLower(line.InventoryPolicy),
+			InventoryItemID:   inventoryItemID,
+			Taxable:           line.Taxable,
+			CreatedAt:         line.CreatedAt,
+			UpdatedAt:         line.UpdatedAt,
+		}}, nil
+	case "InventoryLevel":
+		level := &InventoryLevel{UpdatedAt: line.UpdatedAt}
+		if line.Available != nil {
+			level.Available = *line.Available
+		}
+		if line.Item != nil {
+			level.InventoryItemID = int(ParseGID(line.Item.ID))
+		}
+		if line.Location != nil {
+			level.LocationID = int(ParseGID(line.Location.ID))
+		}
+		return ExportRecord{InventoryLevel: level}, nil
+	case "Collection":
+		if line.RuleSet == nil {
+			return ExportRecord{CustomCollection: &CustomCollection{
+				ID:             int(id),
+				Handle:         line.Handle,
+				Title:          line.Title,
+				UpdatedAt:      line.UpdatedAt,
+				BodyHTML:       line.DescriptionHTML,
+				SortOrder:      restSortOrder(line.SortOrder),
+				TemplateSuffix: line.TemplateSuffix,
+			}}, nil
+		}
+
+		rules := make([]Rule, len(line.RuleSet.Rules))
+		for i, rule := range line.RuleSet.Rules {
+			rules[i] = Rule{
+				Column:    strings.ToLower(rule.Column),
+				Relation:  strings.ToLower(rule.Relation),
+				Condition: rule.Condition,
+			}
+		}
+		return ExportRecord{SmartCollection: &SmartCollection{
+			ID:             int(id),
+			Handle:         line.Handle,
+			Title:          line.Title,
+			UpdatedAt:      line.UpdatedAt,
+			BodyHTML:       line.DescriptionHTML,
+			SortOrder:      restSortOrder(line.SortOrder),
+			TemplateSuffix: line.TemplateSuffix,
+			Rules:          rules,
+			Disjunctive:    line.RuleSet.AppliedDisjunctively,
+		}}, nil
+	case "Metafield":
+		return ExportRecord{Metafield: &Metafield{
+			ID:            id,
+			Key:           line.Key,
+			Value:         line.Value,
+			ValueType:     strings.ToLower(line.ValueType),
+			Namespace:     line.Namespace,
+			Description:   line.Description,
+			OwnerId:       int(ParseGID(line.ParentID)),
+			CreatedAt:     line.CreatedAt,
+			UpdatedAt:     line.UpdatedAt,
+			OwnerResource: metafieldOwnerResources[gidResource(line.ParentID)],
+		}}, nil
+	}
+
+	return ExportRecord{}, fmt.Errorf("unexpected record %s in export", line.ID)
+}
+
+// Turn a GraphQL sort order such as BEST_SELLING into its REST form,
+// best-selling.
+func restSortOrder(sortOrder string) string {
+	return strings.Replace(strings.ToLower(sortOrder), "_", "-", -1)
+}
+
+// The number of inventory items whose levels are listed per REST request
+const inventoryLevelsBatchSize = 50
+
+// An export of a list of REST records, and of the records that go with each.
+// The records of a page are looked up together and returned grouped by item.
+type restExportList struct {
+	name    string
+	list    func(*Client, ListOptions) ([]syncItem, error)
+	records func(s *ExportServiceOp, items []syncItem) ([][]ExportRecord, error)
+}
+
+// The lists of a REST export in the order they are exported
+var restExportLists = []restExportList{
+	{productsResourceName, syncResources[productsResourceName].list, restProductRecords},
+	{"custom_collections", listCustomCollections, restCustomCollectionRecords},
+	{"smart_collections", listSmartCollections, restSmartCollectionRecords},
+	{"collects", listCollects, restCollectRecords},
+}
+
+func (s *ExportServiceOp) restCatalog(after PageInfo, fn func(ExportRecord, PageInfo) error) error {
+	start, position := exportStart(len(restExportLists), func(i int) string { return restExportLists[i].name }, after)
+	for _, list := range restExportLists[start:] {
+		position = PageInfo{HasNextPage: true, Resource: list.name, SinceID: position.SinceID}
+		err := listPages(s.client, list.list, position.ListOptions(ListOptions{}), func(items []syncItem, _ bool) error {
+			records, err := list.records(s, items)
+			if err != nil {
+				return err
+			}
+
+			for i, item := range items {
+				for _, record := range records[i] {
+					err := fn(record, position)
+					if err != nil {
+						return err
+					}
+				}
+				position.SinceID = item.id
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		position = PageInfo{}
+	}
+	return nil
+}
+
+// Return the records of a page of products: each product, followed by its
+// variants, each with the inventory levels of its item, and its metafields.
+func restProductRecords(s *ExportServiceOp, items []syncItem) ([][]ExportRecord, error) {
+	ids := make([]uint64, len(items))
+	inventoryItemIDs := []int{}
+	for i, item := range items {
+		ids[i] = item.id
+		for _, variant := range item.resource.(*Product).Variants {
+			if variant.InventoryItemID != 0 {
+				inventoryItemIDs = append(inventoryItemIDs, variant.InventoryItemID)
+			}
+		}
+	}
+
+	metafields, err := s.client.Metafield.ListByOwners(productsResourceName, ids)
+	if err != nil {
+		return nil, err
+	}
+	levels, err := s.restInventoryLevels(inventoryItemIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([][]ExportRecord, len(items))
+	for i, item := range items {
+		product := item.resource.(*Product)
+		variants := product.Variants
+		product.Variants = nil
+
+		records[i] = append(records[i], ExportRecord{Product: product})
+		for j := range variants {
+			records[i] = append(records[i], ExportRecord{Variant: &variants[j]})
+			variantLevels := levels[variants[j].InventoryItemID]
+			for k := range variantLevels {
+				records[i] = append(records[i], ExportRecord{InventoryLevel: &variantLevels[k]})
+			}
+		}
+		records[i] = appendMetafieldRecords(records[i], metafields[item.id])
+	}
+	return records, nil
+}
+
+func restCustomCollectionRecords(s *ExportServiceOp, items []syncItem) ([][]ExportRecord, error) {
+	return s.restCollectionRecords(items, func(item syncItem) ([]ExportRecord, error) {
+		return []ExportRecord{{CustomCollection: item.resource.(*CustomCollection)}}, nil
+	})
+}
+
+// The products of smart collections have no collects to list, so they are
+// listed for each collection.
+func restSmartCollectionRecords(s *ExportServiceOp, items []syncItem) ([][]ExportRecord, error) {
+	return s.restCollectionRecords(items, func(item syncItem) ([]ExportRecord, error) {
+		records := []ExportRecord{{SmartCollection: item.resource.(*SmartCollection)}}
+		err := listAll(s.client, listCollectionProducts(item.id), ListOptions{Fields: "id"}, func(product syncItem, _ PageInfo) error {
+			records = append(records, ExportRecord{Collect: &Collect{CollectionID: int(item.id), ProductID: int(product.id)}})
+			return nil
+		})
+		return records, err
+	})
+}
+
+// Return the records of each collection of a page followed by its
+// metafields.
+func (s *ExportServiceOp) restCollectionRecords(items []syncItem, collection func(syncItem) ([]ExportRecord, error)) ([][]ExportRecord, error) {
+	ids := make([]uint64, len(items))
+	for i, item := range items {
+		ids[i] = item.id
+	}
+	metafields, err := s.client.Metafield.ListByOwners("collections", ids)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([][]ExportRecord, len(items))
+	for i, item := range items {
+		records[i], err = collection(item)
+		if err != nil {
+			return nil, err
+		}
+		records[i] = appendMetafieldRecords(records[i], metafields[item.id])
+	}
+	return records, nil
+}
+
+func restCollectRecords(s *ExportServiceOp, items []syncItem) ([][]ExportRecord, error) {
+	records := make([][]ExportRecord, len(items))
+	for i, item := range items {
+		records[i] = []ExportRecord{{Collect: item.resource.(*Collect)}}
+	}
+	return records, nil
+}
+
+func appendMetafieldRecords(records []ExportRecord, metafields []Metafield) []ExportRecord {
+	for i := range metafields {
+		records = append(records, ExportRecord{Metafield: &metafields[i]})
+	}
+	return records
+}
+
+// Return the inventory levels of the inventory items, keyed by item id.
+func (s *ExportServiceOp) restInventoryLevels(inventoryItemIDs []int) (map[int][]InventoryLevel, error) {
+	levels := make(map[int][]InventoryLevel)
+	for start := 0; start < len(inventoryItemIDs); start += inventoryLevelsBatchSize {
+		end := start + inventoryLevelsBatchSize
+		if end > len(inventoryItemIDs) {
+			end = len(inventoryItemIDs)
+		}
+
+		options := InventoryLevelListOptions{InventoryItemIDs: inventoryItemIDs[start:end], Limit: syncPageLimit}
+		for options.Page = 1; ; options.Page++ {
+			page, err := s.client.InventoryLevel.List(options)
+			if err != nil {
+				return nil, err
+			}
+			for _, level := range page {
+				levels[level.InventoryItemID] = append(levels[level.InventoryItemID], level)
+			}
+			if len(page) < syncPageLimit {
+				break
+			}
+		}
+	}
+	return levels, nil
+}
+
+// Options to list the products of a collection
+type collectionProductsOptions struct {
+	ListOptions
+	CollectionID int `url:"collection_id"`
+}
+
+// Return a list of the products of a collection.
+func listCollectionProducts(collectionID uint64) func(*Client, ListOptions) ([]syncItem, error) {
+	return func(c *Client, options ListOptions) ([]syncItem, error) {
+		products, err := c.Product.List(collectionProductsOptions{options, int(collectionID)})
+		items := make([]syncItem, len(products))
+		for i := range products {
+			items[i] = syncItem{products[i].ID, products[i].UpdatedAt, &products[i]}
+		}
+		return items, err
+	}
+}
+
+func listCustomCollections(c *Client, options ListOptions) ([]syncItem, error) {
+	collections, err := c.CustomCollection.List(options)
+	items := make([]syncItem, len(collections))
+	for i := range collections {
+		items[i] = syncItem{uint64(collections[i].ID), collections[i].UpdatedAt, &collections[i]}
+	}
+	return items, err
+}
+
+func listSmartCollections(c *Client, options ListOptions) ([]syncItem, error) {
+	collections, err := c.SmartCollection.List(options)
+	items := make([]syncItem, len(collections))
+	for i := range collections {
+		items[i] = syncItem{uint64(collections[i].ID), collections[i].UpdatedAt, &collections[i]}
+	}
+	return items, err
+}
+
+func listCollects(c *Client, options ListOptions) ([]syncItem, error) {
+	collects, err := c.Collect.List(options)
+	items := make([]syncItem, len(collects))
+	for i := range collects {
+		items[i] = syncItem{uint64(collects[i].ID), collects[i].UpdatedAt, &collects[i]}
+	}
+	return items, err
+}
@@ -0,0 +1,305 @@
+package goshopify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+)
+
+// Describe a record by its type and id.
+func exportRecordName(record ExportRecord) string {
+	switch {
+	case record.Product != nil:
+		return fmt.Sprintf("product %d", record.Product.ID)
+	case record.Variant != nil:
+		return fmt.Sprintf("variant %d", record.Variant.ID)
+	case record.CustomCollection != nil:
+		return fmt.Sprintf("custom collection %d", record.CustomCollection.ID)
+	case record.SmartCollection != nil:
+		return fmt.Sprintf("smart collection %d", record.SmartCollection.ID)
+	case record.Collect != nil:
+		return fmt.Sprintf("collect %d of product %d in %d", record.Collect.ID, record.Collect.ProductID, record.Collect.CollectionID)
+	case record.InventoryLevel != nil:
+		return fmt.Sprintf("inventory level of %d at %d", record.InventoryLevel.InventoryItemID, record.InventoryLevel.LocationID)
+	case record.Metafield != nil:
+		return fmt.Sprintf("metafield %d", record.Metafield.ID)
+	}
+	return "empty"
+}
+
+func TestExportCatalogBulk(t *testing.T) {
+	setup()
+	defer teardown()
+
+	resultURL := ""
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/graphql.json",
+		func(req *http.Request) (*http.Response, error) {
+			body := struct {
+				Query     string            `json:"query"`
+				Variables map[string]string `json:"variables"`
+			}{}
+			json.NewDecoder(req.Body).Decode(&body)
+
+			if strings.Contains(body.Query, "bulkOperationRunQuery") {
+				switch query := body.Variables["query"]; {
+				case strings.Contains(query, "collections"):
+					resultURL = "https://storage.googleapis.com/shopify/collections.jsonl"
+				case strings.Contains(query, "inventoryItems"):
+					resultURL = "https://storage.googleapis.com/shopify/inventory.jsonl"
+				default:
+					resultURL = "https://storage.googleapis.com/shopify/products.jsonl"
+				}
+				return httpmock.NewStringResponse(200, `{"data": {"bulkOperationRunQuery": {
+					"bulkOperation": {"id": "gid://shopify/BulkOperation/1", "status": "CREATED"}, "userErrors": []
+				}}}`), nil
+			}
+
+			return httpmock.NewStringResponse(200, `{"data": {"currentBulkOperation": {
+				"id": "gid://shopify/BulkOperation/1", "status": "COMPLETED", "url": "`+resultURL+`"
+			}}}`), nil
+		})
+
+	httpmock.RegisterResponder("GET", "https://storage.googleapis.com/shopify/products.jsonl",
+		httpmock.NewBytesResponder(200, loadFixture("export_products.jsonl")))
+	httpmock.RegisterResponder("GET", "https://storage.googleapis.com/shopify/inventory.jsonl",
+		httpmock.NewBytesResponder(200, loadFixture("export_inventory.jsonl")))
+	httpmock.RegisterResponder("GET", "https://storage.googleapis.com/shopify/collections.jsonl",
+		httpmock.NewBytesResponder(200, loadFixture("export_collections.jsonl")))
+
+	records := []ExportRecord{}
+	err := client.Export.Catalog(func(record ExportRecord) error {
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Export.Catalog returned error: %v", err)
+	}
+
+	names := []string{}
+	for _, record := range records {
+		names = append(names, exportRecordName(record))
+	}
+	expected := []string{
+		"product 1", "variant 11", "metafield 21", "inventory level of 41 at 51",
+		"custom collection 31", "collect 0 of product 1 in 31", "smart collection 32",
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("Export.Catalog returned %v, expected %v", names, expected)
+	}
+
+	product, variant, metafield, level := records[0].Product, records[1].Variant, records[2].Metafield, records[3].InventoryLevel
+	custom, smart := records[4].CustomCollection, records[6].SmartCollection
+	price := decimal.NewFromFloat(10)
+	cases := []struct {
+		field    string
+		expected interface{}
+		actual   interface{}
+	}{
+		{"Product.Tags", "cotton, summer", product.Tags},
+		{"Product.BodyHTML", "<p>Soft</p>", product.BodyHTML},
+		{"Variant.ProductID", 1, variant.ProductID},
+		{"Variant.Sku", "SHIRT-S", variant.Sku},
+		{"Variant.Price", true, price.Equals(*variant.Price)},
+		{"Variant.InventoryQuantity", 5, variant.InventoryQuantity},
+		{"Variant.InventoryPolicy", "deny", variant.InventoryPolicy},
+		{"Variant.InventoryItemID", 41, variant.InventoryItemID},
+		{"InventoryLevel.Available", 5, level.Available},
+		{"Metafield.OwnerId", 1, metafield.OwnerId},
+		{"Metafield.OwnerResource", "product", metafield.OwnerResource},
+		{"Metafield.ValueType", "string", metafield.ValueType},
+		{"CustomCollection.SortOrder", "best-selling", custom.SortOrder},
+		{"SmartCollection.Rules", []Rule{{"tag", "equals", "cotton"}}, smart.Rules},
+	}
+
+	for _, c := range cases {
+		if !reflect.DeepEqual(c.expected, c.actual) {
+			t.Errorf("%v returned %v, expected %v", c.field, c.actual, c.expected)
+		}
+	}
+}
+
+// Register a GraphQL endpoint without bulk operations, which looks up
+// metafields by owner. The ids of the metafields are keyed by the gid of their
+// owner.
+func registerExportGraphQL(metafields map[string][]int) {
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/graphql.json",
+		func(req *http.Request) (*http.Response, error) {
+			body := struct {
+				Query     string `json:"query"`
+				Variables struct {
+					IDs []string `json:"ids"`
+				} `json:"variables"`
+			}{}
+			json.NewDecoder(req.Body).Decode(&body)
+			if strings.Contains(body.Query, "bulkOperationRunQuery") {
+				return httpmock.NewStringResponse(404, `{"errors": "Not Found"}`), nil
+			}
+
+			nodes := []string{}
+			for _, id := range body.Variables.IDs {
+				edges := []string{}
+				for _, metafieldID := range metafields[id] {
+					edges = append(edges, fmt.Sprintf(`{"node": {"id": "gid://shopify/Metafield/%d"}}`, metafieldID))
+				}
+				nodes = append(nodes, fmt.Sprintf(`{"id": %q, "metafields": {"pageInfo": {"hasNextPage": false}, "edges": [%s]}}`, id, strings.Join(edges, ",")))
+			}
+			return httpmock.NewStringResponse(200, `{"data": {"nodes": [`+strings.Join(nodes, ",")+`]}}`), nil
+		})
+}
+
+func TestExportCatalogMissingScope(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/graphql.json",
+		httpmock.NewStringResponder(403, `{"errors": "This action requires merchant approval for read_products scope."}`))
+
+	err := client.Export.Catalog(func(record ExportRecord) error {
+		t.Errorf("Export.Catalog returned %v without the scope to read it", exportRecordName(record))
+		return nil
+	})
+	if e, ok := err.(ResponseError); !ok || e.Status != 403 {
+		t.Errorf("Export.Catalog returned %v, expected the 403 ResponseError", err)
+	}
+}
+
+func TestExportCatalogREST(t *testing.T) {
+	setup()
+	defer teardown()
+
+	registerExportGraphQL(map[string][]int{
+		"gid://shopify/Product/1":     {21},
+		"gid://shopify/Collection/32": {22},
+	})
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products.json?limit=250",
+		httpmock.NewStringResponder(200, `{"products": [{"id": 1, "variants": [{"id": 11, "product_id": 1, "inventory_item_id": 41}]}]}`))
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/inventory_levels.json?inventory_item_ids=41&limit=250&page=1",
+		httpmock.NewStringResponder(200, `{"inventory_levels": [{"inventory_item_id": 41, "location_id": 51, "available": 3}]}`))
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/custom_collections.json?limit=250",
+		httpmock.NewStringResponder(200, `{"custom_collections": [{"id": 31}]}`))
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/smart_collections.json?limit=250",
+		httpmock.NewStringResponder(200, `{"smart_collections": [{"id": 32}]}`))
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products.json?collection_id=32&fields=id&limit=250",
+		httpmock.NewStringResponder(200, `{"products": [{"id": 1}]}`))
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/collects.json?limit=250",
+		httpmock.NewStringResponder(200, `{"collects": [{"id": 61, "collection_id": 31, "product_id": 1}]}`))
+
+	names := []string{}
+	err := client.Export.Catalog(func(record ExportRecord) error {
+		names = append(names, exportRecordName(record))
+		if record.Product != nil && record.Product.Variants != nil {
+			t.Errorf("Export.Catalog returned product with variants %v, expected none", record.Product.Variants)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Export.Catalog returned error: %v", err)
+	}
+
+	expected := []string{
+		"product 1", "variant 11", "inventory level of 41 at 51", "metafield 21",
+		"custom collection 31", "smart collection 32", "collect 0 of product 1 in 32", "metafield 22",
+		"collect 61 of product 1 in 31",
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Export.Catalog returned %v, expected %v", names, expected)
+	}
+}
+
+func TestExportCatalogFromBulk(t *testing.T) {
+	setup()
+	defer teardown()
+
+	queries := []string{}
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/graphql.json",
+		func(req *http.Request) (*http.Response, error) {
+			body := struct {
+				Query     string            `json:"query"`
+				Variables map[string]string `json:"variables"`
+			}{}
+			json.NewDecoder(req.Body).Decode(&body)
+
+			if strings.Contains(body.Query, "bulkOperationRunQuery") {
+				queries = append(queries, body.Variables["query"])
+				return httpmock.NewStringResponse(200, `{"data": {"bulkOperationRunQuery": {
+					"bulkOperation": {"id": "gid://shopify/BulkOperation/1", "status": "CREATED"}, "userErrors": []
+				}}}`), nil
+			}
+			return httpmock.NewStringResponse(200, `{"data": {"currentBulkOperation": {
+				"id": "gid://shopify/BulkOperation/1", "status": "COMPLETED", "url": "https://storage.googleapis.com/shopify/collections.jsonl"
+			}}}`), nil
+		})
+	httpmock.RegisterResponder("GET", "https://storage.googleapis.com/shopify/collections.jsonl",
+		httpmock.NewBytesResponder(200, loadFixture("export_collections.jsonl")))
+
+	positions := []PageInfo{}
+	after := PageInfo{HasNextPage: true, Resource: "collections", SinceID: 30}
+	err := client.Export.CatalogFrom(after, func(record ExportRecord, position PageInfo) error {
+		positions = append(positions, position)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Export.CatalogFrom returned error: %v", err)
+	}
+
+	if len(queries) != 1 || !strings.Contains(queries[0], `collections(query: "id:>30")`) {
+		t.Errorf("Export.CatalogFrom ran bulk queries %q, expected only the collections after 30", queries)
+	}
+	expected := []PageInfo{
+		{HasNextPage: true, Resource: "collections", SinceID: 30},
+		{HasNextPage: true, Resource: "collections", SinceID: 30},
+		{HasNextPage: true, Resource: "collections", SinceID: 31},
+	}
+	if !reflect.DeepEqual(positions, expected) {
+		t.Errorf("Export.CatalogFrom passed positions %v, expected %v", positions, expected)
+	}
+}
+
+func TestExportCatalogFromREST(t *testing.T) {
+	setup()
+	defer teardown()
+
+	registerExportGraphQL(nil)
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products.json?limit=250&since_id=1",
+		httpmock.NewStringResponder(200, `{"products": [{"id": 2, "variants": [{"id": 12, "product_id": 2}]}, {"id": 3}]}`))
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/custom_collections.json?limit=250",
+		httpmock.NewStringResponder(200, `{"custom_collections": []}`))
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/smart_collections.json?limit=250",
+		httpmock.NewStringResponder(200, `{"smart_collections": [{"id": 32}]}`))
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products.json?collection_id=32&fields=id&limit=250",
+		httpmock.NewStringResponder(200, `{"products": []}`))
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/collects.json?limit=250",
+		httpmock.NewStringResponder(200, `{"collects": []}`))
+
+	names := []string{}
+	positions := []PageInfo{}
+	after := PageInfo{HasNextPage: true, Resource: "products", SinceID: 1}
+	err := client.Export.CatalogFrom(after, func(record ExportRecord, position PageInfo) error {
+		names = append(names, exportRecordName(record))
+		positions = append(positions, position)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Export.CatalogFrom returned error: %v", err)
+	}
+
+	expectedNames := []string{"product 2", "variant 12", "product 3", "smart collection 32"}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("Export.CatalogFrom returned %v, expected %v", names, expectedNames)
+	}
+	expectedPositions := []PageInfo{
+		{HasNextPage: true, Resource: "products", SinceID: 1},
+		{HasNextPage: true, Resource: "products", SinceID: 1},
+		{HasNextPage: true, Resource: "products", SinceID: 2},
+		{HasNextPage: true, Resource: "smart_collections"},
+	}
+	if !reflect.DeepEqual(positions, expectedPositions) {
+		t.Errorf("Export.CatalogFrom passed positions %v, expected %v", positions, expectedPositions)
+	}
+}
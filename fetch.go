@@ -0,0 +1,197 @@
+package goshopify
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultFetchConcurrency is the number of pages FetchAll requests at once
+// when no concurrency is given.
+const DefaultFetchConcurrency = 4
+
+// Times a rate limited page is requested again before FetchAll gives up
+const fetchRateLimitRetries = 10
+
+// Time to wait before requesting a rate limited page again when Shopify does
+// not say
+var fetchRetryDelay = time.Second
+
+// A page of a fetch: the records with ids after after, up to and including
+// upTo, and where its result goes.
+type fetchJob struct {
+	after   int
+	upTo    uint64
+	results chan fetchResult
+}
+
+type fetchResult struct {
+	items []syncItem
+	err   error
+}
+
+// FetchFunc is called by FetchAllFrom for every record, with the position
+// after it to continue from.
+type FetchFunc func(resource interface{}, next PageInfo) error
+
+// FetchAll lists every record of the named resource, e.g. "products",
+// "customers" or "orders", and calls fn for each of them. Up to concurrency
+// pages are requested at once, and records are passed to fn in order of id,
+// one at a time, starting after the SinceID of the options.
+//
+// The ids of the records are listed first and split into ranges of a page
+// each, which are then requested at once. A record deleted during the fetch
+// therefore cannot shift the others into another page, and records created
+// during the fetch are left out.
+//
+// Rate limited requests are retried a few times after the time Shopify asks
+// for, within the retry budget of the client. Fetching stops at the first
+// error returned by fn or by a request, and FetchAll only returns once all of
+// its requests have stopped.
+func FetchAll(client *Client, resource string, options ListOptions, concurrency int, fn SyncFunc) error {
+	after := PageInfo{HasNextPage: true, SinceID: uint64(options.SinceID)}
+	return FetchAllFrom(client, resource, after, options, concurrency, func(resource interface{}, next PageInfo) error {
+		return fn(next.SinceID, resource)
+	})
+}
+
+// FetchAllFrom is FetchAll continuing after a saved position, such as one
+// passed to fn by an earlier fetch that was stopped. The position passed to
+// fn after the last record has no next page.
+func FetchAllFrom(client *Client, resource string, after PageInfo, options ListOptions, concurrency int, fn FetchFunc) error {
+	kind, ok := syncResources[resource]
+	if !ok {
+		return fmt.Errorf("fetching %s is not supported", resource)
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultFetchConcurrency
+	}
+	options = after.ListOptions(options)
+	options.Limit = syncPageLimit
+
+	done := make(chan struct{})
+	wg := sync.WaitGroup{}
+	defer wg.Wait()
+	defer close(done)
+
+	// Pages are handed out in order and only a few ahead of the page being
+	// passed to fn, which bounds the number of pages held in memory.
+	ordered := make(chan chan fetchResult, 2*concurrency)
+	jobs := make(chan fetchJob)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(ordered)
+		defer close(jobs)
+		scanFetchRanges(client, kind, options, done, ordered, jobs)
+	}()
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				pageOptions := options
+				pageOptions.SinceID = job.after
+				items, err := fetchPage(client, kind.list, pageOptions, done)
+
+				// Drop records past the range, which are there when records
+				// of the range were deleted since the ids were listed
+				inRange := items[:0]
+				for _, item := range items {
+					if item.id <= job.upTo {
+						inRange = append(inRange, item)
+					}
+				}
+				job.results <- fetchResult{inRange, err}
+			}
+		}()
+	}
+
+	results, ok := <-ordered
+	for ok {
+		result := <-results
+		if result.err != nil {
+			return result.err
+		}
+
+		// Whether another page follows is known once the ids after the
+		// page have been listed
+		var more bool
+		results, more = <-ordered
+		for i, item := range result.items {
+			next := PageInfo{HasNextPage: more || i < len(result.items)-1, SinceID: item.id}
+			err := fn(item.resource, next)
+			if err != nil {
+				return err
+			}
+		}
+		ok = more
+	}
+	return nil
+}
+
+// List the ids of the records to fetch, a page at a time, and hand out a job
+// for each page. A failed listing is passed on as the result of a page.
+func scanFetchRanges(client *Client, kind syncResource, options ListOptions, done chan struct{}, ordered chan chan fetchResult, jobs chan fetchJob) {
+	idOptions := options
+	idOptions.Fields = "id"
+
+	for {
+		items, err := fetchPage(client, kind.list, idOptions, done)
+		if err != nil {
+			results := make(chan fetchResult, 1)
+			results <- fetchResult{err: err}
+			select {
+			case ordered <- results:
+			case <-done:
+			}
+			return
+		}
+		if len(items) == 0 {
+			return
+		}
+
+		// Each page gets a buffered channel so workers never wait on a slow fn
+		job := fetchJob{after: idOptions.SinceID, upTo: items[len(items)-1].id, results: make(chan fetchResult, 1)}
+		select {
+		case ordered <- job.results:
+		case <-done:
+			return
+		}
+		select {
+		case jobs <- job:
+		case <-done:
+			return
+		}
+
+		if len(items) < syncPageLimit {
+			return
+		}
+		idOptions.SinceID = int(job.upTo)
+	}
+}
+
+// Request a page, waiting and trying again a few times while the request is
+// rate limited.
+func fetchPage(c *Client, list func(*Client, ListOptions) ([]syncItem, error), options ListOptions, done chan struct{}) ([]syncItem, error) {
+	c.budgetRequest()
+	for retries := 0; ; retries++ {
+		items, err := list(c, options)
+		rateLimitErr, ok := err.(RateLimitError)
+		if !ok || retries >= fetchRateLimitRetries || !c.budgetRetry() {
+			return items, err
+		}
+
+		wait := time.Duration(rateLimitErr.RetryAfter) * time.Second
+		if wait <= 0 {
+			wait = fetchRetryDelay
+		}
+		select {
+		case <-time.After(wait):
+		case <-done:
+			return nil, err
+		}
+	}
+}
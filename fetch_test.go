@@ -0,0 +1,223 @@
+package goshopify
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+)
+
+// Products listed by since_id, which can be deleted while they are listed
+type fakeProducts struct {
+	mu       sync.Mutex
+	ids      []uint64
+	requests int32
+
+	// Called before each request is answered
+	onRequest func(sinceID uint64, fields string)
+}
+
+func (p *fakeProducts) delete(from, to int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ids = append(p.ids[:from:from], p.ids[to:]...)
+}
+
+// Register products with the ids from 1 to n.
+func registerProducts(n int) *fakeProducts {
+	products := &fakeProducts{}
+	for i := 1; i <= n; i++ {
+		products.ids = append(products.ids, uint64(i))
+	}
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products.json",
+		func(req *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&products.requests, 1)
+			sinceID, _ := strconv.ParseUint(req.URL.Query().Get("since_id"), 10, 64)
+			limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+			if products.onRequest != nil {
+				products.onRequest(sinceID, req.URL.Query().Get("fields"))
+			}
+
+			products.mu.Lock()
+			defer products.mu.Unlock()
+			list := []string{}
+			for _, id := range products.ids {
+				if id > sinceID && len(list) < limit {
+					list = append(list, fmt.Sprintf(`{"id": %d}`, id))
+				}
+			}
+			return httpmock.NewStringResponse(200, `{"products": [`+strings.Join(list, ",")+`]}`), nil
+		})
+	return products
+}
+
+func TestFetchAll(t *testing.T) {
+	setup()
+	defer teardown()
+	registerProducts(501)
+
+	ids := []uint64{}
+	err := FetchAll(client, "products", ListOptions{}, 2, func(id uint64, resource interface{}) error {
+		if resource.(*Product).ID != id {
+			t.Errorf("FetchAll passed product %d with id %d", resource.(*Product).ID, id)
+		}
+		ids = append(ids, id)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("FetchAll returned error: %v", err)
+	}
+
+	if len(ids) != 501 {
+		t.Fatalf("FetchAll passed %d products, expected 501", len(ids))
+	}
+	for i, id := range ids {
+		if id != uint64(i+1) {
+			t.Fatalf("FetchAll passed product %d at position %d, expected %d", id, i, i+1)
+		}
+	}
+}
+
+func TestFetchAllStops(t *testing.T) {
+	setup()
+	defer teardown()
+	products := registerProducts(2000)
+
+	stop := errors.New("stop")
+	calls := 0
+	err := FetchAll(client, "products", ListOptions{}, 0, func(id uint64, resource interface{}) error {
+		calls++
+		if id == 300 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("FetchAll returned %v, expected %v", err, stop)
+	}
+	if calls != 300 {
+		t.Errorf("FetchAll called fn %d times, expected 300", calls)
+	}
+
+	// No requests are made once FetchAll returned
+	made := atomic.LoadInt32(&products.requests)
+	time.Sleep(10 * time.Millisecond)
+	if after := atomic.LoadInt32(&products.requests); after != made {
+		t.Errorf("FetchAll made %d requests after it returned", after-made)
+	}
+}
+
+func TestFetchAllDeleted(t *testing.T) {
+	setup()
+	defer teardown()
+	products := registerProducts(600)
+
+	// Delete products of the second page after their ids were listed
+	products.onRequest = func(sinceID uint64, fields string) {
+		if sinceID == 250 && fields != "id" {
+			products.delete(250, 260)
+		}
+	}
+
+	ids := []uint64{}
+	err := FetchAll(client, "products", ListOptions{}, 2, func(id uint64, resource interface{}) error {
+		ids = append(ids, id)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("FetchAll returned error: %v", err)
+	}
+
+	seen := map[uint64]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			t.Fatalf("FetchAll passed product %d twice", id)
+		}
+		seen[id] = true
+	}
+	if len(ids) != 590 || !seen[250] || !seen[261] || !seen[501] || !seen[600] {
+		t.Errorf("FetchAll passed %d products, expected the 590 that were not deleted", len(ids))
+	}
+}
+
+func TestFetchAllSinceID(t *testing.T) {
+	setup()
+	defer teardown()
+	registerProducts(300)
+
+	ids := []uint64{}
+	err := FetchAll(client, "products", ListOptions{SinceID: 280}, 2, func(id uint64, resource interface{}) error {
+		ids = append(ids, id)
+		return nil
+	})
+	if err != nil || len(ids) != 20 || ids[0] != 281 {
+		t.Errorf("FetchAll returned %v after passing %v, expected products 281 to 300", err, ids)
+	}
+}
+
+func TestFetchAllFrom(t *testing.T) {
+	setup()
+	defer teardown()
+	registerProducts(500)
+
+	positions := []PageInfo{}
+	after := PageInfo{HasNextPage: true, SinceID: 200}
+	err := FetchAllFrom(client, "products", after, ListOptions{}, 2, func(resource interface{}, next PageInfo) error {
+		if next.SinceID != resource.(*Product).ID {
+			t.Errorf("FetchAllFrom passed product %d with position %v", resource.(*Product).ID, next)
+		}
+		positions = append(positions, next)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("FetchAllFrom returned error: %v", err)
+	}
+
+	if len(positions) != 300 || positions[0].SinceID != 201 {
+		t.Fatalf("FetchAllFrom passed %d products, expected products 201 to 500", len(positions))
+	}
+	for i, position := range positions {
+		if position.HasNextPage != (i < len(positions)-1) {
+			t.Errorf("FetchAllFrom passed position %v at %d, expected a next page on all but the last", position, i)
+		}
+	}
+}
+
+func TestFetchAllRateLimited(t *testing.T) {
+	setup()
+	defer teardown()
+	fetchRetryDelay = time.Millisecond
+	defer func() { fetchRetryDelay = time.Second }()
+
+	requests := 0
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products.json",
+		func(req *http.Request) (*http.Response, error) {
+			requests++
+			return httpmock.NewStringResponse(429, `{"errors": "Exceeded 2 calls per second for api client. Slow down!"}`), nil
+		})
+
+	err := FetchAll(client, "products", ListOptions{}, 1, func(id uint64, resource interface{}) error {
+		return nil
+	})
+	if _, ok := err.(RateLimitError); !ok {
+		t.Errorf("FetchAll returned %v, expected a RateLimitError", err)
+	}
+	if requests != fetchRateLimitRetries+1 {
+		t.Errorf("FetchAll made %d requests, expected %d", requests, fetchRateLimitRetries+1)
+	}
+}
+
+func TestFetchAllUnsupported(t *testing.T) {
+	err := FetchAll(client, "themes", ListOptions{}, 1, nil)
+	if err == nil {
+		t.Errorf("FetchAll returned no error for an unsupported resource")
+	}
+}
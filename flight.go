@@ -0,0 +1,89 @@
+package goshopify
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// flightGroup coalesces identical requests that are in flight at the same
+// time into one.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// A request in flight and, once it is done, its response
+type flightCall struct {
+	done chan struct{}
+
+	resp *http.Response
+	body []byte
+	err  error
+
+	// Whether the request failed because the context of the caller that
+	// made it was done, which says nothing about the other callers
+	canceled bool
+}
+
+// Run fn, unless a call with the same key is in flight, in which case its
+// response is shared instead. Every caller gets its own copy of the response
+// to read and close. A caller only waits for as long as its context allows,
+// and makes the request itself if the shared one failed because of the
+// context of the caller that made it.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() (*http.Response, error)) (*http.Response, error) {
+	for {
+		g.mu.Lock()
+		if g.calls == nil {
+			g.calls = make(map[string]*flightCall)
+		}
+		call, ok := g.calls[key]
+		if !ok {
+			break
+		}
+		g.mu.Unlock()
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if !call.canceled {
+			return call.response()
+		}
+	}
+
+	call := &flightCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	call.resp, call.err = fn()
+	if call.err == nil {
+		call.body, call.err = ioutil.ReadAll(call.resp.Body)
+		call.resp.Body.Close()
+	}
+	call.canceled = call.err != nil && ctx.Err() != nil
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(call.done)
+
+	return call.response()
+}
+
+func (call *flightCall) response() (*http.Response, error) {
+	if call.err != nil {
+		return nil, call.err
+	}
+
+	resp := *call.resp
+	resp.Header = make(http.Header, len(call.resp.Header))
+	for k, v := range call.resp.Header {
+		resp.Header[k] = v
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(call.body))
+	return &resp, nil
+}
@@ -0,0 +1,135 @@
+package goshopify
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestRequestCoalescing(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testClient := NewClient(app, "fooshop", "abcd", WithRequestCoalescing())
+	httpmock.ActivateNonDefault(testClient.Client)
+
+	requests := 0
+	release := make(chan struct{})
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/shop.json",
+		func(req *http.Request) (*http.Response, error) {
+			requests++
+			<-release
+			return httpmock.NewBytesResponse(200, loadFixture("shop.json")), nil
+		})
+
+	var wg, started sync.WaitGroup
+	shops := make([]*Shop, 5)
+	for i := range shops {
+		wg.Add(1)
+		started.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			started.Done()
+			shop, err := testClient.Shop.Get(nil)
+			if err != nil {
+				t.Errorf("Shop.Get returned error: %v", err)
+			}
+			shops[i] = shop
+		}(i)
+	}
+
+	// Give every caller time to join the request before it completes
+	started.Wait()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if requests != 1 {
+		t.Errorf("Coalesced Shop.Get made %d requests, expected 1", requests)
+	}
+	for i, shop := range shops {
+		if shop == nil || shop.ID != 690933842 {
+			t.Errorf("Shop.Get %d returned %+v, expected shop 690933842", i, shop)
+		}
+	}
+	if shops[0] == shops[1] {
+		t.Errorf("Coalesced Shop.Get returned the same shop to two callers")
+	}
+
+	// A later request is sent again
+	_, err := testClient.Shop.Get(nil)
+	if err != nil {
+		t.Errorf("Shop.Get returned error: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Shop.Get made %d requests in total, expected 2", requests)
+	}
+}
+
+func TestFlightGroupLeaderCanceled(t *testing.T) {
+	group := &flightGroup{}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	leading := make(chan struct{})
+	leaderErr := make(chan error)
+	go func() {
+		_, err := group.do(ctx, "key", func() (*http.Response, error) {
+			close(leading)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		leaderErr <- err
+	}()
+	<-leading
+
+	followerResp := make(chan *http.Response)
+	go func() {
+		resp, err := group.do(context.Background(), "key", func() (*http.Response, error) {
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader("ok"))}, nil
+		})
+		if err != nil {
+			t.Errorf("flightGroup.do returned the error of another caller: %v", err)
+		}
+		followerResp <- resp
+	}()
+
+	// The leader gives up while the follower waits on it
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-leaderErr; err != context.Canceled {
+		t.Errorf("flightGroup.do returned %v for the canceled caller, expected context.Canceled", err)
+	}
+	if resp := <-followerResp; resp == nil || resp.StatusCode != 200 {
+		t.Errorf("flightGroup.do returned %v for the waiting caller, expected its own response", resp)
+	}
+}
+
+func TestFlightGroupFollowerCanceled(t *testing.T) {
+	group := &flightGroup{}
+	release := make(chan struct{})
+	defer close(release)
+
+	leading := make(chan struct{})
+	go group.do(context.Background(), "key", func() (*http.Response, error) {
+		close(leading)
+		<-release
+		return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader("ok"))}, nil
+	})
+	<-leading
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := group.do(ctx, "key", func() (*http.Response, error) {
+		return nil, errors.New("made a request while another was in flight")
+	})
+	if err != context.DeadlineExceeded {
+		t.Errorf("flightGroup.do returned %v, expected it to stop waiting at its deadline", err)
+	}
+}
@@ -0,0 +1,257 @@
+package goshopify
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Default thresholds of a FraudScreener
+const (
+	DefaultFraudInvestigateScore = 0.5
+	DefaultFraudCancelScore      = 0.9
+)
+
+// FraudFinding is a reason found by a FraudRule to suspect an order. Score is
+// between 0 and 1.
+type FraudFinding struct {
+	Score   float64
+	Message string
+}
+
+// FraudRule evaluates an order and its transactions. A rule returns nil when
+// it finds nothing suspicious about the order.
+type FraudRule interface {
+	Evaluate(c *Client, order *Order, transactions []Transaction) (*FraudFinding, error)
+}
+
+// FraudRuleFunc is an adapter to use an ordinary function as a FraudRule.
+type FraudRuleFunc func(c *Client, order *Order, transactions []Transaction) (*FraudFinding, error)
+
+// Evaluate calls f(c, order, transactions)
+func (f FraudRuleFunc) Evaluate(c *Client, order *Order, transactions []Transaction) (*FraudFinding, error) {
+	return f(c, order, transactions)
+}
+
+// FraudScreener evaluates its rules against orders and records the outcome as
+// an order risk. The scores of all findings are added up, to at most 1, and
+// compared to the thresholds to pick a recommendation.
+type FraudScreener struct {
+	Rules []FraudRule
+
+	// Source of the order risks created, "External" if empty
+	Source string
+
+	// Scores from which an order is recommended for investigation or
+	// cancellation
+	InvestigateScore float64
+	CancelScore      float64
+
+	// Cancel orders that are recommended for cancellation
+	CancelHighRisk bool
+
+	client *Client
+}
+
+// NewFraudScreener returns a FraudScreener with the given rules and the
+// default thresholds.
+func NewFraudScreener(client *Client, rules ...FraudRule) *FraudScreener {
+	return &FraudScreener{
+		Rules:            rules,
+		InvestigateScore: DefaultFraudInvestigateScore,
+		CancelScore:      DefaultFraudCancelScore,
+		client:           client,
+	}
+}
+
+// Screen evaluates the rules against an order, creates an order risk with
+// the combined score and returns it. When CancelHighRisk is set and the
+// order is recommended for cancellation, the order is also cancelled with
+// the reason "fraud".
+func (s *FraudScreener) Screen(order *Order) (*OrderRisk, error) {
+	transactions, err := s.client.Transaction.List(int(order.ID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	score := 0.0
+	messages := []string{}
+	for _, rule := range s.Rules {
+		finding, err := rule.Evaluate(s.client, order, transactions)
+		if err != nil {
+			return nil, err
+		}
+		if finding != nil {
+			score += finding.Score
+			messages = append(messages, finding.Message)
+		}
+	}
+	if score > 1 {
+		score = 1
+	}
+
+	recommendation := "accept"
+	if score >= s.CancelScore {
+		recommendation = "cancel"
+	} else if score >= s.InvestigateScore {
+		recommendation = "investigate"
+	}
+
+	source := s.Source
+	if source == "" {
+		source = "External"
+	}
+
+	message := strings.Join(messages, "; ")
+	if message == "" {
+		message = "No fraud indicators found"
+	}
+
+	riskScore := decimal.NewFromFloat(score)
+	cancel := s.CancelHighRisk && recommendation == "cancel"
+	risk, err := s.client.OrderRisk.Create(order.ID, OrderRisk{
+		Source:         source,
+		Score:          &riskScore,
+		Recommendation: recommendation,
+		Display:        true,
+		CauseCancel:    cancel,
+		Message:        message,
+	})
+	if err != nil || !cancel {
+		return risk, err
+	}
+
+	_, err = s.client.Order.Cancel(order.ID, OrderCancelOptions{Reason: "fraud"})
+	return risk, err
+}
+
+// DefaultAVSMismatchCodes are the AVS result codes for which neither the
+// street address nor the postal code matched. Other codes either matched
+// partially or mean that the check could not be made, e.g. U (unavailable),
+// G (not supported by the issuer), R (retry) or E (error), which is common for
+// cards issued outside the US.
+var DefaultAVSMismatchCodes = []string{"N"}
+
+// AVSRule finds orders paid with a card whose billing address did not pass
+// the address verification check, i.e. whose AVS result code is one of the
+// given codes, or one of DefaultAVSMismatchCodes if none are given. Pass e.g.
+// "N", "A" and "Z" to also flag partial matches.
+func AVSRule(score float64, mismatchCodes ...string) FraudRule {
+	if len(mismatchCodes) == 0 {
+		mismatchCodes = DefaultAVSMismatchCodes
+	}
+	mismatch := map[string]bool{}
+	for _, code := range mismatchCodes {
+		mismatch[code] = true
+	}
+
+	return FraudRuleFunc(func(c *Client, order *Order, transactions []Transaction) (*FraudFinding, error) {
+		for _, transaction := range transactions {
+			details := transaction.PaymentDetails
+			if details == nil || !mismatch[details.AVSResultCode] {
+				continue
+			}
+			return &FraudFinding{score, "Billing address did not match (AVS " + details.AVSResultCode + ")"}, nil
+		}
+		return nil, nil
+	})
+}
+
+// CVVRule finds orders paid with a card whose security code did not match,
+// i.e. whose CVV result code is N. Codes for which the check was not made,
+// e.g. P (not processed), S (not present) or U (issuer not certified), are
+// not flagged.
+func CVVRule(score float64) FraudRule {
+	return FraudRuleFunc(func(c *Client, order *Order, transactions []Transaction) (*FraudFinding, error) {
+		for _, transaction := range transactions {
+			details := transaction.PaymentDetails
+			if details == nil || details.CVVResultCode != "N" {
+				continue
+			}
+			return &FraudFinding{score, "Card security code did not match (CVV " + details.CVVResultCode + ")"}, nil
+		}
+		return nil, nil
+	})
+}
+
+// AddressMismatchRule finds orders shipped to a different country or postal
+// code than their billing address.
+func AddressMismatchRule(score float64) FraudRule {
+	return FraudRuleFunc(func(c *Client, order *Order, transactions []Transaction) (*FraudFinding, error) {
+		billing, shipping := order.BillingAddress, order.ShippingAddress
+		if billing == nil || shipping == nil {
+			return nil, nil
+		}
+
+		if !strings.EqualFold(billing.CountryCode, shipping.CountryCode) {
+			return &FraudFinding{score, "Shipping country differs from billing country"}, nil
+		}
+		if normalizeZip(billing.Zip) != normalizeZip(shipping.Zip) {
+			return &FraudFinding{score, "Shipping postal code differs from billing postal code"}, nil
+		}
+		return nil, nil
+	})
+}
+
+func normalizeZip(zip string) string {
+	return strings.ToUpper(strings.Replace(zip, " ", "", -1))
+}
+
+// The order fields that VelocityRule compares
+const velocityFields = "id,email,client_details,browser_ip"
+
+var errVelocityExceeded = errors.New("too many orders")
+
+// VelocityRule finds orders whose email address or browser IP placed more
+// than max orders, including this one, in the window before the order was
+// created.
+func VelocityRule(max int, window time.Duration, score float64) FraudRule {
+	return FraudRuleFunc(func(c *Client, order *Order, transactions []Transaction) (*FraudFinding, error) {
+		end := time.Now()
+		if order.CreatedAt != nil {
+			end = *order.CreatedAt
+		}
+
+		ip := browserIP(order)
+
+		// Only list what is compared, and stop once there are too many
+		count := 0
+		options := ListOptions{
+			CreatedAtMin: end.Add(-window),
+			CreatedAtMax: end,
+			Fields:       velocityFields,
+		}
+		err := listAll(c, syncResources[ordersResourceName].list, options, func(item syncItem, _ PageInfo) error {
+			other := item.resource.(*Order)
+			sameEmail := order.Email != "" && strings.EqualFold(other.Email, order.Email)
+			sameIP := ip != "" && browserIP(other) == ip
+			if other.ID == order.ID || sameEmail || sameIP {
+				count++
+			}
+			if count > max {
+				return errVelocityExceeded
+			}
+			return nil
+		})
+		if err != nil && err != errVelocityExceeded {
+			return nil, err
+		}
+
+		if count > max {
+			return &FraudFinding{score, "Too many orders from the same customer in a short time"}, nil
+		}
+		return nil, nil
+	})
+}
+
+func browserIP(order *Order) string {
+	if order.BrowserIp != "" {
+		return order.BrowserIp
+	}
+	if order.ClientDetails != nil {
+		return order.ClientDetails.BrowserIp
+	}
+	return ""
+}
@@ -0,0 +1,174 @@
+package goshopify
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestFraudScreenerScreen(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/orders/1/transactions.json",
+		httpmock.NewStringResponder(200, `{"transactions": [
+			{"id": 1, "kind": "authorization", "payment_details": {"avs_result_code": "N", "cvv_result_code": "N"}}
+		]}`))
+
+	risk := OrderRisk{}
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/orders/1/risks.json",
+		func(req *http.Request) (*http.Response, error) {
+			resource := OrderRiskResource{Risk: &risk}
+			json.NewDecoder(req.Body).Decode(&resource)
+			return httpmock.NewStringResponse(201, `{"risk": {"id": 2}}`), nil
+		})
+
+	cancelled := false
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/orders/1/cancel.json",
+		func(req *http.Request) (*http.Response, error) {
+			cancelled = true
+			return httpmock.NewStringResponse(200, `{"order": {"id": 1}}`), nil
+		})
+
+	screener := NewFraudScreener(client, AVSRule(0.4), CVVRule(0.4), AddressMismatchRule(0.3))
+	screener.CancelHighRisk = true
+
+	order := &Order{
+		ID:              1,
+		BillingAddress:  &Address{CountryCode: "US", Zip: "10001"},
+		ShippingAddress: &Address{CountryCode: "US", Zip: "10001"},
+	}
+	created, err := screener.Screen(order)
+	if err != nil {
+		t.Fatalf("FraudScreener.Screen returned error: %v", err)
+	}
+	if created.ID != 2 {
+		t.Errorf("FraudScreener.Screen returned risk %d, expected 2", created.ID)
+	}
+
+	if risk.Recommendation != "investigate" || risk.Score.String() != "0.8" {
+		t.Errorf("FraudScreener.Screen sent %v with score %v, expected investigate with 0.8", risk.Recommendation, risk.Score)
+	}
+	if risk.Source != "External" || !risk.Display || risk.CauseCancel {
+		t.Errorf("FraudScreener.Screen sent %+v, expected a displayed External risk", risk)
+	}
+	if cancelled {
+		t.Errorf("FraudScreener.Screen cancelled an order to investigate")
+	}
+
+	order.ShippingAddress.CountryCode = "CA"
+	_, err = screener.Screen(order)
+	if err != nil {
+		t.Fatalf("FraudScreener.Screen returned error: %v", err)
+	}
+	if risk.Recommendation != "cancel" || risk.Score.String() != "1" || !risk.CauseCancel {
+		t.Errorf("FraudScreener.Screen sent %v with score %v, expected cancel with 1", risk.Recommendation, risk.Score)
+	}
+	if !cancelled {
+		t.Errorf("FraudScreener.Screen did not cancel the order")
+	}
+}
+
+func TestCardVerificationRules(t *testing.T) {
+	cases := []struct {
+		rule     FraudRule
+		avs, cvv string
+		flagged  bool
+	}{
+		{AVSRule(0.4), "N", "", true},
+		{AVSRule(0.4), "Y", "", false},
+		// Partial matches are only flagged when asked for
+		{AVSRule(0.4), "A", "", false},
+		{AVSRule(0.4, "N", "A", "Z"), "A", "", true},
+		{AVSRule(0.4, "N", "A", "Z"), "Z", "", true},
+		// The check could not be made
+		{AVSRule(0.4), "U", "", false},
+		{AVSRule(0.4), "G", "", false},
+		{AVSRule(0.4), "S", "", false},
+		{AVSRule(0.4), "R", "", false},
+		{AVSRule(0.4), "E", "", false},
+		{AVSRule(0.4), "", "", false},
+		{CVVRule(0.4), "", "N", true},
+		{CVVRule(0.4), "", "M", false},
+		{CVVRule(0.4), "", "P", false},
+		{CVVRule(0.4), "", "S", false},
+		{CVVRule(0.4), "", "U", false},
+		{CVVRule(0.4), "", "", false},
+	}
+
+	for _, c := range cases {
+		transactions := []Transaction{{PaymentDetails: &PaymentDetails{AVSResultCode: c.avs, CVVResultCode: c.cvv}}}
+		finding, err := c.rule.Evaluate(client, &Order{ID: 1}, transactions)
+		if err != nil {
+			t.Fatalf("Rule returned error: %v", err)
+		}
+		if (finding != nil) != c.flagged {
+			t.Errorf("Rule returned %+v for AVS %q and CVV %q, expected flagged %v", finding, c.avs, c.cvv, c.flagged)
+		}
+	}
+}
+
+func TestVelocityRule(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/orders.json?created_at_max=2017-01-01T12%3A00%3A00Z&created_at_min=2017-01-01T11%3A00%3A00Z&fields=id%2Cemail%2Cclient_details%2Cbrowser_ip&limit=250&status=any",
+		httpmock.NewStringResponder(200, `{"orders": [
+			{"id": 1, "email": "bob@example.com"},
+			{"id": 2, "email": "BOB@example.com"},
+			{"id": 3, "email": "alice@example.com", "client_details": {"browser_ip": "10.0.0.1"}},
+			{"id": 4, "email": "carol@example.com"}
+		]}`))
+
+	createdAt := time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC)
+	order := &Order{
+		ID:            1,
+		Email:         "bob@example.com",
+		CreatedAt:     &createdAt,
+		ClientDetails: &ClientDetails{BrowserIp: "10.0.0.1"},
+	}
+
+	finding, err := VelocityRule(3, time.Hour, 0.5).Evaluate(client, order, nil)
+	if err != nil {
+		t.Fatalf("VelocityRule returned error: %v", err)
+	}
+	if finding != nil {
+		t.Errorf("VelocityRule returned %+v for 3 orders, expected nil", finding)
+	}
+
+	finding, err = VelocityRule(2, time.Hour, 0.5).Evaluate(client, order, nil)
+	if err != nil {
+		t.Fatalf("VelocityRule returned error: %v", err)
+	}
+	if finding == nil || finding.Score != 0.5 {
+		t.Errorf("VelocityRule returned %+v for 3 orders, expected a finding with score 0.5", finding)
+	}
+}
+
+func TestVelocityRuleStopsPaging(t *testing.T) {
+	setup()
+	defer teardown()
+
+	orders := make([]Order, syncPageLimit)
+	for i := range orders {
+		orders[i] = Order{ID: uint64(i + 1), Email: "bob@example.com"}
+	}
+	body, _ := json.Marshal(OrdersResource{Orders: orders})
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/orders.json?created_at_max=2017-01-01T12%3A00%3A00Z&created_at_min=2017-01-01T11%3A00%3A00Z&fields=id%2Cemail%2Cclient_details%2Cbrowser_ip&limit=250&status=any",
+		httpmock.NewBytesResponder(200, body))
+
+	createdAt := time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC)
+	order := &Order{ID: 1, Email: "bob@example.com", CreatedAt: &createdAt}
+
+	// The next page is not registered, so listing it fails
+	finding, err := VelocityRule(2, time.Hour, 0.5).Evaluate(client, order, nil)
+	if err != nil {
+		t.Fatalf("VelocityRule returned error: %v", err)
+	}
+	if finding == nil {
+		t.Errorf("VelocityRule returned nil for %d orders, expected a finding", len(orders))
+	}
+}
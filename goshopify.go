@@ -3,6 +3,7 @@ package goshopify
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -47,6 +48,30 @@ type Client struct {
 	// A permanent access token
 	token string
 
+	// Identical GETs in flight, when requests are coalesced
+	flights *flightGroup
+
+	// Whether destructive calls are checked against the kind of shop, and
+	// the confirmation of this client's calls, if any
+	safeguard    *safeguard
+	confirmation *confirmation
+
+	// Where POST, PUT and DELETE requests are recorded, if anywhere
+	audit AuditSink
+
+	// Deprecated requests made so far, and the metadata of the responses to
+	// this client's calls, if it is collected
+	deprecations *deprecations
+	responseMeta *responseMeta
+
+	// Timeouts by path, and the breaker that stops requests to a failing shop
+	timeouts map[string]time.Duration
+	breaker  *circuitBreaker
+
+	// Where the retries of the client's retry loops are drawn from, if
+	// anywhere
+	retryBudget RetryBudget
+
 	// Services used for communicating with the API
 	Product                    ProductService
 	CustomCollection           CustomCollectionService
@@ -65,6 +90,13 @@ type Client struct {
 	Metafield                  MetafieldService
 	Blog                       BlogService
 	ApplicationCharge          ApplicationChargeService
+	GraphQL                    GraphQLService
+	BulkOperation              BulkOperationService
+	Export                     ExportService
+	OrderRisk                  OrderRiskService
+	CarrierService             CarrierServiceService
+	Collect                    CollectService
+	InventoryLevel             InventoryLevelService
 }
 
 // A general response error that follows a similar layout to Shopify's response
@@ -165,13 +197,25 @@ func (c *Client) NewRequest(method, urlStr string, body, options interface{}) (*
 
 // Returns a new Shopify API client with an already authenticated shopname and
 // token. The shopName parameter is the shop's myshopify domain,
-// e.g. "theshop.myshopify.com", or simply "theshop"
-func NewClient(app App, shopName, token string) *Client {
+// e.g. "theshop.myshopify.com", or simply "theshop". Options such as
+// WithTransport are applied once the client is set up.
+func NewClient(app App, shopName, token string, opts ...Option) *Client {
 	httpClient := http.DefaultClient
 
 	baseURL, _ := url.Parse(ShopBaseUrl(shopName))
 
-	c := &Client{Client: httpClient, app: app, baseURL: baseURL, token: token}
+	c := &Client{Client: httpClient, app: app, baseURL: baseURL, token: token, deprecations: &deprecations{}}
+	c.setServices()
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Point the services at the client.
+func (c *Client) setServices() {
 	c.Product = &ProductServiceOp{client: c}
 	c.CustomCollection = &CustomCollectionServiceOp{client: c}
 	c.SmartCollection = &SmartCollectionServiceOp{client: c}
@@ -189,19 +233,45 @@ func NewClient(app App, shopName, token string) *Client {
 	c.Metafield = &MetafieldServiceOp{client: c}
 	c.Blog = &BlogServiceOp{client: c}
 	c.ApplicationCharge = &ApplicationChargeServiceOp{client: c}
-
-	return c
+	c.GraphQL = &GraphQLServiceOp{client: c}
+	c.BulkOperation = &BulkOperationServiceOp{client: c}
+	c.Export = &ExportServiceOp{client: c}
+	c.OrderRisk = &OrderRiskServiceOp{client: c}
+	c.CarrierService = &CarrierServiceServiceOp{client: c}
+	c.Collect = &CollectServiceOp{client: c}
+	c.InventoryLevel = &InventoryLevelServiceOp{client: c}
 }
 
 // Do sends an API request and populates the given interface with the parsed
 // response. It does not make much sense to call Do without a prepared
 // interface instance.
 func (c *Client) Do(req *http.Request, v interface{}) error {
-	resp, err := c.Client.Do(req)
+	if timeout := c.timeout(req); timeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
+	if c.breaker != nil {
+		if err := c.breaker.allow(c.baseURL.Host); err != nil {
+			return err
+		}
+	}
+
+	resp, err := c.send(req)
+	if c.breaker != nil {
+		c.breaker.done(!requestFailed(resp, err))
+	}
+	if c.audit != nil && req.Method != "GET" {
+		c.recordAudit(req, resp, err)
+	}
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
+	if deprecation := c.deprecations.check(req, resp); deprecation != nil {
+		c.responseMeta.deprecated(*deprecation)
+	}
 
 	err = CheckResponseError(resp)
 	if err != nil {
@@ -219,6 +289,17 @@ func (c *Client) Do(req *http.Request, v interface{}) error {
 	return nil
 }
 
+// Send a request, sharing the response of an identical GET that is already
+// in flight when requests are coalesced.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.flights == nil || req.Method != "GET" {
+		return c.Client.Do(req)
+	}
+	return c.flights.do(req.Context(), req.URL.String(), func() (*http.Response, error) {
+		return c.Client.Do(req)
+	})
+}
+
 func wrapSpecificError(r *http.Response, err ResponseError) error {
 	if err.Status == 429 {
 		f, _ := strconv.ParseFloat(r.Header.Get("retry-after"), 64)
@@ -359,6 +440,13 @@ func (c *Client) Count(path string, options interface{}) (int, error) {
 // parameters like created_at_min
 // Any data returned from Shopify will be marshalled into resource argument.
 func (c *Client) CreateAndDo(method, path string, data, options, resource interface{}) error {
+	if method == "DELETE" {
+		err := c.guard(fmt.Sprintf("DELETE %s", path))
+		if err != nil {
+			return err
+		}
+	}
+
 	req, err := c.NewRequest(method, path, data, options)
 	if err != nil {
 		return err
@@ -0,0 +1,284 @@
+package goshopify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const graphQLPath = "admin/api/graphql.json"
+
+// GraphQLService is an interface for interfacing with the GraphQL endpoint
+// of the Shopify API.
+// See: https://help.shopify.com/api/graphql-admin-api
+type GraphQLService interface {
+	Query(string, interface{}, interface{}) error
+}
+
+// GraphQLServiceOp handles communication with the GraphQL endpoint of the
+// Shopify API.
+type GraphQLServiceOp struct {
+	client *Client
+}
+
+// GraphQLError is an error from the errors list of a GraphQL response.
+type GraphQLError struct {
+	Message string `json:"message"`
+}
+
+// UserError is an error reported by a GraphQL mutation in its userErrors
+// field.
+type UserError struct {
+	Field   []string `json:"field"`
+	Message string   `json:"message"`
+}
+
+// Query runs a GraphQL query or mutation with the given variables and saves
+// the data of the response in the given resource. Errors reported by the
+// GraphQL endpoint are returned as a ResponseError.
+func (s *GraphQLServiceOp) Query(q string, variables, resource interface{}) error {
+	err := s.client.guardMutations(q)
+	if err != nil {
+		return err
+	}
+
+	data := struct {
+		Query     string      `json:"query"`
+		Variables interface{} `json:"variables,omitempty"`
+	}{
+		Query:     q,
+		Variables: variables,
+	}
+
+	response := struct {
+		Data   interface{}    `json:"data"`
+		Errors []GraphQLError `json:"errors"`
+	}{
+		Data: resource,
+	}
+
+	err = s.client.Post(graphQLPath, data, &response)
+	if err != nil {
+		return err
+	}
+
+	return graphQLErrorsToError(response.Errors)
+}
+
+// Return the top level fields of the mutations in a GraphQL document, e.g.
+// ["productDelete"] for `mutation { productDelete(input: $input) { ... } }`.
+// Aliases, arguments, directives and the fields of queries are left out.
+func mutationFields(q string) []string {
+	tokens := graphQLTokens(q)
+
+	fields := []string{}
+	depth := 0
+	mutation := false
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		switch token {
+		case "{":
+			depth++
+		case "}":
+			depth--
+			if depth == 0 {
+				mutation = false
+			}
+		case "(":
+			// Skip arguments and variable definitions
+			for parens := 1; parens > 0 && i+1 < len(tokens); {
+				i++
+				if tokens[i] == "(" {
+					parens++
+				} else if tokens[i] == ")" {
+					parens--
+				}
+			}
+		case "@":
+			// Skip the name of a directive
+			i++
+		default:
+			if depth == 0 && token == "mutation" {
+				mutation = true
+			}
+			if depth != 1 || !mutation || !isGraphQLName(token) {
+				continue
+			}
+			if i+1 < len(tokens) && tokens[i+1] == ":" {
+				// An alias, followed by the field
+				continue
+			}
+			if i > 0 && tokens[i-1] == "..." {
+				continue
+			}
+			fields = append(fields, token)
+		}
+	}
+	return fields
+}
+
+// Split a GraphQL document into names and punctuation, leaving out strings,
+// comments, whitespace and commas.
+func graphQLTokens(q string) []string {
+	tokens := []string{}
+	for i := 0; i < len(q); {
+		c := q[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(q) && q[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(q[i:], `"""`):
+			end := strings.Index(q[i+3:], `"""`)
+			if end < 0 {
+				return tokens
+			}
+			i += end + 6
+		case c == '"':
+			for i++; i < len(q) && q[i] != '"'; i++ {
+				if q[i] == '\\' {
+					i++
+				}
+			}
+			i++
+		case strings.HasPrefix(q[i:], "..."):
+			tokens = append(tokens, "...")
+			i += 3
+		case isGraphQLNameByte(c):
+			start := i
+			for i < len(q) && isGraphQLNameByte(q[i]) {
+				i++
+			}
+			tokens = append(tokens, q[start:i])
+		default:
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+	return tokens
+}
+
+func isGraphQLNameByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// Whether a token is a name, as opposed to punctuation or a number.
+func isGraphQLName(token string) bool {
+	return token != "" && isGraphQLNameByte(token[0]) && (token[0] < '0' || token[0] > '9')
+}
+
+// Post a GraphQL query to an endpoint other than the admin API's, with the
+// given headers on top of the usual ones, and save the data of the response
+// in resource.
+func postGraphQL(client *http.Client, u string, header http.Header, q string, variables, resource interface{}) error {
+	data := struct {
+		Query     string      `json:"query"`
+		Variables interface{} `json:"variables,omitempty"`
+	}{
+		Query:     q,
+		Variables: variables,
+	}
+	js, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", u, bytes.NewBuffer(js))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("User-Agent", UserAgent)
+	for k, values := range header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	err = CheckResponseError(resp)
+	if err != nil {
+		return err
+	}
+
+	response := struct {
+		Data   interface{}    `json:"data"`
+		Errors []GraphQLError `json:"errors"`
+	}{
+		Data: resource,
+	}
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return err
+	}
+
+	return graphQLErrorsToError(response.Errors)
+}
+
+// Turn the errors of a GraphQL response into a ResponseError, or return nil
+// when there are none.
+func graphQLErrorsToError(errors []GraphQLError) error {
+	if len(errors) == 0 {
+		return nil
+	}
+
+	responseError := ResponseError{Status: 200}
+	for _, e := range errors {
+		responseError.Errors = append(responseError.Errors, e.Message)
+	}
+	responseError.Message = strings.Join(responseError.Errors, ", ")
+	return responseError
+}
+
+// Turn the userErrors of a mutation into a ResponseError, or return nil when
+// there are none.
+func userErrorsToError(userErrors []UserError) error {
+	if len(userErrors) == 0 {
+		return nil
+	}
+
+	responseError := ResponseError{Status: 200}
+	for _, e := range userErrors {
+		if len(e.Field) > 0 {
+			responseError.Errors = append(responseError.Errors, fmt.Sprintf("%v: %v", strings.Join(e.Field, "."), e.Message))
+		} else {
+			responseError.Errors = append(responseError.Errors, e.Message)
+		}
+	}
+	responseError.Message = strings.Join(responseError.Errors, ", ")
+	return responseError
+}
+
+// Return the numeric id of a GraphQL global id such as
+// "gid://shopify/Product/123", or 0 if it has none.
+func ParseGID(gid string) uint64 {
+	if i := strings.Index(gid, "?"); i >= 0 {
+		gid = gid[:i]
+	}
+	id, _ := strconv.ParseUint(gid[strings.LastIndex(gid, "/")+1:], 10, 64)
+	return id
+}
+
+// Return the resource type of a GraphQL global id, e.g. "Product" for
+// "gid://shopify/Product/123".
+func gidResource(gid string) string {
+	parts := strings.Split(strings.TrimPrefix(gid, "gid://shopify/"), "/")
+	return parts[0]
+}
+
+// Return the GraphQL global id of a resource, e.g. FormatGID("Product", 123)
+// returns "gid://shopify/Product/123".
+func FormatGID(resource string, id uint64) string {
+	return fmt.Sprintf("gid://shopify/%s/%d", resource, id)
+}
@@ -0,0 +1,114 @@
+package goshopify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestGraphQLQuery(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/graphql.json",
+		func(req *http.Request) (*http.Response, error) {
+			body := struct {
+				Query     string            `json:"query"`
+				Variables map[string]string `json:"variables"`
+			}{}
+			json.NewDecoder(req.Body).Decode(&body)
+			if body.Variables["handle"] != "shirt" {
+				return httpmock.NewStringResponse(400, `{"errors": "missing handle"}`), nil
+			}
+			return httpmock.NewStringResponse(200, `{"data": {"productByHandle": {"id": "gid://shopify/Product/1"}}}`), nil
+		})
+
+	resource := struct {
+		ProductByHandle struct {
+			ID string `json:"id"`
+		} `json:"productByHandle"`
+	}{}
+
+	query := `query($handle: String!) { productByHandle(handle: $handle) { id } }`
+	err := client.GraphQL.Query(query, map[string]string{"handle": "shirt"}, &resource)
+	if err != nil {
+		t.Errorf("GraphQL.Query returned error: %v", err)
+	}
+
+	expected := "gid://shopify/Product/1"
+	if resource.ProductByHandle.ID != expected {
+		t.Errorf("GraphQL.Query returned id %v, expected %v", resource.ProductByHandle.ID, expected)
+	}
+}
+
+func TestGraphQLQueryErrors(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/graphql.json",
+		httpmock.NewStringResponder(200, `{"errors": [{"message": "Field 'foo' doesn't exist on type 'QueryRoot'"}]}`))
+
+	err := client.GraphQL.Query(`{ foo }`, nil, nil)
+	expected := ResponseError{
+		Status:  200,
+		Message: "Field 'foo' doesn't exist on type 'QueryRoot'",
+		Errors:  []string{"Field 'foo' doesn't exist on type 'QueryRoot'"},
+	}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("GraphQL.Query returned error %#v, expected %#v", err, expected)
+	}
+}
+
+func TestUserErrorsToError(t *testing.T) {
+	cases := []struct {
+		userErrors []UserError
+		expected   error
+	}{
+		{nil, nil},
+		{
+			[]UserError{{Field: []string{"input", "title"}, Message: "can't be blank"}, {Message: "oh no"}},
+			ResponseError{
+				Status:  200,
+				Message: "input.title: can't be blank, oh no",
+				Errors:  []string{"input.title: can't be blank", "oh no"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		actual := userErrorsToError(c.userErrors)
+		if fmt.Sprint(actual) != fmt.Sprint(c.expected) {
+			t.Errorf("userErrorsToError(%v): expected %v, actual %v", c.userErrors, c.expected, actual)
+		}
+	}
+}
+
+func TestGID(t *testing.T) {
+	cases := []struct {
+		gid      string
+		resource string
+		id       uint64
+	}{
+		{"gid://shopify/Product/123", "Product", 123},
+		{"gid://shopify/ProductVariant/456?inventory=1", "ProductVariant", 456},
+		{"invalid", "invalid", 0},
+	}
+
+	for _, c := range cases {
+		if actual := ParseGID(c.gid); actual != c.id {
+			t.Errorf("ParseGID(%v): expected %v, actual %v", c.gid, c.id, actual)
+		}
+		if actual := gidResource(c.gid); actual != c.resource {
+			t.Errorf("gidResource(%v): expected %v, actual %v", c.gid, c.resource, actual)
+		}
+	}
+
+	expected := "gid://shopify/Product/123"
+	if actual := FormatGID("Product", 123); actual != expected {
+		t.Errorf("FormatGID(Product, 123): expected %v, actual %v", expected, actual)
+	}
+}
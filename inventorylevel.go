@@ -0,0 +1,54 @@
+package goshopify
+
+import (
+	"fmt"
+	"time"
+)
+
+const inventoryLevelsBasePath = "admin/inventory_levels"
+
+// InventoryLevelService is an interface for interfacing with the inventory
+// level endpoints of the Shopify API.
+// See: https://help.shopify.com/api/reference/inventory/inventorylevel
+type InventoryLevelService interface {
+	List(interface{}) ([]InventoryLevel, error)
+}
+
+// InventoryLevelServiceOp handles communication with the inventory level
+// related methods of the Shopify API.
+type InventoryLevelServiceOp struct {
+	client *Client
+}
+
+// InventoryLevel represents the quantity of an inventory item that is
+// available at a location.
+type InventoryLevel struct {
+	InventoryItemID int        `json:"inventory_item_id,omitempty"`
+	LocationID      int        `json:"location_id,omitempty"`
+	Available       int        `json:"available"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty"`
+}
+
+// InventoryLevelListOptions are the options for listing inventory levels.
+// Either inventory items or locations have to be given.
+type InventoryLevelListOptions struct {
+	InventoryItemIDs []int     `url:"inventory_item_ids,comma,omitempty"`
+	LocationIDs      []int     `url:"location_ids,comma,omitempty"`
+	Limit            int       `url:"limit,omitempty"`
+	Page             int       `url:"page,omitempty"`
+	UpdatedAtMin     time.Time `url:"updated_at_min,omitempty"`
+}
+
+// InventoryLevelsResource represents the result from the
+// inventory_levels.json endpoint
+type InventoryLevelsResource struct {
+	InventoryLevels []InventoryLevel `json:"inventory_levels"`
+}
+
+// List inventory levels
+func (s *InventoryLevelServiceOp) List(options interface{}) ([]InventoryLevel, error) {
+	path := fmt.Sprintf("%s.json", inventoryLevelsBasePath)
+	resource := new(InventoryLevelsResource)
+	err := s.client.Get(path, resource, options)
+	return resource.InventoryLevels, err
+}
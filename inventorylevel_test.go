@@ -0,0 +1,32 @@
+package goshopify
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestInventoryLevelList(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/inventory_levels.json?inventory_item_ids=1%2C2&limit=50",
+		httpmock.NewStringResponder(200, `{"inventory_levels": [
+			{"inventory_item_id": 1, "location_id": 11, "available": 3},
+			{"inventory_item_id": 2, "location_id": 11, "available": 0}
+		]}`))
+
+	levels, err := client.InventoryLevel.List(InventoryLevelListOptions{InventoryItemIDs: []int{1, 2}, Limit: 50})
+	if err != nil {
+		t.Errorf("InventoryLevel.List returned error: %v", err)
+	}
+
+	expected := []InventoryLevel{
+		{InventoryItemID: 1, LocationID: 11, Available: 3},
+		{InventoryItemID: 2, LocationID: 11, Available: 0},
+	}
+	if !reflect.DeepEqual(levels, expected) {
+		t.Errorf("InventoryLevel.List returned %+v, expected %+v", levels, expected)
+	}
+}
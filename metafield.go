@@ -2,9 +2,99 @@ package goshopify
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
+// The number of owners looked up per GraphQL request by ListByOwners, and the
+// metafields of each owner in that request. Their product is kept under the
+// cost of 1000 that Shopify allows for a single query; owners with more
+// metafields are paged on their own.
+const (
+	metafieldOwnersBatchSize = 20
+	metafieldsPerOwner       = 40
+	metafieldsPageSize       = 250
+)
+
+// The GraphQL types of the resources that can own metafields, keyed by their
+// REST resource name.
+var metafieldOwnerTypes = map[string]string{
+	productsResourceName:  "Product",
+	customersResourceName: "Customer",
+	ordersResourceName:    "Order",
+	"collections":         "Collection",
+	"variants":            "ProductVariant",
+}
+
+// The owner_resource of REST metafields, keyed by the GraphQL type of their
+// owner. It is not always the lowercase type, e.g. "variant" for
+// ProductVariant.
+var metafieldOwnerResources = map[string]string{
+	"Product":        "product",
+	"Customer":       "customer",
+	"Order":          "order",
+	"Collection":     "collection",
+	"ProductVariant": "variant",
+}
+
+const metafieldConnectionFields = `
+	pageInfo { hasNextPage endCursor }
+	edges { node { id namespace key value valueType description createdAt updatedAt } }
+`
+
+const metafieldsByOwnersQuery = `query metafieldsByOwners($ids: [ID!]!, $first: Int!) {
+	nodes(ids: $ids) {
+		id
+		... on HasMetafields {
+			metafields(first: $first) {` + metafieldConnectionFields + `}
+		}
+	}
+}`
+
+const metafieldsOfOwnerQuery = `query metafieldsOfOwner($id: ID!, $first: Int!, $after: String) {
+	node(id: $id) {
+		... on HasMetafields {
+			metafields(first: $first, after: $after) {` + metafieldConnectionFields + `}
+		}
+	}
+}`
+
+// A page of metafields in a GraphQL response
+type metafieldConnection struct {
+	PageInfo PageInfo `json:"pageInfo"`
+	Edges    []struct {
+		Node struct {
+			ID          string     `json:"id"`
+			Namespace   string     `json:"namespace"`
+			Key         string     `json:"key"`
+			Value       string     `json:"value"`
+			ValueType   string     `json:"valueType"`
+			Description string     `json:"description"`
+			CreatedAt   *time.Time `json:"createdAt"`
+			UpdatedAt   *time.Time `json:"updatedAt"`
+		} `json:"node"`
+	} `json:"edges"`
+}
+
+// Append the metafields of the page to list.
+func (c metafieldConnection) appendTo(list []Metafield, ownerType string, ownerID uint64) []Metafield {
+	for _, edge := range c.Edges {
+		list = append(list, Metafield{
+			ID:            ParseGID(edge.Node.ID),
+			Key:           edge.Node.Key,
+			Value:         edge.Node.Value,
+			ValueType:     strings.ToLower(edge.Node.ValueType),
+			Namespace:     edge.Node.Namespace,
+			Description:   edge.Node.Description,
+			OwnerId:       int(ownerID),
+			CreatedAt:     edge.Node.CreatedAt,
+			UpdatedAt:     edge.Node.UpdatedAt,
+			OwnerResource: metafieldOwnerResources[ownerType],
+		})
+	}
+	return list
+}
+
 // MetafieldService is an interface for interfacing with the metafield endpoints
 // of the Shopify API.
 // https://help.shopify.com/api/reference/metafield
@@ -15,6 +105,7 @@ type MetafieldService interface {
 	Create(Metafield) (*Metafield, error)
 	Update(Metafield) (*Metafield, error)
 	Delete(uint64) error
+	ListByOwners(string, []uint64) (map[uint64][]Metafield, error)
 }
 
 // MetafieldsService is an interface for other Shopify resources
@@ -111,3 +202,70 @@ func (s *MetafieldServiceOp) Delete(metafieldID uint64) error {
 	prefix := MetafieldPathPrefix(s.resource, s.resourceID)
 	return s.client.Delete(fmt.Sprintf("%s/%d.json", prefix, metafieldID))
 }
+
+// ListByOwners returns the metafields of many owners of one resource type,
+// e.g. "products", keyed by owner id. Owners are looked up 20 at a time with
+// the GraphQL nodes query instead of one request per owner, and the
+// metafields of owners with more than fit in that query are paged on their
+// own.
+func (s *MetafieldServiceOp) ListByOwners(resource string, ownerIDs []uint64) (map[uint64][]Metafield, error) {
+	ownerType, ok := metafieldOwnerTypes[resource]
+	if !ok {
+		return nil, fmt.Errorf("listing metafields of %s is not supported", resource)
+	}
+
+	metafields := make(map[uint64][]Metafield)
+	for start := 0; start < len(ownerIDs); start += metafieldOwnersBatchSize {
+		end := start + metafieldOwnersBatchSize
+		if end > len(ownerIDs) {
+			end = len(ownerIDs)
+		}
+
+		ids := make([]string, end-start)
+		for i, id := range ownerIDs[start:end] {
+			ids[i] = FormatGID(ownerType, id)
+		}
+
+		data := struct {
+			Nodes []*struct {
+				ID         string              `json:"id"`
+				Metafields metafieldConnection `json:"metafields"`
+			} `json:"nodes"`
+		}{}
+		variables := map[string]interface{}{"ids": ids, "first": metafieldsPerOwner}
+		err := s.client.GraphQL.Query(metafieldsByOwnersQuery, variables, &data)
+		if err != nil {
+			return metafields, err
+		}
+
+		for _, node := range data.Nodes {
+			// Owners that do not exist come back as null
+			if node == nil {
+				continue
+			}
+			ownerID := ParseGID(node.ID)
+
+			list := node.Metafields.appendTo([]Metafield{}, ownerType, ownerID)
+			pageInfo := node.Metafields.PageInfo
+			for pageInfo.HasNextPage {
+				page := struct {
+					Node *struct {
+						Metafields metafieldConnection `json:"metafields"`
+					} `json:"node"`
+				}{}
+				variables := map[string]interface{}{"id": node.ID, "first": metafieldsPageSize, "after": pageInfo.EndCursor}
+				err := s.client.GraphQL.Query(metafieldsOfOwnerQuery, variables, &page)
+				if err != nil {
+					return metafields, err
+				}
+				if page.Node == nil {
+					break
+				}
+				list = page.Node.Metafields.appendTo(list, ownerType, ownerID)
+				pageInfo = page.Node.Metafields.PageInfo
+			}
+			metafields[ownerID] = list
+		}
+	}
+	return metafields, nil
+}
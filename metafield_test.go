@@ -1,7 +1,10 @@
 package goshopify
 
 import (
+	"encoding/json"
+	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -139,3 +142,126 @@ func TestMetafieldDelete(t *testing.T) {
 		t.Errorf("Metafield.Delete returned error: %v", err)
 	}
 }
+
+func TestMetafieldListByOwners(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := 0
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/graphql.json",
+		func(req *http.Request) (*http.Response, error) {
+			requests++
+			body := struct {
+				Variables struct {
+					IDs   []string `json:"ids"`
+					ID    string   `json:"id"`
+					First int      `json:"first"`
+					After string   `json:"after"`
+				} `json:"variables"`
+			}{}
+			json.NewDecoder(req.Body).Decode(&body)
+
+			// The remaining pages of product 3
+			if body.Variables.ID != "" {
+				if body.Variables.ID != "gid://shopify/Product/3" || body.Variables.First > 250 {
+					return httpmock.NewStringResponse(400, ""), nil
+				}
+				if body.Variables.After == "c1" {
+					return httpmock.NewStringResponse(200, `{"data": {"node": {"metafields": {
+						"pageInfo": {"hasNextPage": true, "endCursor": "c2"},
+						"edges": [{"node": {"id": "gid://shopify/Metafield/32"}}]
+					}}}}`), nil
+				}
+				return httpmock.NewStringResponse(200, `{"data": {"node": {"metafields": {
+					"pageInfo": {"hasNextPage": false, "endCursor": "c3"},
+					"edges": [{"node": {"id": "gid://shopify/Metafield/33"}}]
+				}}}}`), nil
+			}
+
+			// Shopify rejects queries that may cost more than 1000
+			if len(body.Variables.IDs)*body.Variables.First > 1000 {
+				return httpmock.NewStringResponse(200, `{"errors": [{"message": "Query cost is too high", "extensions": {"code": "MAX_COST_EXCEEDED"}}]}`), nil
+			}
+
+			nodes := []string{}
+			for _, id := range body.Variables.IDs {
+				switch id {
+				case "gid://shopify/Product/1":
+					nodes = append(nodes, `{"id": "gid://shopify/Product/1", "metafields": {
+						"pageInfo": {"hasNextPage": false},
+						"edges": [{"node": {"id": "gid://shopify/Metafield/11", "namespace": "inventory",
+							"key": "warehouse", "value": "25", "valueType": "INTEGER"}}]
+					}}`)
+				case "gid://shopify/Product/2":
+					nodes = append(nodes, `null`)
+				case "gid://shopify/ProductVariant/1":
+					nodes = append(nodes, `{"id": "gid://shopify/ProductVariant/1", "metafields": {
+						"pageInfo": {"hasNextPage": false},
+						"edges": [{"node": {"id": "gid://shopify/Metafield/41"}}]
+					}}`)
+				case "gid://shopify/Product/3":
+					nodes = append(nodes, `{"id": "gid://shopify/Product/3", "metafields": {
+						"pageInfo": {"hasNextPage": true, "endCursor": "c1"},
+						"edges": [{"node": {"id": "gid://shopify/Metafield/31"}}]
+					}}`)
+				default:
+					nodes = append(nodes, `{"id": "`+id+`", "metafields": {"pageInfo": {"hasNextPage": false}, "edges": []}}`)
+				}
+			}
+			return httpmock.NewStringResponse(200, `{"data": {"nodes": [`+strings.Join(nodes, ",")+`]}}`), nil
+		})
+
+	ids := []uint64{}
+	for id := uint64(1); id <= 51; id++ {
+		ids = append(ids, id)
+	}
+
+	metafields, err := client.Metafield.ListByOwners("products", ids)
+	if err != nil {
+		t.Fatalf("Metafield.ListByOwners returned error: %v", err)
+	}
+
+	if requests != 5 {
+		t.Errorf("Metafield.ListByOwners made %d GraphQL requests, expected 3 batches and 2 pages", requests)
+	}
+	if len(metafields) != 50 {
+		t.Errorf("Metafield.ListByOwners returned %d owners, expected 50", len(metafields))
+	}
+
+	expected := []Metafield{{
+		ID:            11,
+		Key:           "warehouse",
+		Value:         "25",
+		ValueType:     "integer",
+		Namespace:     "inventory",
+		OwnerId:       1,
+		OwnerResource: "product",
+	}}
+	if !reflect.DeepEqual(metafields[1], expected) {
+		t.Errorf("Metafield.ListByOwners returned %+v for product 1, expected %+v", metafields[1], expected)
+	}
+
+	expected = []Metafield{
+		{ID: 31, Value: "", OwnerId: 3, OwnerResource: "product"},
+		{ID: 32, Value: "", OwnerId: 3, OwnerResource: "product"},
+		{ID: 33, Value: "", OwnerId: 3, OwnerResource: "product"},
+	}
+	if !reflect.DeepEqual(metafields[3], expected) {
+		t.Errorf("Metafield.ListByOwners returned %+v for product 3, expected %+v", metafields[3], expected)
+	}
+
+	// Variant metafields are owned by "variant" in REST
+	metafields, err = client.Metafield.ListByOwners("variants", []uint64{1})
+	if err != nil {
+		t.Fatalf("Metafield.ListByOwners returned error: %v", err)
+	}
+	expected = []Metafield{{ID: 41, Value: "", OwnerId: 1, OwnerResource: "variant"}}
+	if !reflect.DeepEqual(metafields[1], expected) {
+		t.Errorf("Metafield.ListByOwners returned %+v for variant 1, expected %+v", metafields[1], expected)
+	}
+
+	_, err = client.Metafield.ListByOwners("themes", ids)
+	if err == nil {
+		t.Errorf("Metafield.ListByOwners returned no error for an unsupported resource")
+	}
+}
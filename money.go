@@ -0,0 +1,169 @@
+package goshopify
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// PriceSet holds an amount in both the shop's currency and the currency the
+// customer was presented with at checkout.
+type PriceSet struct {
+	ShopMoney        Money `json:"shop_money"`
+	PresentmentMoney Money `json:"presentment_money"`
+}
+
+// Money is an amount in a given currency.
+type Money struct {
+	Amount       *decimal.Decimal `json:"amount,omitempty"`
+	CurrencyCode string           `json:"currency_code,omitempty"`
+}
+
+// Currencies that do not use two decimal places for their minor units.
+var currencyPlaces = map[string]int32{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0,
+	"XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// Return the number of decimal places used by the given ISO 4217 currency.
+func CurrencyPlaces(currency string) int32 {
+	if places, ok := currencyPlaces[strings.ToUpper(currency)]; ok {
+		return places
+	}
+	return 2
+}
+
+// FormatMoney formats an amount with the shop's money_format, e.g.
+// "${{amount}}" formats 1234.5 as "$1,234.50" and
+// "{{amount_with_comma_separator}} €" as "1.234,50 €", like the storefront.
+func (s Shop) FormatMoney(amount decimal.Decimal) string {
+	return formatMoney(s.MoneyFormat, amount)
+}
+
+// FormatMoneyWithCurrency formats an amount with the shop's
+// money_with_currency_format, e.g. "${{amount}} USD".
+func (s Shop) FormatMoneyWithCurrency(amount decimal.Decimal) string {
+	return formatMoney(s.MoneyWithCurrencyFormat, amount)
+}
+
+// The placeholders of money formats and how they format amounts
+var moneyPlaceholders = map[string]struct {
+	places           int32
+	thousands, point string
+}{
+	"amount":                      {2, ",", "."},
+	"amount_no_decimals":          {0, ",", "."},
+	"amount_with_comma_separator": {2, ".", ","},
+	"amount_no_decimals_with_comma_separator": {0, ".", ","},
+	"amount_with_apostrophe_separator":        {2, "'", "."},
+	"amount_no_decimals_with_space_separator": {0, " ", ","},
+	"amount_with_space_separator":             {2, " ", ","},
+	"amount_with_period_and_space_separator":  {2, " ", "."},
+}
+
+var moneyPlaceholderRegexp = regexp.MustCompile(`{{\s*(\w+)\s*}}`)
+
+// Replace the placeholders of a money format, e.g. "{{amount}}" or
+// "{{ amount_with_comma_separator }}", with the amount. Unknown placeholders
+// are left as they are.
+func formatMoney(format string, amount decimal.Decimal) string {
+	return moneyPlaceholderRegexp.ReplaceAllStringFunc(format, func(placeholder string) string {
+		name := moneyPlaceholderRegexp.FindStringSubmatch(placeholder)[1]
+		p, ok := moneyPlaceholders[name]
+		if !ok {
+			return placeholder
+		}
+		return formatAmount(amount, p.places, p.thousands, p.point)
+	})
+}
+
+// Format an amount with the given number of decimals and separators.
+func formatAmount(amount decimal.Decimal, places int32, thousands, point string) string {
+	s := amount.Abs().StringFixed(places)
+	integer, fraction := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		integer, fraction = s[:i], s[i+1:]
+	}
+
+	var grouped []string
+	for len(integer) > 3 {
+		grouped = append([]string{integer[len(integer)-3:]}, grouped...)
+		integer = integer[:len(integer)-3]
+	}
+	grouped = append([]string{integer}, grouped...)
+
+	s = strings.Join(grouped, thousands)
+	if fraction != "" {
+		s += point + fraction
+	}
+	if amount.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// ExchangeRate returns the rate at which the order's shop currency amounts
+// were converted to its presentment currency. The rate is derived from the
+// order's price sets, which means the order must have been fetched with the
+// total_price_set or subtotal_price_set fields.
+func (o Order) ExchangeRate() (decimal.Decimal, error) {
+	shop, presentment, err := o.exchangeAmounts()
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return presentment.Div(shop), nil
+}
+
+// ToPresentmentCurrency converts an amount in the shop's currency to the
+// order's presentment currency, rounded to the currency's minor unit.
+func (o Order) ToPresentmentCurrency(amount decimal.Decimal) (decimal.Decimal, error) {
+	shop, presentment, err := o.exchangeAmounts()
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return amount.Mul(presentment).Div(shop).Round(CurrencyPlaces(o.presentmentCurrency())), nil
+}
+
+// ToShopCurrency converts an amount in the order's presentment currency to
+// the shop's currency, rounded to the currency's minor unit.
+func (o Order) ToShopCurrency(amount decimal.Decimal) (decimal.Decimal, error) {
+	shop, presentment, err := o.exchangeAmounts()
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return amount.Mul(shop).Div(presentment).Round(CurrencyPlaces(o.Currency)), nil
+}
+
+func (o Order) presentmentCurrency() string {
+	if o.PresentmentCurrency != "" {
+		return o.PresentmentCurrency
+	}
+	return o.Currency
+}
+
+// Return a pair of matching shop and presentment amounts from the order.
+// Multiplying and dividing by these directly, rather than by a precomputed
+// rate, avoids losing precision on the round trip.
+func (o Order) exchangeAmounts() (decimal.Decimal, decimal.Decimal, error) {
+	for _, set := range []*PriceSet{o.TotalPriceSet, o.SubtotalPriceSet} {
+		if set == nil || set.ShopMoney.Amount == nil || set.PresentmentMoney.Amount == nil {
+			continue
+		}
+		if set.ShopMoney.Amount.Sign() == 0 || set.PresentmentMoney.Amount.Sign() == 0 {
+			continue
+		}
+		return *set.ShopMoney.Amount, *set.PresentmentMoney.Amount, nil
+	}
+
+	// Orders presented in the shop's own currency convert one to one.
+	if o.presentmentCurrency() == o.Currency {
+		one := decimal.New(1, 0)
+		return one, one, nil
+	}
+
+	return decimal.Zero, decimal.Zero, fmt.Errorf("order %d has no price set to derive an exchange rate from", o.ID)
+}
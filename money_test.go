@@ -0,0 +1,137 @@
+package goshopify
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestShopFormatMoney(t *testing.T) {
+	shop := Shop{
+		MoneyFormat:             "${{amount}}",
+		MoneyWithCurrencyFormat: "${{amount}} USD",
+	}
+
+	cases := []struct {
+		amount       string
+		expected     string
+		withCurrency string
+	}{
+		{"0", "$0.00", "$0.00 USD"},
+		{"5", "$5.00", "$5.00 USD"},
+		{"999.999", "$1,000.00", "$1,000.00 USD"},
+		{"1234567.5", "$1,234,567.50", "$1,234,567.50 USD"},
+		{"-42.1", "$-42.10", "$-42.10 USD"},
+	}
+
+	for _, c := range cases {
+		amount, _ := decimal.NewFromString(c.amount)
+		if actual := shop.FormatMoney(amount); actual != c.expected {
+			t.Errorf("Shop.FormatMoney(%v) = %v, expected %v", c.amount, actual, c.expected)
+		}
+		if actual := shop.FormatMoneyWithCurrency(amount); actual != c.withCurrency {
+			t.Errorf("Shop.FormatMoneyWithCurrency(%v) = %v, expected %v", c.amount, actual, c.withCurrency)
+		}
+	}
+}
+
+func TestFormatMoneyPlaceholders(t *testing.T) {
+	amount, _ := decimal.NewFromString("1134.65")
+
+	cases := []struct {
+		format   string
+		expected string
+	}{
+		{"{{amount_no_decimals}}", "1,135"},
+		{"{{amount_with_comma_separator}} €", "1.134,65 €"},
+		{"{{amount_no_decimals_with_comma_separator}}", "1.135"},
+		{"CHF {{amount_with_apostrophe_separator}}", "CHF 1'134.65"},
+		{"{{amount_no_decimals_with_space_separator}} kr", "1 135 kr"},
+		{"{{amount_with_space_separator}}", "1 134,65"},
+		{"{{amount_with_period_and_space_separator}}", "1 134.65"},
+		{"<span class=money>${{ amount }}</span>", "<span class=money>$1,134.65</span>"},
+		{"{{amount}} ({{unknown}})", "1,134.65 ({{unknown}})"},
+	}
+
+	for _, c := range cases {
+		shop := Shop{MoneyFormat: c.format}
+		if actual := shop.FormatMoney(amount); actual != c.expected {
+			t.Errorf("Shop.FormatMoney with %q = %v, expected %v", c.format, actual, c.expected)
+		}
+	}
+}
+
+func TestCurrencyPlaces(t *testing.T) {
+	cases := []struct {
+		currency string
+		expected int32
+	}{
+		{"USD", 2},
+		{"jpy", 0},
+		{"KWD", 3},
+		{"", 2},
+	}
+
+	for _, c := range cases {
+		if actual := CurrencyPlaces(c.currency); actual != c.expected {
+			t.Errorf("CurrencyPlaces(%v) = %v, expected %v", c.currency, actual, c.expected)
+		}
+	}
+}
+
+func TestOrderCurrencyConversion(t *testing.T) {
+	shopTotal := decimal.NewFromFloat(100)
+	presentmentTotal := decimal.NewFromFloat(13512)
+	order := Order{
+		Currency:            "USD",
+		PresentmentCurrency: "JPY",
+		TotalPriceSet: &PriceSet{
+			ShopMoney:        Money{Amount: &shopTotal, CurrencyCode: "USD"},
+			PresentmentMoney: Money{Amount: &presentmentTotal, CurrencyCode: "JPY"},
+		},
+	}
+
+	rate, err := order.ExchangeRate()
+	if err != nil {
+		t.Fatalf("Order.ExchangeRate returned error: %v", err)
+	}
+	if expected := decimal.NewFromFloat(135.12); !rate.Equals(expected) {
+		t.Errorf("Order.ExchangeRate returned %v, expected %v", rate, expected)
+	}
+
+	presentment, err := order.ToPresentmentCurrency(decimal.NewFromFloat(12.34))
+	if err != nil {
+		t.Errorf("Order.ToPresentmentCurrency returned error: %v", err)
+	}
+	if expected := decimal.NewFromFloat(1667); !presentment.Equals(expected) {
+		t.Errorf("Order.ToPresentmentCurrency returned %v, expected %v", presentment, expected)
+	}
+
+	shop, err := order.ToShopCurrency(decimal.NewFromFloat(1667))
+	if err != nil {
+		t.Errorf("Order.ToShopCurrency returned error: %v", err)
+	}
+	if expected := decimal.NewFromFloat(12.34); !shop.Equals(expected) {
+		t.Errorf("Order.ToShopCurrency returned %v, expected %v", shop, expected)
+	}
+}
+
+func TestOrderCurrencyConversionWithoutPriceSets(t *testing.T) {
+	order := Order{ID: 1, Currency: "USD"}
+	amount := decimal.NewFromFloat(12.34)
+
+	converted, err := order.ToPresentmentCurrency(amount)
+	if err != nil {
+		t.Errorf("Order.ToPresentmentCurrency returned error: %v", err)
+	}
+	if !converted.Equals(amount) {
+		t.Errorf("Order.ToPresentmentCurrency returned %v, expected %v", converted, amount)
+	}
+
+	order.PresentmentCurrency = "EUR"
+	_, err = order.ToPresentmentCurrency(amount)
+	expected := "order 1 has no price set to derive an exchange rate from"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Order.ToPresentmentCurrency returned error %v, expected %v", err, expected)
+	}
+}
@@ -0,0 +1,109 @@
+package goshopify
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Option customizes a Client created by NewClient.
+type Option func(*Client)
+
+// TransportOptions tune the connections a Client makes to Shopify.
+type TransportOptions struct {
+	// Connections kept open per shop for reuse. Go keeps 2 by default, which
+	// throttles concurrent requests to the same shop.
+	MaxIdleConnsPerHost int
+
+	// Time an idle connection is kept open
+	IdleConnTimeout time.Duration
+
+	// Interval of TCP keep-alive probes, or 0 to disable them
+	KeepAlive time.Duration
+
+	// Open a new connection for every request
+	DisableKeepAlives bool
+
+	// Number of TLS sessions cached for resumption, or 0 to always perform a
+	// full handshake
+	TLSSessionCacheSize int
+
+	// Only use HTTP/1.1
+	DisableHTTP2 bool
+
+	// Time allowed for connecting and for the TLS handshake
+	DialTimeout         time.Duration
+	TLSHandshakeTimeout time.Duration
+}
+
+// DefaultTransportOptions returns transport options suited to many
+// concurrent requests against one shop, as done by FetchAll and the syncers.
+func DefaultTransportOptions() TransportOptions {
+	return TransportOptions{
+		MaxIdleConnsPerHost: 32,
+		IdleConnTimeout:     90 * time.Second,
+		KeepAlive:           30 * time.Second,
+		TLSSessionCacheSize: 64,
+		DialTimeout:         30 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+}
+
+// NewTransport returns an HTTP transport configured with the given options.
+func NewTransport(options TransportOptions) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   options.DialTimeout,
+		KeepAlive: options.KeepAlive,
+	}
+	if options.KeepAlive == 0 {
+		// A zero KeepAlive means the Go default, a negative one disables it
+		dialer.KeepAlive = -1
+	}
+
+	tlsConfig := &tls.Config{}
+	if options.TLSSessionCacheSize > 0 {
+		tlsConfig.ClientSessionCache = tls.NewLRUClientSessionCache(options.TLSSessionCacheSize)
+	}
+
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         dialer.DialContext,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: options.TLSHandshakeTimeout,
+		MaxIdleConnsPerHost: options.MaxIdleConnsPerHost,
+		IdleConnTimeout:     options.IdleConnTimeout,
+		DisableKeepAlives:   options.DisableKeepAlives,
+		// A custom TLS config turns HTTP/2 off unless it is asked for
+		ForceAttemptHTTP2: !options.DisableHTTP2,
+	}
+	if options.DisableHTTP2 {
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+	return transport
+}
+
+// WithTransport makes the client use its own HTTP client with a transport
+// configured with the given options, instead of http.DefaultClient.
+func WithTransport(options TransportOptions) Option {
+	return func(c *Client) {
+		c.Client = &http.Client{Transport: NewTransport(options)}
+	}
+}
+
+// WithHTTPClient makes the client send its requests with the given HTTP
+// client.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.Client = httpClient
+	}
+}
+
+// WithRequestCoalescing makes concurrent identical GET requests share a
+// single request to Shopify and its response, e.g. when many workers fetch
+// the shop at once.
+func WithRequestCoalescing() Option {
+	return func(c *Client) {
+		c.flights = &flightGroup{}
+	}
+}
@@ -0,0 +1,54 @@
+package goshopify
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewClientWithTransport(t *testing.T) {
+	options := DefaultTransportOptions()
+	options.MaxIdleConnsPerHost = 10
+	testClient := NewClient(app, "fooshop", "abcd", WithTransport(options))
+
+	if testClient.Client == http.DefaultClient {
+		t.Fatalf("NewClient with WithTransport uses http.DefaultClient")
+	}
+
+	transport, ok := testClient.Client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("NewClient with WithTransport uses transport %T, expected *http.Transport", testClient.Client.Transport)
+	}
+	if transport.MaxIdleConnsPerHost != 10 {
+		t.Errorf("Transport.MaxIdleConnsPerHost is %d, expected 10", transport.MaxIdleConnsPerHost)
+	}
+	if transport.IdleConnTimeout != 90*time.Second {
+		t.Errorf("Transport.IdleConnTimeout is %v, expected 90s", transport.IdleConnTimeout)
+	}
+	if transport.TLSClientConfig.ClientSessionCache == nil {
+		t.Errorf("Transport has no TLS session cache")
+	}
+	if !transport.ForceAttemptHTTP2 || transport.TLSNextProto != nil {
+		t.Errorf("Transport does not attempt HTTP/2")
+	}
+}
+
+func TestNewTransportDisableHTTP2(t *testing.T) {
+	transport := NewTransport(TransportOptions{DisableHTTP2: true})
+
+	if transport.ForceAttemptHTTP2 || transport.TLSNextProto == nil {
+		t.Errorf("NewTransport attempts HTTP/2 when it is disabled")
+	}
+	if transport.TLSClientConfig.ClientSessionCache != nil {
+		t.Errorf("NewTransport caches TLS sessions with a cache size of 0")
+	}
+}
+
+func TestNewClientWithHTTPClient(t *testing.T) {
+	httpClient := &http.Client{Timeout: time.Second}
+	testClient := NewClient(app, "fooshop", "abcd", WithHTTPClient(httpClient))
+
+	if testClient.Client != httpClient {
+		t.Errorf("NewClient with WithHTTPClient uses %v, expected %v", testClient.Client, httpClient)
+	}
+}
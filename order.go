@@ -18,6 +18,9 @@ type OrderService interface {
 	Count(interface{}) (int, error)
 	Get(uint64, interface{}) (*Order, error)
 	Create(Order) (*Order, error)
+	Cancel(uint64, interface{}) (*Order, error)
+	SuggestRefund(uint64, []RefundLineItem, *RefundShipping) (*Refund, error)
+	Search(OrderSearch, PageInfo) ([]Order, PageInfo, error)
 
 	// MetafieldsService used for Order resource to communicate with Metafields resource
 	MetafieldsService
@@ -64,6 +67,15 @@ type OrderListOptions struct {
 	Order             string    `url:"order,omitempty"`
 }
 
+// A struct for all available order cancel options.
+// See: https://help.shopify.com/api/reference/order#cancel
+type OrderCancelOptions struct {
+	Amount  *decimal.Decimal `json:"amount,omitempty"`
+	Restock bool             `json:"restock,omitempty"`
+	Reason  string           `json:"reason,omitempty"`
+	Email   bool             `json:"email,omitempty"`
+}
+
 // Order represents a Shopify order
 type Order struct {
 	ID                    uint64              `json:"id,omitempty"`
@@ -78,8 +90,11 @@ type Order struct {
 	BillingAddress        *Address         `json:"billing_address,omitempty"`
 	ShippingAddress       *Address         `json:"shipping_address,omitempty"`
 	Currency              string           `json:"currency,omitempty"`
+	PresentmentCurrency   string           `json:"presentment_currency,omitempty"`
 	TotalPrice            *decimal.Decimal `json:"total_price,omitempty"`
+	TotalPriceSet         *PriceSet        `json:"total_price_set,omitempty"`
 	SubtotalPrice         *decimal.Decimal `json:"subtotal_price,omitempty"`
+	SubtotalPriceSet      *PriceSet        `json:"subtotal_price_set,omitempty"`
 	TotalDiscounts        *decimal.Decimal `json:"total_discounts,omitempty"`
 	TotalLineItemsPrice   *decimal.Decimal `json:"total_line_items_price,omitempty"`
 	TaxesIncluded         bool             `json:"taxes_included,omitempty"`
@@ -231,24 +246,25 @@ type TaxLine struct {
 }
 
 type Transaction struct {
-	ID             uint64              `json:"id,omitempty"`
-	OrderID        int              `json:"order_id,omitempty"`
-	Amount         *decimal.Decimal `json:"amount,omitempty"`
-	Kind           string           `json:"kind,omitempty"`
-	Gateway        string           `json:"gateway,omitempty"`
-	Status         string           `json:"status,omitempty"`
-	Message        string           `json:"message,omitempty"`
-	CreatedAt      *time.Time       `json:"created_at,omitempty"`
-	Test           bool             `json:"test,omitempty"`
-	Authorization  string           `json:"authorization,omitempty"`
-	Currency       string           `json:"currency,omitempty"`
-	LocationID     *int             `json:"location_id,omitempty"`
-	UserID         *int             `json:"user_id,omitempty"`
-	ParentID       *int             `json:"parent_id,omitempty"`
-	DeviceID       *int             `json:"device_id,omitempty"`
-	ErrorCode      string           `json:"error_code,omitempty"`
-	SourceName     string           `json:"source_name,omitempty"`
-	PaymentDetails *PaymentDetails  `json:"payment_details,omitempty"`
+	ID                uint64           `json:"id,omitempty"`
+	OrderID           int              `json:"order_id,omitempty"`
+	Amount            *decimal.Decimal `json:"amount,omitempty"`
+	Kind              string           `json:"kind,omitempty"`
+	Gateway           string           `json:"gateway,omitempty"`
+	Status            string           `json:"status,omitempty"`
+	Message           string           `json:"message,omitempty"`
+	CreatedAt         *time.Time       `json:"created_at,omitempty"`
+	Test              bool             `json:"test,omitempty"`
+	Authorization     string           `json:"authorization,omitempty"`
+	Currency          string           `json:"currency,omitempty"`
+	LocationID        *int             `json:"location_id,omitempty"`
+	UserID            *int             `json:"user_id,omitempty"`
+	ParentID          *int             `json:"parent_id,omitempty"`
+	DeviceID          *int             `json:"device_id,omitempty"`
+	ErrorCode         string           `json:"error_code,omitempty"`
+	SourceName        string           `json:"source_name,omitempty"`
+	PaymentDetails    *PaymentDetails  `json:"payment_details,omitempty"`
+	MaximumRefundable *decimal.Decimal `json:"maximum_refundable,omitempty"`
 }
 
 type Fulfillment struct {
@@ -283,21 +299,56 @@ type ClientDetails struct {
 }
 
 type Refund struct {
-	Id              int              `json:"id,omitempty"`
-	OrderId         int              `json:"order_id,omitempty"`
-	CreatedAt       *time.Time       `json:"created_at,omitempty"`
-	Note            string           `json:"note,omitempty"`
-	Restock         bool             `json:"restock,omitempty"`
-	UserId          int              `json:"user_id,omitempty"`
-	RefundLineItems []RefundLineItem `json:"refund_line_items,omitempty"`
-	Transactions    []Transaction    `json:"transactions,omitempty"`
+	Id               int               `json:"id,omitempty"`
+	OrderId          int               `json:"order_id,omitempty"`
+	CreatedAt        *time.Time        `json:"created_at,omitempty"`
+	Note             string            `json:"note,omitempty"`
+	Restock          bool              `json:"restock,omitempty"`
+	UserId           int               `json:"user_id,omitempty"`
+	RefundLineItems  []RefundLineItem  `json:"refund_line_items,omitempty"`
+	Transactions     []Transaction     `json:"transactions,omitempty"`
+	Shipping         *RefundShipping   `json:"shipping,omitempty"`
+	OrderAdjustments []OrderAdjustment `json:"order_adjustments,omitempty"`
+	Currency         string            `json:"currency,omitempty"`
 }
 
 type RefundLineItem struct {
-	Id         int       `json:"id,omitempty"`
-	Quantity   int       `json:"quantity,omitempty"`
-	LineItemId int       `json:"line_item_id,omitempty"`
-	LineItem   *LineItem `json:"line_item,omitempty"`
+	Id          int              `json:"id,omitempty"`
+	Quantity    int              `json:"quantity,omitempty"`
+	LineItemId  int              `json:"line_item_id,omitempty"`
+	LineItem    *LineItem        `json:"line_item,omitempty"`
+	RestockType string           `json:"restock_type,omitempty"`
+	LocationId  int              `json:"location_id,omitempty"`
+	Subtotal    *decimal.Decimal `json:"subtotal,omitempty"`
+	TotalTax    *decimal.Decimal `json:"total_tax,omitempty"`
+}
+
+// OrderAdjustment is a part of a refund that is not for a line item, e.g.
+// refunded shipping, of kind shipping_refund, or the difference between the
+// refunded line items and the amount refunded, of kind refund_discrepancy.
+// Amounts taken off the order are negative.
+type OrderAdjustment struct {
+	Id        int              `json:"id,omitempty"`
+	OrderId   int              `json:"order_id,omitempty"`
+	RefundId  int              `json:"refund_id,omitempty"`
+	Kind      string           `json:"kind,omitempty"`
+	Reason    string           `json:"reason,omitempty"`
+	Amount    *decimal.Decimal `json:"amount,omitempty"`
+	TaxAmount *decimal.Decimal `json:"tax_amount,omitempty"`
+}
+
+// RefundShipping is the shipping part of a refund. Set FullRefund or Amount
+// when asking for a refund, the other fields are filled in by Shopify.
+type RefundShipping struct {
+	FullRefund        bool             `json:"full_refund,omitempty"`
+	Amount            *decimal.Decimal `json:"amount,omitempty"`
+	Tax               *decimal.Decimal `json:"tax,omitempty"`
+	MaximumRefundable *decimal.Decimal `json:"maximum_refundable,omitempty"`
+}
+
+// Represents the result from the orders/X/refunds/calculate.json endpoint
+type RefundResource struct {
+	Refund *Refund `json:"refund"`
 }
 
 // List orders
@@ -331,6 +382,45 @@ func (s *OrderServiceOp) Create(order Order) (*Order, error) {
 	return resource.Order, err
 }
 
+// Cancel an order. The options are usually an OrderCancelOptions.
+func (s *OrderServiceOp) Cancel(orderID uint64, options interface{}) (*Order, error) {
+	path := fmt.Sprintf("%s/%d/cancel.json", ordersBasePath, orderID)
+	resource := new(OrderResource)
+	err := s.client.Post(path, options, resource)
+	return resource.Order, err
+}
+
+// SuggestRefund calculates a refund for the given line items and shipping
+// and returns it ready to be submitted. Shopify's suggested transactions are
+// turned into refund transactions, keeping the gateway and parent_id that
+// Shopify picked for them.
+func (s *OrderServiceOp) SuggestRefund(orderID uint64, lineItems []RefundLineItem, shipping *RefundShipping) (*Refund, error) {
+	path := fmt.Sprintf("%s/%d/refunds/calculate.json", ordersBasePath, orderID)
+	wrappedData := RefundResource{Refund: &Refund{RefundLineItems: lineItems, Shipping: shipping}}
+	resource := new(RefundResource)
+	err := s.client.Post(path, wrappedData, resource)
+	if err != nil {
+		return resource.Refund, err
+	}
+	if resource.Refund == nil {
+		return nil, fmt.Errorf("no refund calculated for order %d", orderID)
+	}
+
+	refund := resource.Refund
+	if refund.Shipping != nil {
+		// Only the amount is accepted when creating the refund
+		refund.Shipping = &RefundShipping{Amount: refund.Shipping.Amount}
+	}
+	for i := range refund.Transactions {
+		transaction := &refund.Transactions[i]
+		if transaction.Kind == "suggested_refund" {
+			transaction.Kind = "refund"
+		}
+		transaction.MaximumRefundable = nil
+	}
+	return refund, nil
+}
+
 // List metafields for an order
 func (s *OrderServiceOp) ListMetafields(orderID uint64, options interface{}) ([]Metafield, error) {
 	metafieldService := &MetafieldServiceOp{client: s.client, resource: ordersResourceName, resourceID: orderID}
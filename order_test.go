@@ -1,7 +1,10 @@
 package goshopify
 
 import (
+	"io/ioutil"
+	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -223,6 +226,101 @@ func TestOrderCreate(t *testing.T) {
 	}
 }
 
+func TestOrderCancel(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/orders/1/cancel.json",
+		func(req *http.Request) (*http.Response, error) {
+			body, _ := ioutil.ReadAll(req.Body)
+			expectedBody := `{"restock":true,"reason":"fraud"}`
+			if string(body) != expectedBody {
+				t.Errorf("Order.Cancel sent %s, expected %s", body, expectedBody)
+			}
+			return httpmock.NewStringResponse(200, `{"order":{"id": 1, "cancel_reason": "fraud"}}`), nil
+		})
+
+	o, err := client.Order.Cancel(1, OrderCancelOptions{Restock: true, Reason: "fraud"})
+	if err != nil {
+		t.Errorf("Order.Cancel returned error: %v", err)
+	}
+
+	if o.CancelReason != "fraud" {
+		t.Errorf("Order.Cancel returned cancel reason %s, expected fraud", o.CancelReason)
+	}
+}
+
+func TestOrderSuggestRefund(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/orders/450789469/refunds/calculate.json",
+		httpmock.NewBytesResponder(200, loadFixture("refund_calculate.json")))
+
+	lineItems := []RefundLineItem{{LineItemId: 518995019, Quantity: 1, RestockType: "no_restock"}}
+	refund, err := client.Order.SuggestRefund(450789469, lineItems, &RefundShipping{FullRefund: true})
+	if err != nil {
+		t.Errorf("Order.SuggestRefund returned error: %v", err)
+	}
+
+	shippingAmount := decimal.NewFromFloat(5)
+	if refund.Shipping == nil || !shippingAmount.Equals(*refund.Shipping.Amount) {
+		t.Errorf("Refund.Shipping returned %+v, expected amount %v", refund.Shipping, shippingAmount)
+	}
+	if refund.Shipping.MaximumRefundable != nil {
+		t.Errorf("Refund.Shipping.MaximumRefundable returned %v, expected nil", refund.Shipping.MaximumRefundable)
+	}
+
+	if len(refund.Transactions) != 1 {
+		t.Fatalf("Refund.Transactions has %d transactions, expected 1", len(refund.Transactions))
+	}
+
+	transaction := refund.Transactions[0]
+	cases := []struct {
+		field    string
+		expected interface{}
+		actual   interface{}
+	}{
+		{"Kind", "refund", transaction.Kind},
+		{"Gateway", "bogus", transaction.Gateway},
+		{"ParentID", 801038806, *transaction.ParentID},
+		{"MaximumRefundable", (*decimal.Decimal)(nil), transaction.MaximumRefundable},
+	}
+
+	for _, c := range cases {
+		if c.expected != c.actual {
+			t.Errorf("Transaction.%v returned %v, expected %v", c.field, c.actual, c.expected)
+		}
+	}
+
+	amount := decimal.NewFromFloat(46.94)
+	if !amount.Equals(*transaction.Amount) {
+		t.Errorf("Transaction.Amount returned %v, expected %v", transaction.Amount, amount)
+	}
+}
+
+func TestOrderSuggestRefundEmpty(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var body string
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/orders/450789469/refunds/calculate.json",
+		func(req *http.Request) (*http.Response, error) {
+			data, _ := ioutil.ReadAll(req.Body)
+			body = string(data)
+			return httpmock.NewStringResponse(200, `{}`), nil
+		})
+
+	lineItems := []RefundLineItem{{LineItemId: 518995019, Quantity: 1, RestockType: "return", LocationId: 487838322}}
+	refund, err := client.Order.SuggestRefund(450789469, lineItems, nil)
+	if err == nil || refund != nil {
+		t.Errorf("Order.SuggestRefund returned %v, %v, expected an error for a response without a refund", refund, err)
+	}
+	if !strings.Contains(body, `"location_id":487838322`) {
+		t.Errorf("Order.SuggestRefund sent %s, expected the location of the restock", body)
+	}
+}
+
 func TestOrderListMetafields(t *testing.T) {
 	setup()
 	defer teardown()
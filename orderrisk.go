@@ -0,0 +1,81 @@
+package goshopify
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// OrderRiskService is an interface for interfacing with the order risks
+// endpoints of the Shopify API.
+// See: https://help.shopify.com/api/reference/order_risks
+type OrderRiskService interface {
+	List(uint64, interface{}) ([]OrderRisk, error)
+	Get(uint64, uint64, interface{}) (*OrderRisk, error)
+	Create(uint64, OrderRisk) (*OrderRisk, error)
+	Delete(uint64, uint64) error
+}
+
+// OrderRiskServiceOp handles communication with the order risk related
+// methods of the Shopify API.
+type OrderRiskServiceOp struct {
+	client *Client
+}
+
+// OrderRisk represents a Shopify order risk, a fraud assessment of an order.
+// Score is between 0 and 1 and Recommendation is one of "cancel",
+// "investigate" or "accept".
+type OrderRisk struct {
+	ID              uint64           `json:"id,omitempty"`
+	OrderID         uint64           `json:"order_id,omitempty"`
+	CheckoutID      uint64           `json:"checkout_id,omitempty"`
+	Source          string           `json:"source,omitempty"`
+	Score           *decimal.Decimal `json:"score,omitempty"`
+	Recommendation  string           `json:"recommendation,omitempty"`
+	Display         bool             `json:"display"`
+	CauseCancel     bool             `json:"cause_cancel,omitempty"`
+	Message         string           `json:"message,omitempty"`
+	MerchantMessage string           `json:"merchant_message,omitempty"`
+}
+
+// OrderRiskResource represents the result from the orders/X/risks/Y.json
+// endpoint
+type OrderRiskResource struct {
+	Risk *OrderRisk `json:"risk"`
+}
+
+// OrderRisksResource represents the result from the orders/X/risks.json
+// endpoint
+type OrderRisksResource struct {
+	Risks []OrderRisk `json:"risks"`
+}
+
+// List order risks
+func (s *OrderRiskServiceOp) List(orderID uint64, options interface{}) ([]OrderRisk, error) {
+	path := fmt.Sprintf("%s/%d/risks.json", ordersBasePath, orderID)
+	resource := new(OrderRisksResource)
+	err := s.client.Get(path, resource, options)
+	return resource.Risks, err
+}
+
+// Get individual order risk
+func (s *OrderRiskServiceOp) Get(orderID uint64, riskID uint64, options interface{}) (*OrderRisk, error) {
+	path := fmt.Sprintf("%s/%d/risks/%d.json", ordersBasePath, orderID, riskID)
+	resource := new(OrderRiskResource)
+	err := s.client.Get(path, resource, options)
+	return resource.Risk, err
+}
+
+// Create a new order risk
+func (s *OrderRiskServiceOp) Create(orderID uint64, risk OrderRisk) (*OrderRisk, error) {
+	path := fmt.Sprintf("%s/%d/risks.json", ordersBasePath, orderID)
+	wrappedData := OrderRiskResource{Risk: &risk}
+	resource := new(OrderRiskResource)
+	err := s.client.Post(path, wrappedData, resource)
+	return resource.Risk, err
+}
+
+// Delete an order risk
+func (s *OrderRiskServiceOp) Delete(orderID uint64, riskID uint64) error {
+	return s.client.Delete(fmt.Sprintf("%s/%d/risks/%d.json", ordersBasePath, orderID, riskID))
+}
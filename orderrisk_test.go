@@ -0,0 +1,103 @@
+package goshopify
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+)
+
+func orderRiskTests(t *testing.T, risk OrderRisk) {
+	expectedID := uint64(284138680)
+	if risk.ID != expectedID {
+		t.Errorf("OrderRisk.ID returned %+v, expected %+v", risk.ID, expectedID)
+	}
+
+	expectedOrderID := uint64(450789469)
+	if risk.OrderID != expectedOrderID {
+		t.Errorf("OrderRisk.OrderID returned %+v, expected %+v", risk.OrderID, expectedOrderID)
+	}
+
+	expectedScore := decimal.NewFromFloat(1)
+	if risk.Score == nil || !risk.Score.Equals(expectedScore) {
+		t.Errorf("OrderRisk.Score returned %+v, expected %+v", risk.Score, expectedScore)
+	}
+
+	expectedRecommendation := "cancel"
+	if risk.Recommendation != expectedRecommendation {
+		t.Errorf("OrderRisk.Recommendation returned %+v, expected %+v", risk.Recommendation, expectedRecommendation)
+	}
+
+	if !risk.Display || !risk.CauseCancel {
+		t.Errorf("OrderRisk returned display %v and cause_cancel %v, expected both true", risk.Display, risk.CauseCancel)
+	}
+}
+
+func TestOrderRiskList(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/orders/450789469/risks.json",
+		httpmock.NewBytesResponder(200, loadFixture("orderrisks.json")))
+
+	risks, err := client.OrderRisk.List(450789469, nil)
+	if err != nil {
+		t.Errorf("OrderRisk.List returned error: %v", err)
+	}
+
+	if len(risks) != 1 {
+		t.Fatalf("OrderRisk.List returned %d risks, expected 1", len(risks))
+	}
+	orderRiskTests(t, risks[0])
+}
+
+func TestOrderRiskGet(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/orders/450789469/risks/284138680.json",
+		httpmock.NewBytesResponder(200, loadFixture("orderrisk.json")))
+
+	risk, err := client.OrderRisk.Get(450789469, 284138680, nil)
+	if err != nil {
+		t.Errorf("OrderRisk.Get returned error: %v", err)
+	}
+
+	orderRiskTests(t, *risk)
+}
+
+func TestOrderRiskCreate(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/orders/450789469/risks.json",
+		httpmock.NewBytesResponder(201, loadFixture("orderrisk.json")))
+
+	score := decimal.NewFromFloat(1)
+	risk, err := client.OrderRisk.Create(450789469, OrderRisk{
+		Source:         "External",
+		Score:          &score,
+		Recommendation: "cancel",
+		Display:        true,
+		CauseCancel:    true,
+		Message:        "This order was placed from a proxy IP",
+	})
+	if err != nil {
+		t.Errorf("OrderRisk.Create returned error: %v", err)
+	}
+
+	orderRiskTests(t, *risk)
+}
+
+func TestOrderRiskDelete(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("DELETE", "https://fooshop.myshopify.com/admin/orders/450789469/risks/284138680.json",
+		httpmock.NewStringResponder(200, "{}"))
+
+	err := client.OrderRisk.Delete(450789469, 284138680)
+	if err != nil {
+		t.Errorf("OrderRisk.Delete returned error: %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package goshopify
+
+import (
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const orderSearchQuery = `query orders($first: Int!, $after: String, $query: String) {
+	orders(first: $first, after: $after, query: $query) {
+		pageInfo { hasNextPage endCursor }
+		edges { node {
+			id name email phone note tags test
+			createdAt updatedAt processedAt cancelledAt closedAt
+			displayFinancialStatus displayFulfillmentStatus currencyCode
+			totalPriceSet { shopMoney { amount } }
+			subtotalPriceSet { shopMoney { amount } }
+			totalTaxSet { shopMoney { amount } }
+			customer { id }
+		} }
+	}
+}`
+
+// OrderSearch is a search for orders. The fields that are set must all
+// match, and Query is added as it is for searches the other fields cannot
+// express.
+// See: https://shopify.dev/api/admin-graphql/latest/queries/orders
+type OrderSearch struct {
+	// open, closed, cancelled or not_closed
+	Status string
+	// e.g. paid, pending, refunded or partially_refunded
+	FinancialStatus string
+	// e.g. shipped, partial or unshipped
+	FulfillmentStatus string
+	Tags              []string
+	// The order name, e.g. #1001
+	Name         string
+	Email        string
+	CreatedAtMin time.Time
+	CreatedAtMax time.Time
+	UpdatedAtMin time.Time
+	UpdatedAtMax time.Time
+	Query        string
+}
+
+// String returns the search in the search syntax of the GraphQL Admin API.
+func (s OrderSearch) String() string {
+	terms := searchTerms{}
+	terms.add("status", s.Status)
+	terms.add("financial_status", s.FinancialStatus)
+	terms.add("fulfillment_status", s.FulfillmentStatus)
+	terms.addAll("tag", s.Tags)
+	terms.add("name", s.Name)
+	terms.add("email", s.Email)
+	terms.addTimeRange("created_at", s.CreatedAtMin, s.CreatedAtMax)
+	terms.addTimeRange("updated_at", s.UpdatedAtMin, s.UpdatedAtMax)
+	terms.addRaw(s.Query)
+	return terms.String()
+}
+
+// An order as the GraphQL Admin API returns it
+type orderNode struct {
+	ID                       string       `json:"id"`
+	Name                     string       `json:"name"`
+	Email                    string       `json:"email"`
+	Phone                    string       `json:"phone"`
+	Note                     string       `json:"note"`
+	Tags                     []string     `json:"tags"`
+	Test                     bool         `json:"test"`
+	CreatedAt                *time.Time   `json:"createdAt"`
+	UpdatedAt                *time.Time   `json:"updatedAt"`
+	ProcessedAt              *time.Time   `json:"processedAt"`
+	CancelledAt              *time.Time   `json:"cancelledAt"`
+	ClosedAt                 *time.Time   `json:"closedAt"`
+	DisplayFinancialStatus   string       `json:"displayFinancialStatus"`
+	DisplayFulfillmentStatus string       `json:"displayFulfillmentStatus"`
+	CurrencyCode             string       `json:"currencyCode"`
+	TotalPriceSet            moneyBagJSON `json:"totalPriceSet"`
+	SubtotalPriceSet         moneyBagJSON `json:"subtotalPriceSet"`
+	TotalTaxSet              moneyBagJSON `json:"totalTaxSet"`
+	Customer                 *struct {
+		ID string `json:"id"`
+	} `json:"customer"`
+}
+
+// An amount in the shop's currency as the GraphQL Admin API returns it
+type moneyBagJSON struct {
+	ShopMoney struct {
+		Amount *decimal.Decimal `json:"amount"`
+	} `json:"shopMoney"`
+}
+
+func (n orderNode) order() Order {
+	order := Order{
+		ID:                ParseGID(n.ID),
+		Name:              n.Name,
+		Email:             n.Email,
+		Phone:             n.Phone,
+		Note:              n.Note,
+		Tags:              strings.Join(n.Tags, ", "),
+		Test:              n.Test,
+		CreatedAt:         n.CreatedAt,
+		UpdatedAt:         n.UpdatedAt,
+		ProcessedAt:       n.ProcessedAt,
+		CancelledAt:       n.CancelledAt,
+		ClosedAt:          n.ClosedAt,
+		FinancialStatus:   strings.ToLower(n.DisplayFinancialStatus),
+		FulfillmentStatus: restFulfillmentStatuses[n.DisplayFulfillmentStatus],
+		Currency:          n.CurrencyCode,
+		TotalPrice:        n.TotalPriceSet.ShopMoney.Amount,
+		SubtotalPrice:     n.SubtotalPriceSet.ShopMoney.Amount,
+		TotalTax:          n.TotalTaxSet.ShopMoney.Amount,
+	}
+	if n.Customer != nil {
+		order.Customer = &Customer{ID: ParseGID(n.Customer.ID)}
+	}
+	return order
+}
+
+// The fulfillment statuses of the REST API, which are null for orders that
+// are not fulfilled at all, by their display status in the GraphQL API.
+var restFulfillmentStatuses = map[string]string{
+	"FULFILLED":           "fulfilled",
+	"PARTIALLY_FULFILLED": "partial",
+	"RESTOCKED":           "restocked",
+}
+
+// Search returns a page of the orders matching a search, starting after the
+// given position, and the position after the page. Pass an empty PageInfo for
+// the first page. Orders are returned with the fields the search pages
+// include: their name, contact details, note, tags, times, statuses and
+// totals.
+func (s *OrderServiceOp) Search(search OrderSearch, after PageInfo) ([]Order, PageInfo, error) {
+	variables := map[string]interface{}{"first": searchPageSize, "query": search.String()}
+	if after.EndCursor != "" {
+		variables["after"] = after.EndCursor
+	}
+
+	data := struct {
+		Orders struct {
+			PageInfo PageInfo `json:"pageInfo"`
+			Edges    []struct {
+				Node orderNode `json:"node"`
+			} `json:"edges"`
+		} `json:"orders"`
+	}{}
+	err := s.client.GraphQL.Query(orderSearchQuery, variables, &data)
+	if err != nil {
+		return nil, after, err
+	}
+
+	orders := make([]Order, len(data.Orders.Edges))
+	for i, edge := range data.Orders.Edges {
+		orders[i] = edge.Node.order()
+	}
+	return orders, data.Orders.PageInfo, nil
+}
@@ -0,0 +1,93 @@
+package goshopify
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestOrderSearchString(t *testing.T) {
+	cases := []struct {
+		search   OrderSearch
+		expected string
+	}{
+		{OrderSearch{}, ""},
+		{OrderSearch{Query: "tag:vip OR tag:wholesale"}, "tag:vip OR tag:wholesale"},
+		{OrderSearch{Status: "open", FinancialStatus: "paid"}, "status:open financial_status:paid"},
+		{OrderSearch{Tags: []string{"vip", "needs review"}, Name: "#1001"}, `tag:vip tag:"needs review" name:#1001`},
+		{
+			OrderSearch{
+				CreatedAtMin: time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC),
+				CreatedAtMax: time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC),
+				Query:        "-fulfillment_status:shipped OR risk_level:high",
+			},
+			`created_at:>="2018-01-01T00:00:00Z" created_at:<="2018-02-01T00:00:00Z" (-fulfillment_status:shipped OR risk_level:high)`,
+		},
+	}
+
+	for _, c := range cases {
+		if actual := c.search.String(); actual != c.expected {
+			t.Errorf("OrderSearch.String() returned %q, expected %q", actual, c.expected)
+		}
+	}
+}
+
+func TestOrderSearch(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var variables map[string]interface{}
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/graphql.json",
+		func(req *http.Request) (*http.Response, error) {
+			body := struct {
+				Variables map[string]interface{} `json:"variables"`
+			}{}
+			json.NewDecoder(req.Body).Decode(&body)
+			variables = body.Variables
+			return httpmock.NewBytesResponse(200, loadFixture("order_search.json")), nil
+		})
+
+	search := OrderSearch{FinancialStatus: "partially_refunded", Tags: []string{"vip"}}
+	orders, pageInfo, err := client.Order.Search(search, PageInfo{EndCursor: "abc"})
+	if err != nil {
+		t.Fatalf("Order.Search returned error: %v", err)
+	}
+
+	if variables["query"] != "financial_status:partially_refunded tag:vip" || variables["after"] != "abc" {
+		t.Errorf("Order.Search sent variables %v", variables)
+	}
+
+	expectedPageInfo := PageInfo{HasNextPage: true, EndCursor: "eyJsYXN0X2lkIjo0NTAzNjM2OTE3fQ=="}
+	if pageInfo != expectedPageInfo {
+		t.Errorf("Order.Search returned page info %#v, expected %#v", pageInfo, expectedPageInfo)
+	}
+
+	if len(orders) != 2 {
+		t.Fatalf("Order.Search returned %d orders, expected 2", len(orders))
+	}
+
+	order := orders[0]
+	total := decimal.New(40994, -2)
+	if order.ID != 450789469 || order.Name != "#1001" || order.Tags != "vip, wholesale" {
+		t.Errorf("Order.Search returned order %d %s tagged %q", order.ID, order.Name, order.Tags)
+	}
+	if order.FinancialStatus != "partially_refunded" || order.FulfillmentStatus != "" {
+		t.Errorf("Order.Search returned statuses %s and %s", order.FinancialStatus, order.FulfillmentStatus)
+	}
+	if order.TotalPrice == nil || !order.TotalPrice.Equals(total) {
+		t.Errorf("Order.TotalPrice returned %v, expected %v", order.TotalPrice, total)
+	}
+	if order.Customer == nil || order.Customer.ID != 207119551 {
+		t.Errorf("Order.Customer returned %v, expected customer 207119551", order.Customer)
+	}
+	if orders[1].FulfillmentStatus != "fulfilled" {
+		t.Errorf("Order.Search returned fulfillment status %s, expected fulfilled", orders[1].FulfillmentStatus)
+	}
+	if orders[1].Customer != nil {
+		t.Errorf("Order.Customer returned %v for an order without a customer", orders[1].Customer)
+	}
+}
@@ -0,0 +1,479 @@
+package goshopify
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultOutboxRetries    = 3
+	DefaultOutboxRetryDelay = 2 * time.Second
+)
+
+// OutboxEntry is a write that kept failing and waits to be replayed.
+type OutboxEntry struct {
+	// Assigned by the store, in the order entries are added
+	ID uint64 `json:"id"`
+
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+
+	// Writes with the same key are replayed in the order they were made
+	Key string `json:"key"`
+
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OutboxStore persists the entries of an outbox.
+type OutboxStore interface {
+	// Add stores a new entry and returns it with its ID set
+	Add(entry OutboxEntry) (OutboxEntry, error)
+
+	// List returns the entries in the order of their IDs
+	List() ([]OutboxEntry, error)
+
+	Update(entry OutboxEntry) error
+	Remove(id uint64) error
+}
+
+// QueuedError is returned for a write that failed and was added to the outbox
+// to be replayed later.
+type QueuedError struct {
+	Entry OutboxEntry
+	Err   error
+}
+
+func (e QueuedError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("%s %s queued behind earlier writes to %s", e.Entry.Method, e.Entry.Path, e.Entry.Key)
+	}
+	return fmt.Sprintf("%s %s queued after %d attempts: %v", e.Entry.Method, e.Entry.Path, e.Entry.Attempts, e.Err)
+}
+
+// Outbox makes writes that are retried and, if they keep failing because of
+// rate limits, server errors or network errors, persisted to be replayed
+// later. Writes with the same key, which is their path unless given, are made
+// one at a time, and are queued behind the entries of the key that are
+// waiting, so that writes to the same resource are applied in order.
+//
+// A POST is only retried and queued when it was not made, i.e. it was rate
+// limited or could not connect to Shopify. A POST that got a server error or
+// no response may have created its resource, so its error is returned as it
+// is, and the caller has to find out whether to make it again.
+//
+// Retries and replays draw from the retry budget of the client. A write that
+// is out of budget is queued, and replaying stops until there is budget.
+type Outbox struct {
+	client *Client
+	store  OutboxStore
+
+	// Guards keys
+	mu   sync.Mutex
+	keys map[string]*outboxKey
+
+	// Held while replaying, so that entries are not replayed twice
+	replaying sync.Mutex
+
+	// Attempts made for a write before it is queued
+	Retries    int
+	RetryDelay time.Duration
+
+	// Called for queued writes that Shopify rejected when replayed, e.g.
+	// because the resource was deleted in the meantime. They are removed
+	// from the outbox.
+	Rejected func(entry OutboxEntry, err error)
+}
+
+// NewOutbox returns an outbox making writes with client and persisting the
+// failed ones to store.
+func NewOutbox(client *Client, store OutboxStore) *Outbox {
+	return &Outbox{
+		client:     client,
+		store:      store,
+		keys:       make(map[string]*outboxKey),
+		Retries:    DefaultOutboxRetries,
+		RetryDelay: DefaultOutboxRetryDelay,
+	}
+}
+
+// Post performs a POST request through the outbox.
+func (o *Outbox) Post(path string, data, resource interface{}) error {
+	return o.Do("POST", path, data, resource)
+}
+
+// Put performs a PUT request through the outbox.
+func (o *Outbox) Put(path string, data, resource interface{}) error {
+	return o.Do("PUT", path, data, resource)
+}
+
+// Delete performs a DELETE request through the outbox.
+func (o *Outbox) Delete(path string) error {
+	return o.Do("DELETE", path, nil, nil)
+}
+
+// Do performs a write and saves the result in resource. A write that is
+// queued returns a QueuedError, and its result is discarded when it is
+// replayed. The path of the write is its key.
+func (o *Outbox) Do(method, path string, data, resource interface{}) error {
+	return o.DoWithKey(path, method, path, data, resource)
+}
+
+// DoWithKey performs a write like Do, ordered with the other writes with the
+// given key, e.g. "products/1" for all writes to product 1 and its variants.
+func (o *Outbox) DoWithKey(key, method, path string, data, resource interface{}) error {
+	var body json.RawMessage
+	if data != nil {
+		var err error
+		body, err = json.Marshal(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	entry := OutboxEntry{
+		Method:    method,
+		Path:      path,
+		Body:      body,
+		Key:       key,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	unlock := o.lock(key)
+	defer unlock()
+
+	waiting, err := o.waiting(key)
+	if err != nil {
+		return err
+	}
+	if waiting {
+		entry, err = o.store.Add(entry)
+		if err != nil {
+			return err
+		}
+		return QueuedError{Entry: entry}
+	}
+
+	o.client.budgetRequest()
+	for {
+		entry.Attempts++
+		err = o.send(entry, resource)
+		if !replayable(method, err) {
+			return err
+		}
+		if entry.Attempts >= o.Retries || !o.client.budgetRetry() {
+			break
+		}
+		o.wait(err)
+	}
+
+	entry.LastError = err.Error()
+	entry, storeErr := o.store.Add(entry)
+	if storeErr != nil {
+		return fmt.Errorf("cannot queue %s %s after %v: %v", method, path, err, storeErr)
+	}
+	return QueuedError{Entry: entry, Err: err}
+}
+
+// Replay makes the queued writes again, in order. Writes that fail again stay
+// queued, along with the later writes with the same key, except for POSTs
+// that may have been made, which are passed to Rejected. It returns the
+// number of writes made.
+func (o *Outbox) Replay() (int, error) {
+	o.replaying.Lock()
+	defer o.replaying.Unlock()
+
+	entries, err := o.store.List()
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	blocked := make(map[string]bool)
+	for _, entry := range entries {
+		if blocked[entry.Key] {
+			continue
+		}
+		if !o.client.budgetRetry() {
+			break
+		}
+
+		made, err := o.replay(entry)
+		if err != nil {
+			return replayed, err
+		}
+		if made {
+			replayed++
+		} else {
+			blocked[entry.Key] = true
+		}
+	}
+
+	return replayed, nil
+}
+
+// Make a queued write again while holding its key, and remove it unless it
+// failed again and stays queued.
+func (o *Outbox) replay(entry OutboxEntry) (bool, error) {
+	unlock := o.lock(entry.Key)
+	defer unlock()
+
+	entry.Attempts++
+	err := o.send(entry, nil)
+	if replayable(entry.Method, err) {
+		entry.LastError = err.Error()
+		return false, o.store.Update(entry)
+	}
+
+	if err != nil && o.Rejected != nil {
+		o.Rejected(entry, err)
+	}
+	return true, o.store.Remove(entry.ID)
+}
+
+// The lock of the writes with a key, kept while it is held or waited for
+type outboxKey struct {
+	sync.Mutex
+	users int
+}
+
+// Lock the writes with the key and return the function that unlocks them.
+func (o *Outbox) lock(key string) func() {
+	o.mu.Lock()
+	k, ok := o.keys[key]
+	if !ok {
+		k = &outboxKey{}
+		o.keys[key] = k
+	}
+	k.users++
+	o.mu.Unlock()
+
+	k.Lock()
+	return func() {
+		k.Unlock()
+		o.mu.Lock()
+		k.users--
+		if k.users == 0 {
+			delete(o.keys, key)
+		}
+		o.mu.Unlock()
+	}
+}
+
+// Whether there are queued writes with the key.
+func (o *Outbox) waiting(key string) (bool, error) {
+	entries, err := o.store.List()
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if entry.Key == key {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (o *Outbox) send(entry OutboxEntry, resource interface{}) error {
+	var data interface{}
+	if entry.Body != nil {
+		data = entry.Body
+	}
+	return o.client.CreateAndDo(entry.Method, entry.Path, data, nil, resource)
+}
+
+// Wait before retrying a write, as long as Shopify asks to when rate limited.
+func (o *Outbox) wait(err error) {
+	wait := o.RetryDelay
+	if rateLimitErr, ok := err.(RateLimitError); ok && rateLimitErr.RetryAfter > 0 {
+		wait = time.Duration(rateLimitErr.RetryAfter) * time.Second
+	}
+	time.Sleep(wait)
+}
+
+// Whether a write may be made again after it failed: it failed in a way that
+// may not happen again, and it is not a POST that may have been made.
+func replayable(method string, err error) bool {
+	if method == "POST" {
+		return unsent(err)
+	}
+	return transientError(err)
+}
+
+// Whether a request failed without being made, because it was rate limited,
+// the circuit breaker was open or Shopify could not be connected to.
+func unsent(err error) bool {
+	switch err := err.(type) {
+	case RateLimitError, CircuitOpenError:
+		return true
+	case *url.Error:
+		opErr, ok := err.Err.(*net.OpError)
+		return ok && opErr.Op == "dial"
+	}
+	return false
+}
+
+// Whether a write failed in a way that may not happen again, i.e. it was rate
+// limited, Shopify had a server error, or it timed out or was never sent. Any
+// other error, e.g. a response that cannot be decoded or a body that cannot
+// be encoded, would only fail the same way again.
+func transientError(err error) bool {
+	switch err := err.(type) {
+	case ResponseError:
+		return err.Status == 429 || err.Status >= 500
+	case ResponseDecodingError:
+		return err.Status >= 500
+	case *url.Error:
+		if err.Timeout() {
+			return true
+		}
+	}
+	return unsent(err)
+}
+
+// MemoryOutboxStore keeps outbox entries in memory, e.g. for tests.
+type MemoryOutboxStore struct {
+	mu      sync.Mutex
+	lastID  uint64
+	entries map[uint64]OutboxEntry
+}
+
+// NewMemoryOutboxStore returns an empty store.
+func NewMemoryOutboxStore() *MemoryOutboxStore {
+	return &MemoryOutboxStore{entries: make(map[uint64]OutboxEntry)}
+}
+
+func (s *MemoryOutboxStore) Add(entry OutboxEntry) (OutboxEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastID++
+	entry.ID = s.lastID
+	s.entries[entry.ID] = entry
+	return entry, nil
+}
+
+func (s *MemoryOutboxStore) List() ([]OutboxEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := make([]OutboxEntry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID < entries[j].ID
+	})
+	return entries, nil
+}
+
+func (s *MemoryOutboxStore) Update(entry OutboxEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[entry.ID]; !ok {
+		return fmt.Errorf("no outbox entry %d", entry.ID)
+	}
+	s.entries[entry.ID] = entry
+	return nil
+}
+
+func (s *MemoryOutboxStore) Remove(id uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, id)
+	return nil
+}
+
+// FileOutboxStore keeps outbox entries in a JSON file, which is rewritten on
+// every change.
+type FileOutboxStore struct {
+	path  string
+	mu    sync.Mutex
+	store *MemoryOutboxStore
+}
+
+// NewFileOutboxStore returns a store of the entries in the file at path,
+// which is created when the first entry is added.
+func NewFileOutboxStore(path string) (*FileOutboxStore, error) {
+	s := &FileOutboxStore{path: path, store: NewMemoryOutboxStore()}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []OutboxEntry
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read outbox %v: %v", path, err)
+	}
+	for _, entry := range entries {
+		s.store.entries[entry.ID] = entry
+		if entry.ID > s.store.lastID {
+			s.store.lastID = entry.ID
+		}
+	}
+	return s, nil
+}
+
+func (s *FileOutboxStore) Add(entry OutboxEntry) (OutboxEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, _ = s.store.Add(entry)
+	err := s.save()
+	if err != nil {
+		// The caller is told the write is not queued, so it must not be
+		// replayed either
+		s.store.Remove(entry.ID)
+		return entry, err
+	}
+	return entry, nil
+}
+
+func (s *FileOutboxStore) List() ([]OutboxEntry, error) {
+	return s.store.List()
+}
+
+func (s *FileOutboxStore) Update(entry OutboxEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.store.Update(entry)
+	if err != nil {
+		return err
+	}
+	return s.save()
+}
+
+func (s *FileOutboxStore) Remove(id uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store.Remove(id)
+	return s.save()
+}
+
+// Write the entries to a temporary file and move it in place, so that the
+// file is never partly written.
+func (s *FileOutboxStore) save() error {
+	entries, _ := s.store.List()
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	err = ioutil.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
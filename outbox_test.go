@@ -0,0 +1,306 @@
+package goshopify
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+// Respond to PUTs to the path with the given status codes in turn, and
+// record the bodies of the requests.
+func respondInTurn(path string, statuses ...int) *[]string {
+	bodies := []string{}
+	httpmock.RegisterResponder("PUT", "https://fooshop.myshopify.com/"+path,
+		func(req *http.Request) (*http.Response, error) {
+			body, _ := ioutil.ReadAll(req.Body)
+			bodies = append(bodies, string(body))
+			status := statuses[0]
+			if len(statuses) > 1 {
+				statuses = statuses[1:]
+			}
+			return httpmock.NewStringResponse(status, `{"product": {"id": 1}}`), nil
+		})
+	return &bodies
+}
+
+func TestOutboxDo(t *testing.T) {
+	setup()
+	defer teardown()
+
+	bodies := respondInTurn("admin/products/1.json", 503, 200)
+	outbox := NewOutbox(client, NewMemoryOutboxStore())
+	outbox.RetryDelay = 0
+
+	resource := ProductResource{}
+	err := outbox.Put("admin/products/1.json", map[string]string{"title": "Shirt"}, &resource)
+	if err != nil {
+		t.Fatalf("Outbox.Put returned error: %v", err)
+	}
+	if len(*bodies) != 2 || resource.Product.ID != 1 {
+		t.Errorf("Outbox.Put made %d requests and returned %v, expected 2 requests and product 1", len(*bodies), resource.Product)
+	}
+}
+
+func TestOutboxDoRejected(t *testing.T) {
+	setup()
+	defer teardown()
+
+	bodies := respondInTurn("admin/products/1.json", 422)
+	store := NewMemoryOutboxStore()
+	outbox := NewOutbox(client, store)
+
+	err := outbox.Put("admin/products/1.json", map[string]string{"title": ""}, nil)
+	if _, ok := err.(ResponseError); !ok {
+		t.Errorf("Outbox.Put returned %v, expected a ResponseError", err)
+	}
+	entries, _ := store.List()
+	if len(*bodies) != 1 || len(entries) != 0 {
+		t.Errorf("Outbox.Put made %d requests and queued %d writes, expected 1 and 0", len(*bodies), len(entries))
+	}
+}
+
+func TestOutboxReplay(t *testing.T) {
+	setup()
+	defer teardown()
+
+	bodies := respondInTurn("admin/products/1.json", 503, 503, 503, 200)
+	other := respondInTurn("admin/products/2.json", 503, 200)
+	outbox := NewOutbox(client, NewMemoryOutboxStore())
+	outbox.RetryDelay = 0
+	outbox.Retries = 2
+
+	err := outbox.Put("admin/products/1.json", map[string]string{"title": "first"}, nil)
+	queued, ok := err.(QueuedError)
+	if !ok || queued.Entry.Attempts != 2 {
+		t.Fatalf("Outbox.Put returned %v, expected a QueuedError after 2 attempts", err)
+	}
+
+	// Queued behind the first write without being attempted
+	err = outbox.Put("admin/products/1.json", map[string]string{"title": "second"}, nil)
+	if queued, ok := err.(QueuedError); !ok || queued.Err != nil {
+		t.Fatalf("Outbox.Put returned %v, expected a QueuedError behind the first write", err)
+	}
+	if len(*bodies) != 2 {
+		t.Fatalf("Outbox.Put attempted a write with writes queued before it")
+	}
+
+	outbox.Retries = 1
+	outbox.Put("admin/products/2.json", map[string]string{"title": "other"}, nil)
+
+	// The first write fails again and holds back the second, the other
+	// product goes through
+	replayed, err := outbox.Replay()
+	if err != nil || replayed != 1 {
+		t.Fatalf("Outbox.Replay returned %d, %v, expected 1 write made", replayed, err)
+	}
+	if len(*other) != 2 || len(*bodies) != 3 {
+		t.Errorf("Outbox.Replay made %d and %d requests, expected 2 and 3", len(*other), len(*bodies))
+	}
+
+	replayed, err = outbox.Replay()
+	if err != nil || replayed != 2 {
+		t.Fatalf("Outbox.Replay returned %d, %v, expected 2 writes made", replayed, err)
+	}
+	expected := []string{`{"title":"first"}`, `{"title":"second"}`}
+	actual := (*bodies)[len(*bodies)-2:]
+	if actual[0] != expected[0] || actual[1] != expected[1] {
+		t.Errorf("Outbox.Replay sent %v, expected %v", actual, expected)
+	}
+}
+
+func TestOutboxReplayRejected(t *testing.T) {
+	setup()
+	defer teardown()
+
+	respondInTurn("admin/products/1.json", 503, 404)
+	store := NewMemoryOutboxStore()
+	outbox := NewOutbox(client, store)
+	outbox.Retries = 1
+
+	var rejected []OutboxEntry
+	outbox.Rejected = func(entry OutboxEntry, err error) {
+		rejected = append(rejected, entry)
+	}
+
+	outbox.Put("admin/products/1.json", map[string]string{"title": "Shirt"}, nil)
+	outbox.Replay()
+
+	entries, _ := store.List()
+	if len(rejected) != 1 || len(entries) != 0 {
+		t.Errorf("Outbox.Replay rejected %d writes and left %d, expected 1 and 0", len(rejected), len(entries))
+	}
+}
+
+func TestOutboxDoInOrder(t *testing.T) {
+	setup()
+	defer teardown()
+
+	bodies := respondInTurn("admin/products/1.json", 503, 200)
+	outbox := NewOutbox(client, NewMemoryOutboxStore())
+	outbox.RetryDelay = 50 * time.Millisecond
+
+	done := make(chan error)
+	go func() {
+		done <- outbox.Put("admin/products/1.json", map[string]string{"title": "first"}, nil)
+	}()
+
+	// Made while the first write waits to be retried
+	time.Sleep(10 * time.Millisecond)
+	err := outbox.Put("admin/products/1.json", map[string]string{"title": "second"}, nil)
+	if err != nil || <-done != nil {
+		t.Fatalf("Outbox.Put returned error: %v", err)
+	}
+
+	expected := []string{`{"title":"first"}`, `{"title":"first"}`, `{"title":"second"}`}
+	if !reflect.DeepEqual(*bodies, expected) {
+		t.Errorf("Outbox.Put sent %v, expected %v", *bodies, expected)
+	}
+}
+
+func TestOutboxDoWithKey(t *testing.T) {
+	setup()
+	defer teardown()
+
+	respondInTurn("admin/products/1.json", 503)
+	variant := respondInTurn("admin/variants/2.json", 200)
+	store := NewMemoryOutboxStore()
+	outbox := NewOutbox(client, store)
+	outbox.Retries = 1
+
+	outbox.DoWithKey("products/1", "PUT", "admin/products/1.json", map[string]string{"title": "Shirt"}, nil)
+	err := outbox.DoWithKey("products/1", "PUT", "admin/variants/2.json", map[string]string{"sku": "SHIRT"}, nil)
+	if queued, ok := err.(QueuedError); !ok || queued.Err != nil || queued.Entry.Key != "products/1" {
+		t.Errorf("Outbox.DoWithKey returned %v, expected a QueuedError behind the write to the product", err)
+	}
+	if len(*variant) != 0 {
+		t.Errorf("Outbox.DoWithKey attempted a write with writes of its key queued before it")
+	}
+}
+
+func TestOutboxPost(t *testing.T) {
+	setup()
+	defer teardown()
+
+	statuses := []int{503, 429, 201}
+	requests := 0
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/products.json",
+		func(req *http.Request) (*http.Response, error) {
+			status := statuses[requests]
+			requests++
+			return httpmock.NewStringResponse(status, `{"product": {"id": 1}}`), nil
+		})
+	store := NewMemoryOutboxStore()
+	outbox := NewOutbox(client, store)
+	outbox.RetryDelay = 0
+
+	// The product may have been created, so the POST is neither retried nor
+	// queued
+	err := outbox.Post("admin/products.json", map[string]string{"title": "Shirt"}, nil)
+	entries, _ := store.List()
+	if _, ok := err.(ResponseError); !ok || requests != 1 || len(entries) != 0 {
+		t.Errorf("Outbox.Post returned %v after %d requests with %d writes queued, expected a ResponseError after 1 request", err, requests, len(entries))
+	}
+
+	// Rate limited POSTs were not made and are retried
+	err = outbox.Post("admin/products.json", map[string]string{"title": "Shirt"}, nil)
+	if err != nil || requests != 3 {
+		t.Errorf("Outbox.Post returned %v after %d requests, expected success after 3", err, requests)
+	}
+}
+
+func TestFileOutboxStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "outbox.json")
+
+	store, err := NewFileOutboxStore(path)
+	if err != nil {
+		t.Fatalf("NewFileOutboxStore returned error: %v", err)
+	}
+	first, _ := store.Add(OutboxEntry{Method: "PUT", Path: "admin/products/1.json", Body: []byte(`{"title":"Shirt"}`)})
+	second, _ := store.Add(OutboxEntry{Method: "DELETE", Path: "admin/products/2.json"})
+	first.Attempts = 2
+	store.Update(first)
+
+	store, err = NewFileOutboxStore(path)
+	if err != nil {
+		t.Fatalf("NewFileOutboxStore returned error: %v", err)
+	}
+	entries, _ := store.List()
+	if len(entries) != 2 || entries[0].Attempts != 2 || string(entries[0].Body) != `{"title":"Shirt"}` || entries[1].ID != second.ID {
+		t.Fatalf("NewFileOutboxStore loaded %v, expected the two entries added", entries)
+	}
+
+	store.Remove(first.ID)
+	third, _ := store.Add(OutboxEntry{Method: "POST", Path: "admin/products.json"})
+	if third.ID <= second.ID {
+		t.Errorf("FileOutboxStore.Add assigned ID %d after %d", third.ID, second.ID)
+	}
+}
+
+func TestFileOutboxStoreAddFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewFileOutboxStore(filepath.Join(dir, "outbox.json"))
+	if err != nil {
+		t.Fatalf("NewFileOutboxStore returned error: %v", err)
+	}
+	os.RemoveAll(dir)
+
+	_, err = store.Add(OutboxEntry{Method: "PUT", Path: "admin/products/1.json"})
+	if err == nil {
+		t.Fatal("FileOutboxStore.Add returned nil error for a file that cannot be written")
+	}
+	entries, _ := store.List()
+	if len(entries) != 0 {
+		t.Errorf("FileOutboxStore.Add kept %v after failing to save it, expected no entries", entries)
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestTransientError(t *testing.T) {
+	cases := []struct {
+		err       error
+		transient bool
+	}{
+		{nil, false},
+		{RateLimitError{ResponseError: ResponseError{Status: 429}}, true},
+		{ResponseError{Status: 429}, true},
+		{ResponseError{Status: 503}, true},
+		{ResponseError{Status: 422}, false},
+		{ResponseDecodingError{Status: 502}, true},
+		{ResponseDecodingError{Status: 200}, false},
+		{CircuitOpenError{}, true},
+		{&url.Error{Op: "Put", URL: "https://fooshop.myshopify.com", Err: timeoutError{}}, true},
+		{&url.Error{Op: "Put", URL: "https://fooshop.myshopify.com", Err: &net.OpError{Op: "dial", Err: errors.New("refused")}}, true},
+		{&url.Error{Op: "Put", URL: "https://fooshop.myshopify.com", Err: errors.New("stopped after 10 redirects")}, false},
+		{&json.UnsupportedValueError{Str: "NaN"}, false},
+		{errors.New("unknown"), false},
+	}
+
+	for _, c := range cases {
+		if transientError(c.err) != c.transient {
+			t.Errorf("transientError(%#v) returned %v, expected %v", c.err, !c.transient, c.transient)
+		}
+	}
+}
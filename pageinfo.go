@@ -0,0 +1,54 @@
+package goshopify
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// PageInfo is a position in a paginated list: the end cursor of a page of a
+// GraphQL connection, or for REST endpoints paged by ascending id, the id of
+// the last record listed. It can be saved with String and restored with
+// ParsePageInfo, so that a long export can continue where it left off after a
+// restart.
+type PageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor,omitempty"`
+	SinceID     uint64 `json:"sinceId,omitempty"`
+
+	// The list the position is in, for positions in exports of several
+	// lists, e.g. "products"
+	Resource string `json:"resource,omitempty"`
+}
+
+// String returns the position as an opaque string for ParsePageInfo.
+func (p PageInfo) String() string {
+	data, _ := json.Marshal(p)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParsePageInfo parses a position returned by PageInfo.String. The empty
+// string is the start of a list.
+func ParsePageInfo(s string) (PageInfo, error) {
+	p := PageInfo{}
+	if s == "" {
+		return PageInfo{HasNextPage: true}, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(data, &p)
+	}
+	if err != nil {
+		return p, fmt.Errorf("invalid page info %q", s)
+	}
+	return p, nil
+}
+
+// ListOptions returns options to list the page of a REST endpoint after the
+// position, ordered by id.
+func (p PageInfo) ListOptions(options ListOptions) ListOptions {
+	options.Page = 0
+	options.SinceID = int(p.SinceID)
+	return options
+}
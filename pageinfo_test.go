@@ -0,0 +1,45 @@
+package goshopify
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPageInfoString(t *testing.T) {
+	cases := []PageInfo{
+		{HasNextPage: true, EndCursor: "eyJsYXN0X2lkIjo0fQ=="},
+		{HasNextPage: true, SinceID: 1234},
+		{},
+	}
+
+	for _, c := range cases {
+		actual, err := ParsePageInfo(c.String())
+		if err != nil {
+			t.Errorf("ParsePageInfo(%v) returned error %v", c, err)
+		}
+		if actual != c {
+			t.Errorf("ParsePageInfo(%v) returned %#v, expected %#v", c, actual, c)
+		}
+	}
+}
+
+func TestParsePageInfo(t *testing.T) {
+	p, err := ParsePageInfo("")
+	if err != nil || p != (PageInfo{HasNextPage: true}) {
+		t.Errorf("ParsePageInfo(\"\") returned %#v, %v, expected the start of a list", p, err)
+	}
+
+	_, err = ParsePageInfo("not page info")
+	if err == nil {
+		t.Errorf("ParsePageInfo did not return an error for invalid page info")
+	}
+}
+
+func TestPageInfoListOptions(t *testing.T) {
+	p := PageInfo{HasNextPage: true, SinceID: 1234}
+	actual := p.ListOptions(ListOptions{Page: 3, Limit: 250, Fields: "id"})
+	expected := ListOptions{SinceID: 1234, Limit: 250, Fields: "id"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("PageInfo.ListOptions returned %#v, expected %#v", actual, expected)
+	}
+}
@@ -18,6 +18,7 @@ type ProductService interface {
 	Create(Product) (*Product, error)
 	Update(Product) (*Product, error)
 	Delete(uint64) error
+	Search(ProductSearch, PageInfo) ([]Product, PageInfo, error)
 
 	// MetafieldsService used for Product resource to communicate with Metafields resource
 	MetafieldsService
@@ -0,0 +1,169 @@
+package goshopify
+
+import (
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// The products per page of a search and the variants of each product. A page
+// of products costs about the products times their variants, which is kept
+// under the cost of 1000 that Shopify allows for a single query.
+const (
+	productSearchPageSize = 9
+	productSearchVariants = 100
+)
+
+const productSearchQuery = `query products($first: Int!, $variants: Int!, $after: String, $query: String) {
+	products(first: $first, after: $after, query: $query) {
+		pageInfo { hasNextPage endCursor }
+		edges { node {
+			id title handle vendor productType descriptionHtml tags templateSuffix
+			createdAt updatedAt publishedAt
+			variants(first: $variants) {
+				edges { node {
+					id title sku barcode position price compareAtPrice
+					inventoryQuantity inventoryPolicy taxable createdAt updatedAt
+				} }
+			}
+		} }
+	}
+}`
+
+// ProductSearch is a search for products. The fields that are set must all
+// match, and Query is added as it is for searches the other fields cannot
+// express. A product matches a SKU or barcode when one of its variants has
+// it.
+// See: https://shopify.dev/api/admin-graphql/latest/queries/products
+type ProductSearch struct {
+	Vendor      string
+	ProductType string
+	Tags        []string
+	// active, archived or draft
+	Status  string
+	SKU     string
+	Barcode string
+	// Bounds of the inventory of all variants together, nil for none
+	InventoryTotalMin *int
+	InventoryTotalMax *int
+	Query             string
+}
+
+// String returns the search in the search syntax of the GraphQL Admin API.
+func (s ProductSearch) String() string {
+	terms := searchTerms{}
+	terms.add("vendor", s.Vendor)
+	terms.add("product_type", s.ProductType)
+	terms.addAll("tag", s.Tags)
+	terms.add("status", s.Status)
+	terms.add("sku", s.SKU)
+	terms.add("barcode", s.Barcode)
+	terms.addIntRange("inventory_total", s.InventoryTotalMin, s.InventoryTotalMax)
+	terms.addRaw(s.Query)
+	return terms.String()
+}
+
+// A product as the GraphQL Admin API returns it
+type productNode struct {
+	ID              string     `json:"id"`
+	Title           string     `json:"title"`
+	Handle          string     `json:"handle"`
+	Vendor          string     `json:"vendor"`
+	ProductType     string     `json:"productType"`
+	DescriptionHTML string     `json:"descriptionHtml"`
+	Tags            []string   `json:"tags"`
+	TemplateSuffix  string     `json:"templateSuffix"`
+	CreatedAt       *time.Time `json:"createdAt"`
+	UpdatedAt       *time.Time `json:"updatedAt"`
+	PublishedAt     *time.Time `json:"publishedAt"`
+	Variants        struct {
+		Edges []struct {
+			Node struct {
+				ID                string           `json:"id"`
+				Title             string           `json:"title"`
+				Sku               string           `json:"sku"`
+				Barcode           string           `json:"barcode"`
+				Position          int              `json:"position"`
+				Price             *decimal.Decimal `json:"price"`
+				CompareAtPrice    *decimal.Decimal `json:"compareAtPrice"`
+				InventoryQuantity int              `json:"inventoryQuantity"`
+				InventoryPolicy   string           `json:"inventoryPolicy"`
+				Taxable           bool             `json:"taxable"`
+				CreatedAt         *time.Time       `json:"createdAt"`
+				UpdatedAt         *time.Time       `json:"updatedAt"`
+			} `json:"node"`
+		} `json:"edges"`
+	} `json:"variants"`
+}
+
+func (n productNode) product() Product {
+	product := Product{
+		ID:             ParseGID(n.ID),
+		Title:          n.Title,
+		BodyHTML:       n.DescriptionHTML,
+		Vendor:         n.Vendor,
+		ProductType:    n.ProductType,
+		Handle:         n.Handle,
+		CreatedAt:      n.CreatedAt,
+		UpdatedAt:      n.UpdatedAt,
+		PublishedAt:    n.PublishedAt,
+		Tags:           strings.Join(n.Tags, ", "),
+		TemplateSuffix: n.TemplateSuffix,
+		Variants:       make([]Variant, len(n.Variants.Edges)),
+	}
+	for i, edge := range n.Variants.Edges {
+		variant := edge.Node
+		product.Variants[i] = Variant{
+			ID:                ParseGID(variant.ID),
+			ProductID:         int(product.ID),
+			Title:             variant.Title,
+			Sku:               variant.Sku,
+			Barcode:           variant.Barcode,
+			Position:          variant.Position,
+			Price:             variant.Price,
+			CompareAtPrice:    variant.CompareAtPrice,
+			InventoryQuantity: variant.InventoryQuantity,
+			InventoryPolicy:   strings.ToLower(variant.InventoryPolicy),
+			Taxable:           variant.Taxable,
+			CreatedAt:         variant.CreatedAt,
+			UpdatedAt:         variant.UpdatedAt,
+		}
+	}
+	return product
+}
+
+// Search returns a page of the products matching a search, starting after
+// the given position, and the position after the page. Pass an empty
+// PageInfo for the first page. Products are returned with their first 100
+// variants, and without options, images or metafields; variants past the
+// first 100 are left out.
+func (s *ProductServiceOp) Search(search ProductSearch, after PageInfo) ([]Product, PageInfo, error) {
+	variables := map[string]interface{}{
+		"first":    productSearchPageSize,
+		"variants": productSearchVariants,
+		"query":    search.String(),
+	}
+	if after.EndCursor != "" {
+		variables["after"] = after.EndCursor
+	}
+
+	data := struct {
+		Products struct {
+			PageInfo PageInfo `json:"pageInfo"`
+			Edges    []struct {
+				Node productNode `json:"node"`
+			} `json:"edges"`
+		} `json:"products"`
+	}{}
+	err := s.client.GraphQL.Query(productSearchQuery, variables, &data)
+	if err != nil {
+		return nil, after, err
+	}
+
+	products := make([]Product, len(data.Products.Edges))
+	for i, edge := range data.Products.Edges {
+		products[i] = edge.Node.product()
+	}
+	return products, data.Products.PageInfo, nil
+}
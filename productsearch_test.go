@@ -0,0 +1,86 @@
+package goshopify
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestProductSearchString(t *testing.T) {
+	min, max := 0, 5
+
+	cases := []struct {
+		search   ProductSearch
+		expected string
+	}{
+		{ProductSearch{}, ""},
+		{ProductSearch{SKU: "IPOD2008PINK"}, "sku:IPOD2008PINK"},
+		{ProductSearch{Vendor: "Apple", ProductType: "Cult Products", Status: "active"}, `vendor:Apple product_type:"Cult Products" status:active`},
+		{ProductSearch{Tags: []string{"sale"}, InventoryTotalMax: &max}, "tag:sale inventory_total:<=5"},
+		{ProductSearch{Barcode: "1234_pink", InventoryTotalMin: &min, InventoryTotalMax: &max}, "barcode:1234_pink inventory_total:>=0 inventory_total:<=5"},
+	}
+
+	for _, c := range cases {
+		if actual := c.search.String(); actual != c.expected {
+			t.Errorf("ProductSearch.String() returned %q, expected %q", actual, c.expected)
+		}
+	}
+}
+
+func TestProductSearch(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var variables map[string]interface{}
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/api/graphql.json",
+		func(req *http.Request) (*http.Response, error) {
+			body := struct {
+				Variables map[string]interface{} `json:"variables"`
+			}{}
+			json.NewDecoder(req.Body).Decode(&body)
+			variables = body.Variables
+			return httpmock.NewBytesResponse(200, loadFixture("product_search.json")), nil
+		})
+
+	products, pageInfo, err := client.Product.Search(ProductSearch{SKU: "IPOD2008PINK"}, PageInfo{})
+	if err != nil {
+		t.Fatalf("Product.Search returned error: %v", err)
+	}
+
+	if variables["query"] != "sku:IPOD2008PINK" {
+		t.Errorf("Product.Search sent query %v, expected sku:IPOD2008PINK", variables["query"])
+	}
+	// Shopify rejects queries that may cost more than 1000
+	first, _ := variables["first"].(float64)
+	variants, _ := variables["variants"].(float64)
+	if first <= 0 || variants <= 0 || first*(variants+1) > 1000 {
+		t.Errorf("Product.Search asked for %v products with %v variants each, which costs more than 1000", first, variants)
+	}
+	if _, ok := variables["after"]; ok {
+		t.Errorf("Product.Search sent after %v for the first page", variables["after"])
+	}
+	if pageInfo.HasNextPage {
+		t.Errorf("Product.Search returned page info %#v, expected the last page", pageInfo)
+	}
+
+	if len(products) != 1 || len(products[0].Variants) != 1 {
+		t.Fatalf("Product.Search returned %v, expected one product with one variant", products)
+	}
+
+	product := products[0]
+	if product.ID != 632910392 || product.Vendor != "Apple" || product.Tags != "Emotive, Flash Memory" {
+		t.Errorf("Product.Search returned product %d by %s tagged %q", product.ID, product.Vendor, product.Tags)
+	}
+
+	variant := product.Variants[0]
+	price := decimal.New(199, 0)
+	if variant.ID != 808950810 || variant.ProductID != 632910392 || variant.Sku != "IPOD2008PINK" {
+		t.Errorf("Product.Search returned variant %d of product %d with SKU %s", variant.ID, variant.ProductID, variant.Sku)
+	}
+	if variant.Price == nil || !variant.Price.Equals(price) || variant.InventoryPolicy != "continue" {
+		t.Errorf("Product.Search returned variant priced %v with inventory policy %s", variant.Price, variant.InventoryPolicy)
+	}
+}
@@ -0,0 +1,144 @@
+package goshopify
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ReportDayFormat is the layout of the keys of OrderReport.SalesByDay.
+const ReportDayFormat = "2006-01-02"
+
+// OrderReport aggregates the sales of a set of orders. All amounts are in
+// the shop's currency and test orders are left out.
+//
+// GrossSales is the sum of line item prices times quantities, Discounts the
+// sum of the orders' total discounts and Refunds the sum of the subtotals of
+// refunded line items. NetSales is GrossSales less Discounts and Refunds.
+// Refunded shipping and the tax of refunds are not sales, and are summed up
+// in RefundedShipping and RefundedTax.
+//
+// UnitsBySKU counts the units sold of each SKU, less refunded units.
+// SalesByDay holds the net sales per day in the report's location: sales
+// count on the day the order was created and refunds on the day they were
+// made.
+type OrderReport struct {
+	Location   *time.Location
+	Orders     int
+	GrossSales decimal.Decimal
+	Discounts  decimal.Decimal
+	Refunds    decimal.Decimal
+	NetSales   decimal.Decimal
+	UnitsBySKU map[string]int
+	SalesByDay map[string]decimal.Decimal
+
+	RefundedShipping decimal.Decimal
+	RefundedTax      decimal.Decimal
+}
+
+// NewOrderReport returns an empty report that buckets days in the given
+// location, usually the shop's timezone.
+func NewOrderReport(location *time.Location) *OrderReport {
+	if location == nil {
+		location = time.UTC
+	}
+	return &OrderReport{
+		Location:   location,
+		UnitsBySKU: make(map[string]int),
+		SalesByDay: make(map[string]decimal.Decimal),
+	}
+}
+
+// ReportOrders lists every order created between from and to and returns a
+// report of them, bucketed by day in the shop's timezone. It fails if the shop
+// has no timezone or it is not known to the time package.
+func ReportOrders(client *Client, from, to time.Time) (*OrderReport, error) {
+	shop, err := client.Shop.Get(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// LoadLocation takes an empty name to be UTC
+	if shop.IanaTimezone == "" {
+		return nil, errors.New("cannot load the timezone of the shop: the shop has no timezone")
+	}
+	location, err := time.LoadLocation(shop.IanaTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load the timezone of the shop: %v", err)
+	}
+
+	report := NewOrderReport(location)
+	options := ListOptions{CreatedAtMin: from, CreatedAtMax: to}
+	err = listAll(client, syncResources[ordersResourceName].list, options, func(item syncItem, _ PageInfo) error {
+		report.Add(*item.resource.(*Order))
+		return nil
+	})
+	return report, err
+}
+
+// Add an order to the report.
+func (r *OrderReport) Add(order Order) {
+	if order.Test {
+		return
+	}
+	r.Orders++
+
+	skus := make(map[uint64]string)
+	sales := decimal.Zero
+	for _, lineItem := range order.LineItems {
+		skus[lineItem.ID] = lineItem.SKU
+		r.UnitsBySKU[lineItem.SKU] += lineItem.Quantity
+		if lineItem.Price != nil {
+			sales = sales.Add(lineItem.Price.Mul(decimal.New(int64(lineItem.Quantity), 0)))
+		}
+	}
+	r.GrossSales = r.GrossSales.Add(sales)
+
+	if order.TotalDiscounts != nil {
+		r.Discounts = r.Discounts.Add(*order.TotalDiscounts)
+		sales = sales.Sub(*order.TotalDiscounts)
+	}
+	r.NetSales = r.NetSales.Add(sales)
+	r.addToDay(order.CreatedAt, sales)
+
+	for _, refund := range order.Refunds {
+		for _, refundLineItem := range refund.RefundLineItems {
+			sku, ok := skus[uint64(refundLineItem.LineItemId)]
+			if !ok && refundLineItem.LineItem != nil {
+				sku = refundLineItem.LineItem.SKU
+			}
+			r.UnitsBySKU[sku] -= refundLineItem.Quantity
+
+			if refundLineItem.Subtotal != nil {
+				r.Refunds = r.Refunds.Add(*refundLineItem.Subtotal)
+				r.NetSales = r.NetSales.Sub(*refundLineItem.Subtotal)
+				r.addToDay(refund.CreatedAt, refundLineItem.Subtotal.Neg())
+			}
+			if refundLineItem.TotalTax != nil {
+				r.RefundedTax = r.RefundedTax.Add(*refundLineItem.TotalTax)
+			}
+		}
+
+		for _, adjustment := range refund.OrderAdjustments {
+			if adjustment.Kind != "shipping_refund" {
+				continue
+			}
+			if adjustment.Amount != nil {
+				r.RefundedShipping = r.RefundedShipping.Sub(*adjustment.Amount)
+			}
+			if adjustment.TaxAmount != nil {
+				r.RefundedTax = r.RefundedTax.Sub(*adjustment.TaxAmount)
+			}
+		}
+	}
+}
+
+func (r *OrderReport) addToDay(t *time.Time, amount decimal.Decimal) {
+	if t == nil {
+		return
+	}
+	day := t.In(r.Location).Format(ReportDayFormat)
+	r.SalesByDay[day] = r.SalesByDay[day].Add(amount)
+}
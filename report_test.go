@@ -0,0 +1,139 @@
+package goshopify
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestReportOrders(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/shop.json",
+		httpmock.NewBytesResponder(200, loadFixture("shop.json")))
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/orders.json?created_at_max=2017-01-03T00%3A00%3A00Z&created_at_min=2017-01-01T00%3A00%3A00Z&limit=250&status=any",
+		httpmock.NewStringResponder(200, `{"orders": [
+			{
+				"id": 1,
+				"created_at": "2017-01-02T03:00:00Z",
+				"total_discounts": "5.00",
+				"line_items": [
+					{"id": 11, "sku": "SHIRT", "quantity": 2, "price": "10.00"},
+					{"id": 12, "sku": "HAT", "quantity": 1, "price": "15.00"}
+				],
+				"refunds": [{
+					"created_at": "2017-01-02T15:00:00Z",
+					"refund_line_items": [{"line_item_id": 11, "quantity": 1, "subtotal": "8.00", "total_tax": "0.80"}],
+					"order_adjustments": [
+						{"kind": "shipping_refund", "amount": "-4.00", "tax_amount": "-0.40"},
+						{"kind": "refund_discrepancy", "amount": "-1.00", "tax_amount": "0.00"}
+					],
+					"transactions": [
+						{"kind": "refund", "status": "success", "amount": "14.20"},
+						{"kind": "refund", "status": "failure", "amount": "14.20"}
+					]
+				}]
+			},
+			{
+				"id": 2,
+				"created_at": "2017-01-02T12:00:00Z",
+				"line_items": [{"id": 21, "sku": "SHIRT", "quantity": 1, "price": "10.00"}]
+			},
+			{
+				"id": 3,
+				"test": true,
+				"created_at": "2017-01-02T12:00:00Z",
+				"line_items": [{"id": 31, "sku": "SHIRT", "quantity": 1, "price": "10.00"}]
+			}
+		]}`))
+
+	from := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2017, 1, 3, 0, 0, 0, 0, time.UTC)
+	report, err := ReportOrders(client, from, to)
+	if err != nil {
+		t.Fatalf("ReportOrders returned error: %v", err)
+	}
+
+	if report.Location.String() != "America/New_York" {
+		t.Errorf("OrderReport.Location is %v, expected America/New_York", report.Location)
+	}
+
+	cases := []struct {
+		field    string
+		expected interface{}
+		actual   interface{}
+	}{
+		{"Orders", 2, report.Orders},
+		{"GrossSales", "45", report.GrossSales.String()},
+		{"Discounts", "5", report.Discounts.String()},
+		{"Refunds", "8", report.Refunds.String()},
+		{"NetSales", "32", report.NetSales.String()},
+		{"RefundedShipping", "4", report.RefundedShipping.String()},
+		{"RefundedTax", "1.2", report.RefundedTax.String()},
+		{"UnitsBySKU", map[string]int{"SHIRT": 2, "HAT": 1}, report.UnitsBySKU},
+	}
+
+	for _, c := range cases {
+		if !reflect.DeepEqual(c.expected, c.actual) {
+			t.Errorf("OrderReport.%v is %v, expected %v", c.field, c.actual, c.expected)
+		}
+	}
+
+	// The first order was placed on the evening of January 1st in New York
+	expectedDays := map[string]string{"2017-01-01": "30", "2017-01-02": "2"}
+	days := make(map[string]string)
+	for day, sales := range report.SalesByDay {
+		days[day] = sales.String()
+	}
+	if !reflect.DeepEqual(days, expectedDays) {
+		t.Errorf("OrderReport.SalesByDay is %v, expected %v", days, expectedDays)
+	}
+}
+
+func TestReportOrdersUnknownTimezone(t *testing.T) {
+	setup()
+	defer teardown()
+
+	for _, timezone := range []string{"Mars/Olympus_Mons", ""} {
+		shop := strings.Replace(string(loadFixture("shop.json")), `America\/New_York`, timezone, 1)
+		httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/shop.json",
+			httpmock.NewStringResponder(200, shop))
+
+		_, err := ReportOrders(client, time.Time{}, time.Time{})
+		if err == nil || !strings.Contains(err.Error(), "timezone") {
+			t.Errorf("ReportOrders returned %v for timezone %q, expected an error about the timezone", err, timezone)
+		}
+	}
+}
+
+func TestOrderReportAdd(t *testing.T) {
+	report := NewOrderReport(nil)
+
+	createdAt := time.Date(2017, 1, 1, 23, 0, 0, 0, time.UTC)
+	price := decimal.NewFromFloat(2.5)
+	report.Add(Order{
+		CreatedAt: &createdAt,
+		LineItems: []LineItem{{ID: 1, SKU: "PEN", Quantity: 4, Price: &price}},
+		Refunds: []Refund{{
+			RefundLineItems: []RefundLineItem{{Quantity: 1, LineItem: &LineItem{SKU: "PEN"}}},
+		}},
+	})
+
+	if report.Location != time.UTC {
+		t.Errorf("OrderReport.Location is %v, expected UTC", report.Location)
+	}
+	if report.GrossSales.String() != "10" {
+		t.Errorf("OrderReport.GrossSales is %v, expected 10", report.GrossSales)
+	}
+	if report.UnitsBySKU["PEN"] != 3 {
+		t.Errorf("OrderReport.UnitsBySKU[PEN] is %v, expected 3", report.UnitsBySKU["PEN"])
+	}
+	if sales := report.SalesByDay["2017-01-01"]; sales.String() != "10" {
+		t.Errorf("OrderReport.SalesByDay[2017-01-01] is %v, expected 10", sales)
+	}
+}
@@ -0,0 +1,37 @@
+package goshopify
+
+// RetryBudget limits retries to a share of the requests made, so that they
+// cannot make an outage worse. The Budget of the errors subpackage is one.
+type RetryBudget interface {
+	// Request adds a request to the budget
+	Request()
+
+	// Retry reports whether a retry is within the budget, and takes it out
+	// of the budget if it is
+	Retry() bool
+}
+
+// WithRetryBudget makes the retries of FetchAll, Outbox and
+// BulkOperationReader draw from budget, which can be shared with other
+// clients and with the Retry function of the errors subpackage:
+//
+//	budget := shopifyerrors.NewBudget(0.1, 100)
+//	client := goshopify.NewClient(app, shopName, token, goshopify.WithRetryBudget(budget))
+func WithRetryBudget(budget RetryBudget) Option {
+	return func(c *Client) {
+		c.retryBudget = budget
+	}
+}
+
+// Add a request to the retry budget of the client, if it has one.
+func (c *Client) budgetRequest() {
+	if c.retryBudget != nil {
+		c.retryBudget.Request()
+	}
+}
+
+// Whether a retry is within the retry budget of the client, taking it out of
+// the budget. Clients without a budget always retry.
+func (c *Client) budgetRetry() bool {
+	return c.retryBudget == nil || c.retryBudget.Retry()
+}
@@ -0,0 +1,85 @@
+package goshopify
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+// A retry budget that allows a fixed number of retries
+type fixedBudget struct {
+	mu       sync.Mutex
+	requests int
+	left     int
+}
+
+func (b *fixedBudget) Request() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.requests++
+}
+
+func (b *fixedBudget) Retry() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.left == 0 {
+		return false
+	}
+	b.left--
+	return true
+}
+
+func TestRetryBudgetFetchAll(t *testing.T) {
+	setup()
+	defer teardown()
+	fetchRetryDelay = time.Millisecond
+	defer func() { fetchRetryDelay = time.Second }()
+
+	budget := &fixedBudget{left: 2}
+	client = NewClient(app, "fooshop", "abcd", WithRetryBudget(budget))
+	httpmock.ActivateNonDefault(client.Client)
+
+	requests := 0
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products.json",
+		func(req *http.Request) (*http.Response, error) {
+			requests++
+			return httpmock.NewStringResponse(429, `{"errors": "Exceeded 2 calls per second for api client. Slow down!"}`), nil
+		})
+
+	err := FetchAll(client, "products", ListOptions{}, 1, func(id uint64, resource interface{}) error {
+		return nil
+	})
+	if _, ok := err.(RateLimitError); !ok {
+		t.Errorf("FetchAll returned %v, expected a RateLimitError", err)
+	}
+	if requests != 3 || budget.requests != 1 {
+		t.Errorf("FetchAll made %d requests and added %d to the budget, expected 3 and 1", requests, budget.requests)
+	}
+}
+
+func TestRetryBudgetOutbox(t *testing.T) {
+	setup()
+	defer teardown()
+
+	budget := &fixedBudget{}
+	client = NewClient(app, "fooshop", "abcd", WithRetryBudget(budget))
+	httpmock.ActivateNonDefault(client.Client)
+
+	bodies := respondInTurn("admin/products/1.json", 503)
+	store := NewMemoryOutboxStore()
+	outbox := NewOutbox(client, store)
+	outbox.RetryDelay = 0
+
+	// Queued without retries, and not replayed
+	err := outbox.Put("admin/products/1.json", map[string]string{"title": "Shirt"}, nil)
+	if queued, ok := err.(QueuedError); !ok || queued.Entry.Attempts != 1 {
+		t.Fatalf("Outbox.Put returned %v, expected a QueuedError after 1 attempt", err)
+	}
+	replayed, err := outbox.Replay()
+	if replayed != 0 || err != nil || len(*bodies) != 1 {
+		t.Errorf("Outbox.Replay returned %d, %v after %d requests, expected nothing replayed", replayed, err, len(*bodies))
+	}
+}
@@ -0,0 +1,154 @@
+package goshopify
+
+import (
+	"fmt"
+	"sync"
+)
+
+// The plans of shops that are not used by real merchants
+var developmentPlans = map[string]bool{
+	"affiliate":            true,
+	"partner_test":         true,
+	"plus_partner_sandbox": true,
+	"staff":                true,
+	"staff_business":       true,
+}
+
+// SafeguardError is returned for a destructive call to a production store
+// that was not confirmed.
+type SafeguardError struct {
+	Operation string
+	Shop      string
+}
+
+func (e SafeguardError) Error() string {
+	return fmt.Sprintf("refusing %s on production store %s without confirmation", e.Operation, e.Shop)
+}
+
+// Whether the client's shop is a production store, found out on the first
+// destructive call.
+type safeguard struct {
+	mu         sync.Mutex
+	checked    bool
+	production bool
+	shop       string
+}
+
+// The GraphQL mutations refused by the safeguard, which delete resources or
+// change many of them at once
+var destructiveMutations = map[string]bool{
+	"bulkOperationRunMutation":             true,
+	"collectionDelete":                     true,
+	"customerAddressDelete":                true,
+	"customerDelete":                       true,
+	"discountAutomaticDelete":              true,
+	"discountCodeBulkDelete":               true,
+	"discountCodeDelete":                   true,
+	"eventBridgeWebhookSubscriptionDelete": true,
+	"fulfillmentServiceDelete":             true,
+	"inventoryDeactivate":                  true,
+	"locationDelete":                       true,
+	"metafieldDelete":                      true,
+	"metafieldsDelete":                     true,
+	"orderDelete":                          true,
+	"priceListDelete":                      true,
+	"privateMetafieldDelete":               true,
+	"productDelete":                        true,
+	"productDeleteAsync":                   true,
+	"productDeleteMedia":                   true,
+	"productVariantDelete":                 true,
+	"productVariantsBulkDelete":            true,
+	"publicationDelete":                    true,
+	"scriptTagDelete":                      true,
+	"sellingPlanGroupDelete":               true,
+	"tagsRemove":                           true,
+	"webhookSubscriptionDelete":            true,
+}
+
+// WithProductionSafeguard makes the client refuse destructive calls to
+// production stores with a SafeguardError, unless they are made through
+// Confirm. Destructive calls are REST deletes, and GraphQL mutations that
+// delete resources or change many of them at once, such as productDelete
+// and bulkOperationRunMutation. The kind of store is looked up with Shop.Get
+// on the first destructive call. Development and test stores are not
+// affected.
+func WithProductionSafeguard() Option {
+	return func(c *Client) {
+		c.safeguard = &safeguard{}
+	}
+}
+
+// A confirmation of destructive calls, which lasts until it ends
+type confirmation struct {
+	mu    sync.Mutex
+	ended bool
+}
+
+func (c *confirmation) active() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.ended
+}
+
+func (c *confirmation) end() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ended = true
+}
+
+// Confirm calls fn with a copy of the client whose destructive calls are
+// allowed by the production safeguard. The copy is only confirmed until fn
+// returns, so keeping it around does not allow later calls:
+//
+//	err := client.Confirm(func(c *goshopify.Client) error {
+//		return c.Product.Delete(productID)
+//	})
+func (c *Client) Confirm(fn func(*Client) error) error {
+	confirmed := *c
+	confirmed.confirmation = &confirmation{}
+	confirmed.setServices()
+	defer confirmed.confirmation.end()
+	return fn(&confirmed)
+}
+
+// Check that the destructive mutations of a GraphQL query are allowed.
+func (c *Client) guardMutations(q string) error {
+	if c.safeguard == nil {
+		return nil
+	}
+	for _, field := range mutationFields(q) {
+		if destructiveMutations[field] {
+			err := c.guard(field)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Check that a destructive operation is allowed.
+func (c *Client) guard(operation string) error {
+	if c.safeguard == nil || c.confirmation != nil && c.confirmation.active() {
+		return nil
+	}
+
+	s := c.safeguard
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.checked {
+		shop, err := c.Shop.Get(nil)
+		if err != nil {
+			return fmt.Errorf("cannot tell whether the shop is a production store: %v", err)
+		}
+		s.checked = true
+		s.production = !developmentPlans[shop.PlanName]
+		s.shop = shop.MyshopifyDomain
+	}
+
+	if s.production {
+		return SafeguardError{Operation: operation, Shop: s.shop}
+	}
+	return nil
+}
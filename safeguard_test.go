@@ -0,0 +1,117 @@
+package goshopify
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+// Set up a client with the safeguard for a shop on the given plan, and return
+// a pointer to the number of times the shop was looked up.
+func setupSafeguard(plan string) *int {
+	setup()
+	client = NewClient(app, "fooshop", "abcd", WithProductionSafeguard())
+	httpmock.ActivateNonDefault(client.Client)
+
+	lookups := 0
+	shop := strings.Replace(string(loadFixture("shop.json")), `"plan_name": "enterprise"`, fmt.Sprintf(`"plan_name": %q`, plan), 1)
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/shop.json",
+		func(req *http.Request) (*http.Response, error) {
+			lookups++
+			return httpmock.NewStringResponse(200, shop), nil
+		})
+	httpmock.RegisterResponder("DELETE", "https://fooshop.myshopify.com/admin/products/1.json",
+		httpmock.NewStringResponder(200, "{}"))
+	return &lookups
+}
+
+func TestSafeguardProduction(t *testing.T) {
+	lookups := setupSafeguard("enterprise")
+	defer teardown()
+
+	for i := 0; i < 2; i++ {
+		err := client.Product.Delete(1)
+		if _, ok := err.(SafeguardError); !ok {
+			t.Fatalf("Product.Delete on a production store returned %v, expected a SafeguardError", err)
+		}
+	}
+	if *lookups != 1 {
+		t.Errorf("The shop was looked up %d times, expected 1", *lookups)
+	}
+
+	err := client.GraphQL.Query(`mutation { bulkOperationRunMutation(mutation: "", stagedUploadPath: "") { userErrors { message } } }`, nil, nil)
+	if _, ok := err.(SafeguardError); !ok {
+		t.Errorf("A bulk mutation on a production store returned %v, expected a SafeguardError", err)
+	}
+
+	err = client.GraphQL.Query(`mutation remove($id: ID!) { gone: productDelete(input: {id: $id}) { deletedProductId } }`, nil, nil)
+	if _, ok := err.(SafeguardError); !ok {
+		t.Errorf("A productDelete mutation on a production store returned %v, expected a SafeguardError", err)
+	}
+
+	var confirmed *Client
+	err = client.Confirm(func(c *Client) error {
+		confirmed = c
+		return c.Product.Delete(1)
+	})
+	if err != nil {
+		t.Errorf("Confirmed Product.Delete returned error: %v", err)
+	}
+
+	err = client.Product.Delete(1)
+	if _, ok := err.(SafeguardError); !ok {
+		t.Errorf("Product.Delete after a confirmed call returned %v, expected a SafeguardError", err)
+	}
+	err = confirmed.Product.Delete(1)
+	if _, ok := err.(SafeguardError); !ok {
+		t.Errorf("Product.Delete with a client kept from Confirm returned %v, expected a SafeguardError", err)
+	}
+}
+
+func TestSafeguardDevelopment(t *testing.T) {
+	setupSafeguard("partner_test")
+	defer teardown()
+
+	err := client.Product.Delete(1)
+	if err != nil {
+		t.Errorf("Product.Delete on a development store returned error: %v", err)
+	}
+}
+
+func TestMutationFields(t *testing.T) {
+	cases := []struct {
+		query    string
+		expected []string
+	}{
+		{`{ shop { name } }`, []string{}},
+		{`query products { products(first: 1) { edges { node { id } } } }`, []string{}},
+		{`mutation { productDelete(input: {id: "gid://shopify/Product/1"}) { deletedProductId } }`, []string{"productDelete"}},
+		{
+			`# productDelete in a comment
+			mutation run($mutation: String!) @live {
+				a: bulkOperationRunMutation(mutation: "mutation { productDelete }", stagedUploadPath: "") { bulkOperation { id } }
+				productUpdate(input: {title: "x"}) @skip(if: false) { product { id } }
+				...extra
+			}`,
+			[]string{"bulkOperationRunMutation", "productUpdate"},
+		},
+	}
+
+	for _, c := range cases {
+		if actual := mutationFields(c.query); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("mutationFields(%q) returned %v, expected %v", c.query, actual, c.expected)
+		}
+	}
+}
+
+func TestSafeguardError(t *testing.T) {
+	err := SafeguardError{Operation: "DELETE admin/products/1.json", Shop: "fooshop.myshopify.com"}
+	expected := "refusing DELETE admin/products/1.json on production store fooshop.myshopify.com without confirmation"
+	if err.Error() != expected {
+		t.Errorf("SafeguardError.Error() returned %q, expected %q", err.Error(), expected)
+	}
+}
@@ -0,0 +1,74 @@
+package goshopify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Results per page of the GraphQL searches that do not list connections of
+// their results, which multiply the cost of a page
+const searchPageSize = 50
+
+// The terms of a query in the search syntax of the GraphQL Admin API, which
+// are all required to match.
+// See: https://shopify.dev/api/usage/search-syntax
+type searchTerms []string
+
+// Add field:value, unless the value is empty.
+func (t *searchTerms) add(field, value string) {
+	if value != "" {
+		*t = append(*t, field+":"+searchValue(value))
+	}
+}
+
+// Add a term for each value.
+func (t *searchTerms) addAll(field string, values []string) {
+	for _, value := range values {
+		t.add(field, value)
+	}
+}
+
+// Add a range of times, either end of which may be zero to leave it open.
+func (t *searchTerms) addTimeRange(field string, min, max time.Time) {
+	if !min.IsZero() {
+		*t = append(*t, field+":>="+searchValue(min.UTC().Format(time.RFC3339)))
+	}
+	if !max.IsZero() {
+		*t = append(*t, field+":<="+searchValue(max.UTC().Format(time.RFC3339)))
+	}
+}
+
+// Add a range of numbers, either end of which may be nil to leave it open.
+func (t *searchTerms) addIntRange(field string, min, max *int) {
+	if min != nil {
+		*t = append(*t, fmt.Sprintf("%s:>=%d", field, *min))
+	}
+	if max != nil {
+		*t = append(*t, fmt.Sprintf("%s:<=%d", field, *max))
+	}
+}
+
+// Add a query as it is, in parentheses if there are other terms, so that an
+// OR in it does not take the other terms as one of its sides.
+func (t *searchTerms) addRaw(query string) {
+	if query == "" {
+		return
+	}
+	if len(*t) > 0 {
+		query = "(" + query + ")"
+	}
+	*t = append(*t, query)
+}
+
+func (t searchTerms) String() string {
+	return strings.Join(t, " ")
+}
+
+// Quote a value if it would otherwise not be read as a single value.
+func searchValue(value string) string {
+	if strings.ContainsAny(value, " \t:()'\"\\") {
+		return fmt.Sprintf("%q", value)
+	}
+	return value
+}
@@ -0,0 +1,22 @@
+package goshopify
+
+import "testing"
+
+func TestSearchValue(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected string
+	}{
+		{"paid", "paid"},
+		{"#1001", "#1001"},
+		{"needs review", `"needs review"`},
+		{"2018-01-01T00:00:00Z", `"2018-01-01T00:00:00Z"`},
+		{`say "hi"`, `"say \"hi\""`},
+	}
+
+	for _, c := range cases {
+		if actual := searchValue(c.value); actual != c.expected {
+			t.Errorf("searchValue(%q) returned %s, expected %s", c.value, actual, c.expected)
+		}
+	}
+}
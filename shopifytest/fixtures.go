@@ -0,0 +1,135 @@
+// Package shopifytest provides helpers for testing code that uses goshopify:
+// loading JSON fixtures into the package's types, comparing request payloads
+// with golden files and building signed webhook requests.
+package shopifytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	goshopify "github.com/getconversio/go-shopify"
+)
+
+// LoadFixture returns the contents of a fixture file, failing the test if it
+// cannot be read.
+func LoadFixture(t testing.TB, path string) []byte {
+	t.Helper()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("cannot load fixture %v: %v", path, err)
+	}
+	return data
+}
+
+// LoadJSON decodes a fixture file into v. When the fixture is wrapped in a
+// root object as Shopify returns it, e.g. {"product": {...}}, give the name
+// of the root as key and the wrapped value is decoded. A fixture that is not
+// wrapped in key is decoded as a whole.
+func LoadJSON(t testing.TB, path, key string, v interface{}) {
+	t.Helper()
+	data := LoadFixture(t, path)
+
+	if key != "" {
+		root := map[string]json.RawMessage{}
+		if json.Unmarshal(data, &root) == nil {
+			if wrapped, ok := root[key]; ok {
+				data = wrapped
+			}
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(v); err != nil {
+		t.Fatalf("cannot decode fixture %v: %v", path, err)
+	}
+}
+
+// Product loads a product fixture
+func Product(t testing.TB, path string) goshopify.Product {
+	t.Helper()
+	product := goshopify.Product{}
+	LoadJSON(t, path, "product", &product)
+	return product
+}
+
+// Products loads a fixture with a list of products
+func Products(t testing.TB, path string) []goshopify.Product {
+	t.Helper()
+	products := []goshopify.Product{}
+	LoadJSON(t, path, "products", &products)
+	return products
+}
+
+// Variant loads a variant fixture
+func Variant(t testing.TB, path string) goshopify.Variant {
+	t.Helper()
+	variant := goshopify.Variant{}
+	LoadJSON(t, path, "variant", &variant)
+	return variant
+}
+
+// Customer loads a customer fixture
+func Customer(t testing.TB, path string) goshopify.Customer {
+	t.Helper()
+	customer := goshopify.Customer{}
+	LoadJSON(t, path, "customer", &customer)
+	return customer
+}
+
+// Customers loads a fixture with a list of customers
+func Customers(t testing.TB, path string) []goshopify.Customer {
+	t.Helper()
+	customers := []goshopify.Customer{}
+	LoadJSON(t, path, "customers", &customers)
+	return customers
+}
+
+// Order loads an order fixture
+func Order(t testing.TB, path string) goshopify.Order {
+	t.Helper()
+	order := goshopify.Order{}
+	LoadJSON(t, path, "order", &order)
+	return order
+}
+
+// Orders loads a fixture with a list of orders
+func Orders(t testing.TB, path string) []goshopify.Order {
+	t.Helper()
+	orders := []goshopify.Order{}
+	LoadJSON(t, path, "orders", &orders)
+	return orders
+}
+
+// Transaction loads a transaction fixture
+func Transaction(t testing.TB, path string) goshopify.Transaction {
+	t.Helper()
+	transaction := goshopify.Transaction{}
+	LoadJSON(t, path, "transaction", &transaction)
+	return transaction
+}
+
+// Metafield loads a metafield fixture
+func Metafield(t testing.TB, path string) goshopify.Metafield {
+	t.Helper()
+	metafield := goshopify.Metafield{}
+	LoadJSON(t, path, "metafield", &metafield)
+	return metafield
+}
+
+// Webhook loads a webhook fixture
+func Webhook(t testing.TB, path string) goshopify.Webhook {
+	t.Helper()
+	webhook := goshopify.Webhook{}
+	LoadJSON(t, path, "webhook", &webhook)
+	return webhook
+}
+
+// Shop loads a shop fixture
+func Shop(t testing.TB, path string) goshopify.Shop {
+	t.Helper()
+	shop := goshopify.Shop{}
+	LoadJSON(t, path, "shop", &shop)
+	return shop
+}
@@ -0,0 +1,32 @@
+package shopifytest
+
+import (
+	"testing"
+)
+
+func TestLoadWrappedFixtures(t *testing.T) {
+	product := Product(t, "../fixtures/product.json")
+	if product.ID != 1071559748 {
+		t.Errorf("Product returned id %d, expected 1071559748", product.ID)
+	}
+
+	orders := Orders(t, "../fixtures/orders.json")
+	if len(orders) == 0 || orders[0].ID != 123456 {
+		t.Errorf("Orders returned %+v, expected order 123456 first", orders)
+	}
+
+	customer := Customer(t, "../fixtures/customer.json")
+	if customer.ID != 1 {
+		t.Errorf("Customer returned id %d, expected 1", customer.ID)
+	}
+}
+
+func TestLoadBareFixture(t *testing.T) {
+	product := Product(t, "testdata/product.json")
+	if product.ID != 1 || product.Title != "Shirt" {
+		t.Errorf("Product returned %+v, expected product 1", product)
+	}
+	if len(product.Variants) != 1 || product.Variants[0].Sku != "SHIRT-S" {
+		t.Errorf("Product returned variants %+v, expected SHIRT-S", product.Variants)
+	}
+}
@@ -0,0 +1,91 @@
+package shopifytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// GoldenDir is the directory golden files are kept in, relative to the
+// package being tested.
+var GoldenDir = filepath.Join("testdata", "golden")
+
+var update = flag.Bool("shopifytest.update", false, "write golden files instead of comparing with them")
+
+// AssertGolden compares a JSON document with the golden file of the given
+// name, ignoring formatting and the order of object keys. Run the tests with
+// -shopifytest.update to write the golden files from the current output.
+func AssertGolden(t testing.TB, name string, actual []byte) {
+	t.Helper()
+	path := filepath.Join(GoldenDir, name+".json")
+
+	actualJSON, err := normalizeJSON(actual)
+	if err != nil {
+		t.Fatalf("cannot compare %v with golden file: %v", string(actual), err)
+	}
+
+	if *update {
+		if err := os.MkdirAll(GoldenDir, 0755); err != nil {
+			t.Fatalf("cannot create golden directory: %v", err)
+		}
+		if err := ioutil.WriteFile(path, actualJSON, 0644); err != nil {
+			t.Fatalf("cannot write golden file %v: %v", path, err)
+		}
+		return
+	}
+
+	expectedJSON, err := normalizeJSON(LoadFixture(t, path))
+	if err != nil {
+		t.Fatalf("cannot read golden file %v: %v", path, err)
+	}
+
+	if !bytes.Equal(actualJSON, expectedJSON) {
+		t.Errorf("JSON does not match golden file %v\ngot:\n%s\nexpected:\n%s", path, actualJSON, expectedJSON)
+	}
+}
+
+// AssertRequestGolden compares the JSON body of a request, e.g. one received
+// by an httpmock responder, with the golden file of the given name. The body
+// can still be read afterwards.
+func AssertRequestGolden(t testing.TB, req *http.Request, name string) {
+	t.Helper()
+	AssertGolden(t, name, RequestBody(t, req))
+}
+
+// RequestBody reads the body of a request and puts it back, so that it can
+// be read again.
+func RequestBody(t testing.TB, req *http.Request) []byte {
+	t.Helper()
+	if req.Body == nil {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("cannot read request body: %v", err)
+	}
+	req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body
+}
+
+// Format a JSON document in a canonical way, with sorted keys and indented.
+func normalizeJSON(data []byte) ([]byte, error) {
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	normalized, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(normalized, '\n'), nil
+}
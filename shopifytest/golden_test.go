@@ -0,0 +1,52 @@
+package shopifytest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	goshopify "github.com/getconversio/go-shopify"
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestAssertRequestGolden(t *testing.T) {
+	client := goshopify.NewClient(goshopify.App{}, "fooshop", "abcd", goshopify.WithHTTPClient(&http.Client{}))
+	httpmock.ActivateNonDefault(client.Client)
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/webhooks.json",
+		func(req *http.Request) (*http.Response, error) {
+			AssertRequestGolden(t, req, "create_webhook")
+
+			// The body can be read again
+			body, _ := ioutil.ReadAll(req.Body)
+			if len(body) == 0 {
+				t.Errorf("AssertRequestGolden consumed the request body")
+			}
+			return httpmock.NewStringResponse(201, `{"webhook": {"id": 1}}`), nil
+		})
+
+	_, err := client.Webhook.Create(goshopify.Webhook{
+		Topic:   "orders/create",
+		Address: "https://example.com/hooks",
+		Format:  "json",
+	})
+	if err != nil {
+		t.Errorf("Webhook.Create returned error: %v", err)
+	}
+}
+
+func TestNormalizeJSON(t *testing.T) {
+	a, err := normalizeJSON([]byte(`{"b": 1.50, "a": [1, 2]}`))
+	if err != nil {
+		t.Fatalf("normalizeJSON returned error: %v", err)
+	}
+	b, err := normalizeJSON([]byte("{\n\"a\":[1,2],\"b\":1.50}"))
+	if err != nil {
+		t.Fatalf("normalizeJSON returned error: %v", err)
+	}
+
+	if string(a) != string(b) {
+		t.Errorf("normalizeJSON returned %s and %s for the same document", a, b)
+	}
+}
@@ -0,0 +1,58 @@
+package shopifytest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	goshopify "github.com/getconversio/go-shopify"
+)
+
+// The id of the last webhook request built
+var webhookID uint64
+
+// WebhookRequest returns a request like the one Shopify sends to deliver a
+// webhook of the given topic, e.g. "orders/create", for use with handlers in
+// tests. The payload is used as the body as is when it is a []byte or string
+// and encoded as JSON otherwise. The request is signed with the app's secret,
+// so app.VerifyWebhookRequest accepts it.
+func WebhookRequest(t testing.TB, app goshopify.App, shopName, topic string, payload interface{}) *http.Request {
+	t.Helper()
+
+	var body []byte
+	switch p := payload.(type) {
+	case []byte:
+		body = p
+	case string:
+		body = []byte(p)
+	default:
+		var err error
+		body, err = json.Marshal(payload)
+		if err != nil {
+			t.Fatalf("cannot encode webhook payload: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest("POST", "/webhooks", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Shopify-Topic", topic)
+	req.Header.Set("X-Shopify-Shop-Domain", goshopify.ShopFullName(shopName))
+	req.Header.Set("X-Shopify-Hmac-Sha256", SignWebhook(app, body))
+	req.Header.Set("X-Shopify-Webhook-Id", fmt.Sprintf("%d", atomic.AddUint64(&webhookID, 1)))
+	return req
+}
+
+// SignWebhook returns the signature of a webhook body as Shopify sends it in
+// the X-Shopify-Hmac-Sha256 header.
+func SignWebhook(app goshopify.App, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(app.ApiSecret))
+	mac.Write(body)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,45 @@
+package shopifytest
+
+import (
+	"io/ioutil"
+	"testing"
+
+	goshopify "github.com/getconversio/go-shopify"
+)
+
+func TestWebhookRequest(t *testing.T) {
+	app := goshopify.App{ApiSecret: "hush"}
+	req := WebhookRequest(t, app, "fooshop", "orders/create", goshopify.Order{ID: 1, Email: "jon@doe.ca"})
+
+	if !app.VerifyWebhookRequest(req) {
+		t.Errorf("WebhookRequest returned a request that does not verify")
+	}
+
+	cases := []struct {
+		header, expected string
+	}{
+		{"Content-Type", "application/json"},
+		{"X-Shopify-Topic", "orders/create"},
+		{"X-Shopify-Shop-Domain", "fooshop.myshopify.com"},
+	}
+	for _, c := range cases {
+		if actual := req.Header.Get(c.header); actual != c.expected {
+			t.Errorf("WebhookRequest set %v to %v, expected %v", c.header, actual, c.expected)
+		}
+	}
+	if req.Method != "POST" || req.Header.Get("X-Shopify-Webhook-Id") == "" {
+		t.Errorf("WebhookRequest returned a %v request with headers %v", req.Method, req.Header)
+	}
+
+	body, _ := ioutil.ReadAll(req.Body)
+	expected := `{"id":1,"email":"jon@doe.ca"}`
+	if string(body) != expected {
+		t.Errorf("WebhookRequest has body %s, expected %s", body, expected)
+	}
+
+	other := goshopify.App{ApiSecret: "other"}
+	req = WebhookRequest(t, app, "fooshop", "orders/create", []byte(`{"id": 1}`))
+	if other.VerifyWebhookRequest(req) {
+		t.Errorf("WebhookRequest returned a request that verifies with another secret")
+	}
+}
@@ -0,0 +1,50 @@
+package goshopify
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// StorefrontAPIVersion is the version of the Storefront API used by
+// StorefrontClient.
+const StorefrontAPIVersion = "2023-01"
+
+// StorefrontClient manages communication with the Storefront API, which
+// headless storefronts use to show products and build carts on behalf of
+// customers.
+// See: https://shopify.dev/api/storefront
+type StorefrontClient struct {
+	// HTTP client used to communicate with the Storefront API.
+	Client *http.Client
+
+	baseURL *url.URL
+
+	// A Storefront API access token
+	token string
+
+	// Services used for communicating with the API
+	Cart CartService
+}
+
+// NewStorefrontClient returns a client for the Storefront API of a shop using
+// a Storefront access token.
+func NewStorefrontClient(shopName, token string) *StorefrontClient {
+	baseURL, _ := url.Parse(ShopBaseUrl(shopName))
+
+	c := &StorefrontClient{Client: http.DefaultClient, baseURL: baseURL, token: token}
+	c.Cart = &CartServiceOp{client: c}
+
+	return c
+}
+
+// Query runs a GraphQL query or mutation against the Storefront API with the
+// given variables and saves the data of the response in the given resource.
+// Errors reported by the GraphQL endpoint are returned as a ResponseError.
+func (c *StorefrontClient) Query(q string, variables, resource interface{}) error {
+	path := fmt.Sprintf("/api/%s/graphql.json", StorefrontAPIVersion)
+	u := c.baseURL.ResolveReference(&url.URL{Path: path})
+	header := http.Header{}
+	header.Set("X-Shopify-Storefront-Access-Token", c.token)
+	return postGraphQL(c.Client, u.String(), header, q, variables, resource)
+}
@@ -0,0 +1,56 @@
+package goshopify
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestStorefrontClientQuery(t *testing.T) {
+	storefront := NewStorefrontClient("fooshop", "storefronttoken")
+	httpmock.ActivateNonDefault(storefront.Client)
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", storefrontGraphQLURL,
+		httpmock.NewStringResponder(200, `{"data": {"shop": {"name": "Foo Shop"}}}`))
+
+	data := struct {
+		Shop struct {
+			Name string `json:"name"`
+		} `json:"shop"`
+	}{}
+	err := storefront.Query(`{ shop { name } }`, nil, &data)
+	if err != nil {
+		t.Fatalf("StorefrontClient.Query returned error: %v", err)
+	}
+	if data.Shop.Name != "Foo Shop" {
+		t.Errorf("StorefrontClient.Query returned shop %v, expected Foo Shop", data.Shop.Name)
+	}
+}
+
+func TestStorefrontClientQueryErrors(t *testing.T) {
+	storefront := NewStorefrontClient("fooshop", "storefronttoken")
+	httpmock.ActivateNonDefault(storefront.Client)
+	defer teardown()
+
+	httpmock.RegisterResponder("POST", storefrontGraphQLURL,
+		httpmock.NewStringResponder(200, `{"errors": [{"message": "Field 'nope' doesn't exist on type 'Shop'"}]}`))
+
+	err := storefront.Query(`{ shop { nope } }`, nil, nil)
+	expected := ResponseError{
+		Status:  200,
+		Message: "Field 'nope' doesn't exist on type 'Shop'",
+		Errors:  []string{"Field 'nope' doesn't exist on type 'Shop'"},
+	}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("StorefrontClient.Query returned error %#v, expected %#v", err, expected)
+	}
+
+	httpmock.RegisterResponder("POST", storefrontGraphQLURL,
+		httpmock.NewStringResponder(401, `{"errors": "Unauthorized"}`))
+	err = storefront.Query(`{ shop { name } }`, nil, nil)
+	if responseError, ok := err.(ResponseError); !ok || responseError.Status != 401 {
+		t.Errorf("StorefrontClient.Query returned error %#v, expected a 401 ResponseError", err)
+	}
+}
@@ -0,0 +1,419 @@
+package goshopify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const syncPageLimit = 250
+
+// SyncStore is implemented by callers that keep a local copy of a Shopify
+// resource. Upsert receives a pointer to the resource's struct, e.g. a
+// *Product when syncing products.
+type SyncStore interface {
+	Upsert(id uint64, resource interface{}) error
+	Delete(id uint64) error
+}
+
+// Syncer keeps registered SyncStores current with their Shopify resource.
+//
+// Call Load once to copy every existing record into a store, pass webhooks for
+// the resource to HandleWebhook or HandleWebhookRequest, and call CatchUp
+// periodically to pick up changes whose webhook never arrived. CatchUp lists
+// everything updated since the newest updated_at seen by the previous Load or
+// CatchUp, or since it started if that is earlier, so a store receives each
+// change at least once. Deletions are only seen through webhooks.
+//
+// Webhooks can arrive late and out of order, so a webhook whose updated_at is
+// not newer than the checkpoint, or than the record last delivered by a
+// webhook, is skipped, as the store already has that version or a newer one.
+type Syncer struct {
+	client    *Client
+	mu        sync.Mutex
+	resources map[string]*syncState
+}
+
+// syncResource describes how to list, count and decode one kind of resource.
+type syncResource struct {
+	list   func(c *Client, options ListOptions) ([]syncItem, error)
+	count  func(c *Client, options CountOptions) (int, error)
+	decode func(body []byte) (syncItem, error)
+}
+
+type syncItem struct {
+	id        uint64
+	updatedAt *time.Time
+	resource  interface{}
+}
+
+type syncState struct {
+	resource syncResource
+	store    SyncStore
+
+	// Serializes Load and CatchUp for the resource
+	copyMu sync.Mutex
+
+	// Where the next CatchUp continues from
+	checkpoint time.Time
+
+	// Records delivered by webhooks since before the last copy, so that
+	// older webhooks and copies do not overwrite them with older data.
+	delivered map[uint64]syncDelivery
+}
+
+type syncDelivery struct {
+	updatedAt *time.Time
+	deleted   bool
+
+	// When a deletion was delivered
+	at time.Time
+}
+
+// The resources that can be synced, keyed by their name in webhook topics.
+var syncResources = map[string]syncResource{
+	productsResourceName: {
+		list: func(c *Client, options ListOptions) ([]syncItem, error) {
+			products, err := c.Product.List(options)
+			items := make([]syncItem, len(products))
+			for i := range products {
+				items[i] = syncItem{products[i].ID, products[i].UpdatedAt, &products[i]}
+			}
+			return items, err
+		},
+		count: func(c *Client, options CountOptions) (int, error) {
+			return c.Product.Count(options)
+		},
+		decode: func(body []byte) (syncItem, error) {
+			product := new(Product)
+			err := json.Unmarshal(body, product)
+			return syncItem{product.ID, product.UpdatedAt, product}, err
+		},
+	},
+	customersResourceName: {
+		list: func(c *Client, options ListOptions) ([]syncItem, error) {
+			customers, err := c.Customer.List(options)
+			items := make([]syncItem, len(customers))
+			for i := range customers {
+				items[i] = syncItem{customers[i].ID, customers[i].UpdatedAt, &customers[i]}
+			}
+			return items, err
+		},
+		count: func(c *Client, options CountOptions) (int, error) {
+			return c.Customer.Count(options)
+		},
+		decode: func(body []byte) (syncItem, error) {
+			customer := new(Customer)
+			err := json.Unmarshal(body, customer)
+			return syncItem{customer.ID, customer.UpdatedAt, customer}, err
+		},
+	},
+	ordersResourceName: {
+		list: func(c *Client, options ListOptions) ([]syncItem, error) {
+			orders, err := c.Order.List(OrderListOptions{
+				Page:         options.Page,
+				Limit:        options.Limit,
+				SinceID:      options.SinceID,
+				CreatedAtMin: options.CreatedAtMin,
+				CreatedAtMax: options.CreatedAtMax,
+				UpdatedAtMin: options.UpdatedAtMin,
+				UpdatedAtMax: options.UpdatedAtMax,
+				Order:        options.Order,
+				Fields:       options.Fields,
+				Status:       "any",
+			})
+			items := make([]syncItem, len(orders))
+			for i := range orders {
+				items[i] = syncItem{orders[i].ID, orders[i].UpdatedAt, &orders[i]}
+			}
+			return items, err
+		},
+		count: func(c *Client, options CountOptions) (int, error) {
+			return c.Order.Count(OrderCountOptions{
+				CreatedAtMin: options.CreatedAtMin,
+				CreatedAtMax: options.CreatedAtMax,
+				UpdatedAtMin: options.UpdatedAtMin,
+				UpdatedAtMax: options.UpdatedAtMax,
+				Status:       "any",
+			})
+		},
+		decode: func(body []byte) (syncItem, error) {
+			order := new(Order)
+			err := json.Unmarshal(body, order)
+			return syncItem{order.ID, order.UpdatedAt, order}, err
+		},
+	},
+}
+
+// NewSyncer returns a Syncer that uses the given client to talk to Shopify.
+func NewSyncer(client *Client) *Syncer {
+	return &Syncer{client: client, resources: make(map[string]*syncState)}
+}
+
+// Register a store for a resource. The resource is named as in webhook
+// topics, e.g. "products", "customers" or "orders".
+func (s *Syncer) Register(resource string, store SyncStore) error {
+	kind, ok := syncResources[resource]
+	if !ok {
+		return fmt.Errorf("syncing %s is not supported", resource)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resources[resource] = &syncState{resource: kind, store: store, delivered: make(map[uint64]syncDelivery)}
+	return nil
+}
+
+// Checkpoint returns the updated_at from which the next CatchUp of the
+// resource will list records.
+func (s *Syncer) Checkpoint(resource string) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state, ok := s.resources[resource]; ok {
+		return state.checkpoint
+	}
+	return time.Time{}
+}
+
+// Load copies every record of the resource into its store.
+func (s *Syncer) Load(resource string) error {
+	state, err := s.state(resource)
+	if err != nil {
+		return err
+	}
+	return s.copy(state, ListOptions{})
+}
+
+// CatchUp copies the records of the resource that were updated since the
+// last Load or CatchUp into its store.
+func (s *Syncer) CatchUp(resource string) error {
+	state, err := s.state(resource)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	checkpoint := state.checkpoint
+	s.mu.Unlock()
+
+	return s.copy(state, ListOptions{UpdatedAtMin: checkpoint})
+}
+
+// HandleWebhook applies a webhook payload for the given topic, e.g.
+// "products/update", to the matching store, unless it is older than what the
+// store has.
+func (s *Syncer) HandleWebhook(topic string, body []byte) error {
+	parts := strings.SplitN(topic, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid webhook topic %q", topic)
+	}
+
+	state, err := s.state(parts[0])
+	if err != nil {
+		return err
+	}
+
+	if parts[1] == "delete" {
+		payload := struct {
+			ID uint64 `json:"id"`
+		}{}
+		err := json.Unmarshal(body, &payload)
+		if err != nil {
+			return err
+		}
+		undo, _ := s.deliver(state, payload.ID, syncDelivery{deleted: true, at: time.Now()})
+		err = state.store.Delete(payload.ID)
+		if err != nil {
+			undo()
+		}
+		return err
+	}
+
+	item, err := state.resource.decode(body)
+	if err != nil {
+		return err
+	}
+	undo, ok := s.deliver(state, item.id, syncDelivery{updatedAt: item.updatedAt})
+	if !ok {
+		return nil
+	}
+	err = state.store.Upsert(item.id, item.resource)
+	if err != nil {
+		undo()
+	}
+	return err
+}
+
+// HandleWebhookRequest verifies a webhook request sent by Shopify and applies
+// it with HandleWebhook.
+func (s *Syncer) HandleWebhookRequest(r *http.Request) error {
+	if !s.client.app.VerifyWebhookRequest(r) {
+		return errors.New("invalid webhook signature")
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return s.HandleWebhook(r.Header.Get("X-Shopify-Topic"), body)
+}
+
+func (s *Syncer) state(resource string) (*syncState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.resources[resource]
+	if !ok {
+		return nil, fmt.Errorf("no store registered for %s", resource)
+	}
+	return state, nil
+}
+
+// Record a webhook delivery, unless it is an update that is not newer than
+// the checkpoint or the last delivery of the record, and report whether it
+// was recorded. The returned func forgets the delivery again, for when the
+// store fails to apply it and Shopify is left to send it again.
+func (s *Syncer) deliver(state *syncState, id uint64, delivery syncDelivery) (func(), bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Nothing to compare an update without updated_at with
+	updatedAt := delivery.updatedAt
+	if updatedAt == nil && !delivery.deleted {
+		return func() {}, true
+	}
+
+	last, delivered := state.delivered[id]
+	if updatedAt != nil {
+		if !state.checkpoint.IsZero() && !updatedAt.After(state.checkpoint) {
+			return func() {}, false
+		}
+		if delivered && (last.deleted || last.updatedAt != nil && !updatedAt.After(*last.updatedAt)) {
+			return func() {}, false
+		}
+	}
+	state.delivered[id] = delivery
+
+	undo := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		// Leave a later delivery of the record in place
+		if current, ok := state.delivered[id]; !ok || current != delivery {
+			return
+		}
+		if delivered {
+			state.delivered[id] = last
+		} else {
+			delete(state.delivered, id)
+		}
+	}
+	return undo, true
+}
+
+// Page through the resource and upsert every record.
+func (s *Syncer) copy(state *syncState, options ListOptions) error {
+	state.copyMu.Lock()
+	defer state.copyMu.Unlock()
+
+	started := time.Now()
+	var newest time.Time
+	err := listAll(s.client, state.resource.list, options, func(item syncItem, _ PageInfo) error {
+		if !s.isNewer(state, item) {
+			return nil
+		}
+
+		err := state.store.Upsert(item.id, item.resource)
+		if err == nil && item.updatedAt != nil && item.updatedAt.After(newest) {
+			newest = *item.updatedAt
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if checkpoint := nextCheckpoint(newest, started); checkpoint.After(state.checkpoint) {
+		state.checkpoint = checkpoint
+	}
+
+	// Forget the deliveries that the checkpoint covers, and the deletions
+	// that the copy could no longer list
+	for id, delivery := range state.delivered {
+		if delivery.deleted && delivery.at.Before(started) ||
+			!delivery.deleted && !delivery.updatedAt.After(state.checkpoint) {
+			delete(state.delivered, id)
+		}
+	}
+	return nil
+}
+
+// Page through a resource by ascending id, starting after the SinceID of the
+// options, and call fn for every record with the position after it.
+func listAll(c *Client, list func(*Client, ListOptions) ([]syncItem, error), options ListOptions, fn func(syncItem, PageInfo) error) error {
+	return listPages(c, list, options, func(items []syncItem, more bool) error {
+		for i, item := range items {
+			err := fn(item, PageInfo{HasNextPage: more || i < len(items)-1, SinceID: item.id})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Page through a resource by ascending id, starting after the SinceID of the
+// options, and call fn for every page that has records, along with whether
+// more pages may follow.
+func listPages(c *Client, list func(*Client, ListOptions) ([]syncItem, error), options ListOptions, fn func([]syncItem, bool) error) error {
+	options.Limit = syncPageLimit
+	for {
+		items, err := list(c, options)
+		if err != nil {
+			return err
+		}
+
+		more := len(items) == syncPageLimit
+		if len(items) > 0 {
+			err = fn(items, more)
+			if err != nil {
+				return err
+			}
+			options.SinceID = int(items[len(items)-1].id)
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+// Work out where to continue from after listing records that started at the
+// given time and saw updates up to newest. A record updated while the listing
+// was running may have been passed over already, so the checkpoint never moves
+// past the start of the listing.
+func nextCheckpoint(newest, started time.Time) time.Time {
+	if newest.After(started) {
+		return started
+	}
+	return newest
+}
+
+// Check that a listed record is newer than what webhooks delivered.
+func (s *Syncer) isNewer(state *syncState, item syncItem) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delivery, ok := state.delivered[item.id]
+	if !ok {
+		return true
+	}
+	if delivery.deleted {
+		return false
+	}
+	return delivery.updatedAt == nil || (item.updatedAt != nil && item.updatedAt.After(*delivery.updatedAt))
+}
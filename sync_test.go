@@ -0,0 +1,311 @@
+package goshopify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+)
+
+type memoryStore struct {
+	records map[uint64]interface{}
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{records: make(map[uint64]interface{})}
+}
+
+func (m *memoryStore) Upsert(id uint64, resource interface{}) error {
+	m.records[id] = resource
+	return nil
+}
+
+func (m *memoryStore) Delete(id uint64) error {
+	delete(m.records, id)
+	return nil
+}
+
+// A store that fails the next n changes
+type failingStore struct {
+	*memoryStore
+	failures int
+}
+
+func (f *failingStore) Upsert(id uint64, resource interface{}) error {
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("store unavailable")
+	}
+	return f.memoryStore.Upsert(id, resource)
+}
+
+func (f *failingStore) Delete(id uint64) error {
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("store unavailable")
+	}
+	return f.memoryStore.Delete(id)
+}
+
+func (m *memoryStore) ids() []uint64 {
+	ids := []uint64{}
+	for id := range m.records {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func TestSyncerRegisterUnsupported(t *testing.T) {
+	syncer := NewSyncer(client)
+	err := syncer.Register("themes", newMemoryStore())
+	if err == nil {
+		t.Error("Syncer.Register(themes) returned nil error, expected an error")
+	}
+}
+
+func TestSyncerLoad(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products.json?limit=250",
+		httpmock.NewStringResponder(200, `{"products": [
+			{"id": 1, "title": "one", "updated_at": "2017-01-01T00:00:00Z"},
+			{"id": 2, "title": "two", "updated_at": "2017-03-01T00:00:00Z"}
+		]}`))
+
+	store := newMemoryStore()
+	syncer := NewSyncer(client)
+	syncer.Register("products", store)
+
+	err := syncer.Load("products")
+	if err != nil {
+		t.Errorf("Syncer.Load returned error: %v", err)
+	}
+
+	expected := []uint64{1, 2}
+	if !reflect.DeepEqual(store.ids(), expected) {
+		t.Errorf("Syncer.Load stored ids %v, expected %v", store.ids(), expected)
+	}
+	if product := store.records[2].(*Product); product.Title != "two" {
+		t.Errorf("Syncer.Load stored title %v, expected two", product.Title)
+	}
+
+	checkpoint := time.Date(2017, time.March, 1, 0, 0, 0, 0, time.UTC)
+	if !syncer.Checkpoint("products").Equal(checkpoint) {
+		t.Errorf("Syncer.Checkpoint returned %v, expected %v", syncer.Checkpoint("products"), checkpoint)
+	}
+}
+
+func TestSyncerLoadKeepsWebhookDeliveries(t *testing.T) {
+	setup()
+	defer teardown()
+
+	store := newMemoryStore()
+	syncer := NewSyncer(client)
+	syncer.Register("products", store)
+
+	// Webhooks arrive while the products are being listed.
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products.json?limit=250",
+		func(req *http.Request) (*http.Response, error) {
+			syncer.HandleWebhook("products/update", []byte(`{"id": 1, "title": "new", "updated_at": "2017-02-01T00:00:00Z"}`))
+			syncer.HandleWebhook("products/delete", []byte(`{"id": 2}`))
+			return httpmock.NewStringResponse(200, `{"products": [
+				{"id": 1, "title": "old", "updated_at": "2017-01-01T00:00:00Z"},
+				{"id": 2, "title": "two", "updated_at": "2017-01-01T00:00:00Z"}
+			]}`), nil
+		})
+
+	err := syncer.Load("products")
+	if err != nil {
+		t.Errorf("Syncer.Load returned error: %v", err)
+	}
+
+	expected := []uint64{1}
+	if !reflect.DeepEqual(store.ids(), expected) {
+		t.Errorf("Syncer.Load stored ids %v, expected %v", store.ids(), expected)
+	}
+	if product := store.records[1].(*Product); product.Title != "new" {
+		t.Errorf("Syncer.Load stored title %v, expected new", product.Title)
+	}
+}
+
+func TestSyncerCatchUp(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/orders.json?limit=250&status=any",
+		httpmock.NewStringResponder(200, `{"orders": [{"id": 1, "updated_at": "2017-01-01T00:00:00Z"}]}`))
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/orders.json?limit=250&status=any&updated_at_min=2017-01-01T00%3A00%3A00Z",
+		httpmock.NewStringResponder(200, `{"orders": [{"id": 1, "updated_at": "2017-01-01T00:00:00Z"}, {"id": 3, "updated_at": "2017-01-02T00:00:00Z"}]}`))
+
+	store := newMemoryStore()
+	syncer := NewSyncer(client)
+	syncer.Register("orders", store)
+
+	err := syncer.Load("orders")
+	if err != nil {
+		t.Errorf("Syncer.Load returned error: %v", err)
+	}
+
+	err = syncer.CatchUp("orders")
+	if err != nil {
+		t.Errorf("Syncer.CatchUp returned error: %v", err)
+	}
+
+	expected := []uint64{1, 3}
+	if !reflect.DeepEqual(store.ids(), expected) {
+		t.Errorf("Syncer.CatchUp stored ids %v, expected %v", store.ids(), expected)
+	}
+}
+
+func TestSyncerHandleWebhook(t *testing.T) {
+	setup()
+	defer teardown()
+
+	store := newMemoryStore()
+	syncer := NewSyncer(client)
+	syncer.Register("customers", store)
+
+	err := syncer.HandleWebhook("customers/create", []byte(`{"id": 1, "email": "john@test.com"}`))
+	if err != nil {
+		t.Errorf("Syncer.HandleWebhook returned error: %v", err)
+	}
+	if customer := store.records[1].(*Customer); customer.Email != "john@test.com" {
+		t.Errorf("Syncer.HandleWebhook stored email %v, expected john@test.com", customer.Email)
+	}
+
+	err = syncer.HandleWebhook("customers/delete", []byte(`{"id": 1}`))
+	if err != nil {
+		t.Errorf("Syncer.HandleWebhook returned error: %v", err)
+	}
+	if len(store.records) != 0 {
+		t.Errorf("Syncer.HandleWebhook left %d records, expected 0", len(store.records))
+	}
+
+	cases := []string{"customers", "products/update"}
+	for _, topic := range cases {
+		err := syncer.HandleWebhook(topic, []byte(`{"id": 1}`))
+		if err == nil {
+			t.Errorf("Syncer.HandleWebhook(%v) returned nil error, expected an error", topic)
+		}
+	}
+}
+
+func TestSyncerHandleWebhookOutOfOrder(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/products.json?limit=250",
+		httpmock.NewStringResponder(200, `{"products": [{"id": 1, "title": "loaded", "updated_at": "2017-01-02T00:00:00Z"}]}`))
+
+	store := newMemoryStore()
+	syncer := NewSyncer(client)
+	syncer.Register("products", store)
+	err := syncer.Load("products")
+	if err != nil {
+		t.Fatalf("Syncer.Load returned error: %v", err)
+	}
+
+	cases := []struct {
+		body     string
+		expected string
+	}{
+		// Older than the checkpoint of the load
+		{`{"id": 1, "title": "late", "updated_at": "2017-01-01T00:00:00Z"}`, "loaded"},
+		{`{"id": 1, "title": "newer", "updated_at": "2017-01-04T00:00:00Z"}`, "newer"},
+		// Older than the last webhook
+		{`{"id": 1, "title": "older", "updated_at": "2017-01-03T00:00:00Z"}`, "newer"},
+		{`{"id": 1, "title": "newest", "updated_at": "2017-01-05T00:00:00Z"}`, "newest"},
+	}
+
+	for _, c := range cases {
+		err := syncer.HandleWebhook("products/update", []byte(c.body))
+		if err != nil {
+			t.Errorf("Syncer.HandleWebhook returned error: %v", err)
+		}
+		if product := store.records[1].(*Product); product.Title != c.expected {
+			t.Errorf("Syncer.HandleWebhook(%s) left title %v, expected %v", c.body, product.Title, c.expected)
+		}
+	}
+}
+
+func TestSyncerHandleWebhookRedelivery(t *testing.T) {
+	setup()
+	defer teardown()
+
+	store := &failingStore{memoryStore: newMemoryStore(), failures: 1}
+	syncer := NewSyncer(client)
+	syncer.Register("products", store)
+
+	body := []byte(`{"id": 1, "title": "updated", "updated_at": "2017-01-04T00:00:00Z"}`)
+	err := syncer.HandleWebhook("products/update", body)
+	if err == nil {
+		t.Fatal("Syncer.HandleWebhook returned nil error for a failing store")
+	}
+
+	// Shopify sends the webhook again
+	err = syncer.HandleWebhook("products/update", body)
+	if err != nil {
+		t.Errorf("Syncer.HandleWebhook returned error: %v", err)
+	}
+	if product, ok := store.records[1].(*Product); !ok || product.Title != "updated" {
+		t.Errorf("Syncer.HandleWebhook stored %+v for the redelivery, expected the update", store.records[1])
+	}
+
+	store.failures = 1
+	err = syncer.HandleWebhook("products/delete", []byte(`{"id": 1}`))
+	if err == nil {
+		t.Fatal("Syncer.HandleWebhook returned nil error for a failing store")
+	}
+	err = syncer.HandleWebhook("products/delete", []byte(`{"id": 1}`))
+	if err != nil {
+		t.Errorf("Syncer.HandleWebhook returned error: %v", err)
+	}
+	if len(store.records) != 0 {
+		t.Errorf("Syncer.HandleWebhook left %d records after the redelivered delete, expected 0", len(store.records))
+	}
+}
+
+func TestSyncerHandleWebhookRequest(t *testing.T) {
+	setup()
+	defer teardown()
+
+	store := newMemoryStore()
+	syncer := NewSyncer(client)
+	syncer.Register("products", store)
+
+	body := []byte(`{"id": 1}`)
+	mac := hmac.New(sha256.New, []byte(app.ApiSecret))
+	mac.Write(body)
+
+	req, _ := http.NewRequest("POST", "https://example.com/webhooks", bytes.NewBuffer(body))
+	req.Header.Add("X-Shopify-Topic", "products/create")
+	req.Header.Add("X-Shopify-Hmac-Sha256", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	err := syncer.HandleWebhookRequest(req)
+	if err != nil {
+		t.Errorf("Syncer.HandleWebhookRequest returned error: %v", err)
+	}
+	if len(store.records) != 1 {
+		t.Errorf("Syncer.HandleWebhookRequest stored %d records, expected 1", len(store.records))
+	}
+
+	req, _ = http.NewRequest("POST", "https://example.com/webhooks", bytes.NewBuffer(body))
+	req.Header.Add("X-Shopify-Topic", "products/create")
+	req.Header.Add("X-Shopify-Hmac-Sha256", "invalid")
+
+	err = syncer.HandleWebhookRequest(req)
+	if err == nil {
+		t.Error("Syncer.HandleWebhookRequest returned nil error for an invalid signature")
+	}
+}
@@ -0,0 +1,603 @@
+// Package testserver provides a fake Shopify admin API that runs in-process,
+// for testing code that uses goshopify without a development store.
+//
+// The server keeps products, customers, orders, webhooks and metafields in
+// memory and serves them like Shopify does: list endpoints are paginated with
+// limit, page and since_id, every response carries the call limit header,
+// and errors have the same shape as Shopify's.
+package testserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	goshopify "github.com/getconversio/go-shopify"
+)
+
+// Defaults of the leaky bucket that limits the calls to a Server
+const (
+	DefaultBucketSize = 40
+	DefaultLeakRate   = 2
+)
+
+// The page size used when a list request has no limit, and the largest one
+// allowed
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 250
+)
+
+// The resources served, keyed by their name in paths, with the name of a
+// single record in request and response bodies.
+var resources = map[string]string{
+	"products":   "product",
+	"customers":  "customer",
+	"orders":     "order",
+	"webhooks":   "webhook",
+	"metafields": "metafield",
+}
+
+// The attributes a record must have to be created
+var requiredAttributes = map[string][]string{
+	"products":   {"title"},
+	"webhooks":   {"topic", "address"},
+	"metafields": {"namespace", "key", "value", "value_type"},
+}
+
+// A record as it is stored, i.e. its JSON attributes
+type record map[string]interface{}
+
+// Server is a fake Shopify admin API. Create one with New and close it when
+// done.
+type Server struct {
+	*httptest.Server
+
+	// Shop returned by the shop endpoint
+	Shop goshopify.Shop
+
+	// Access token that requests must carry. Any token, or basic auth, is
+	// accepted when empty.
+	Token string
+
+	// Size of the leaky bucket and the calls per second leaking out of it.
+	// Calls made when the bucket is full are answered with 429 Too Many
+	// Requests.
+	BucketSize int
+	LeakRate   float64
+
+	mu       sync.Mutex
+	nextID   uint64
+	records  map[string]map[uint64]record
+	bucket   float64
+	leakedAt time.Time
+}
+
+// New starts a Server with an empty store.
+func New() *Server {
+	s := &Server{
+		Shop: goshopify.Shop{
+			ID:           1,
+			Name:         "Test Shop",
+			Domain:       "test-shop.myshopify.com",
+			Currency:     "USD",
+			IanaTimezone: "UTC",
+			MoneyFormat:  "${{amount}}",
+		},
+		BucketSize: DefaultBucketSize,
+		LeakRate:   DefaultLeakRate,
+		nextID:     1000,
+		records:    make(map[string]map[uint64]record),
+	}
+	for name := range resources {
+		s.records[name] = make(map[uint64]record)
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// NewClient returns a goshopify client whose requests go to the server,
+// whatever the shop name.
+func (s *Server) NewClient(app goshopify.App, shopName, token string, opts ...goshopify.Option) *goshopify.Client {
+	target, _ := url.Parse(s.URL)
+	httpClient := &http.Client{Transport: redirectTransport{target}}
+	opts = append([]goshopify.Option{goshopify.WithHTTPClient(httpClient)}, opts...)
+	return goshopify.NewClient(app, shopName, token, opts...)
+}
+
+// A transport that sends every request to the target server.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	redirected := new(http.Request)
+	*redirected = *req
+	redirected.URL = new(url.URL)
+	*redirected.URL = *req.URL
+	redirected.URL.Scheme = t.target.Scheme
+	redirected.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(redirected)
+}
+
+// Add a record to the store, e.g. a goshopify.Product to "products", and
+// return its id. An id is assigned unless the record has one.
+func (s *Server) Add(resource string, v interface{}) (uint64, error) {
+	if _, ok := resources[resource]; !ok {
+		return 0, fmt.Errorf("testserver does not serve %s", resource)
+	}
+
+	r, err := toRecord(v)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.create(resource, r), nil
+}
+
+// Get a record from the store and decode it into v. It reports whether the
+// record exists.
+func (s *Server) Get(resource string, id uint64, v interface{}) (bool, error) {
+	s.mu.Lock()
+	r, ok := s.records[resource][id]
+	if ok {
+		r = r.copy()
+	}
+	s.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return true, err
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+// Count the records of a resource in the store.
+func (s *Server) Count(resource string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.records[resource])
+}
+
+func toRecord(v interface{}) (record, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	r := record{}
+	err = json.Unmarshal(data, &r)
+	return r, err
+}
+
+// Store a new record, assigning its ids and timestamps. The lock must be held.
+func (s *Server) create(resource string, r record) uint64 {
+	id := s.assignID(r)
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, ok := r["created_at"]; !ok {
+		r["created_at"] = now
+	}
+	r["updated_at"] = now
+
+	if variants, ok := r["variants"].([]interface{}); ok {
+		for _, v := range variants {
+			if variant, ok := v.(map[string]interface{}); ok {
+				s.assignID(variant)
+				variant["product_id"] = id
+			}
+		}
+	}
+
+	s.records[resource][id] = r
+	return id
+}
+
+// Give a record an id unless it has one, and return it.
+func (s *Server) assignID(r map[string]interface{}) uint64 {
+	if id := recordID(r); id != 0 {
+		return id
+	}
+	s.nextID++
+	r["id"] = s.nextID
+	return s.nextID
+}
+
+// Return a copy of a record that can be written out while the store changes.
+func (r record) copy() record {
+	c := make(record, len(r))
+	for k, v := range r {
+		c[k] = v
+	}
+	return c
+}
+
+func recordID(r map[string]interface{}) uint64 {
+	switch id := r["id"].(type) {
+	case float64:
+		return uint64(id)
+	case uint64:
+		return id
+	}
+	return 0
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if !s.authorized(req) {
+		writeError(w, http.StatusUnauthorized, "[API] Invalid API key or access token (unrecognized login or wrong password)")
+		return
+	}
+
+	if !s.take(w) {
+		w.Header().Set("Retry-After", "2.0")
+		writeError(w, http.StatusTooManyRequests, "Exceeded 2 calls per second for api client. Reduce request rates to resume uninterrupted service.")
+		return
+	}
+
+	path := strings.TrimSuffix(strings.TrimPrefix(req.URL.Path, "/admin/"), ".json")
+	parts := strings.Split(path, "/")
+	if path == "shop" && req.Method == "GET" {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"shop": s.Shop})
+		return
+	}
+
+	// Metafields of another resource, e.g. products/1/metafields/2
+	filter := record{}
+	if len(parts) >= 3 && parts[2] == "metafields" {
+		if _, ok := resources[parts[0]]; !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		ownerID, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil || !s.exists(parts[0], ownerID) {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		filter["owner_resource"] = strings.TrimSuffix(parts[0], "s")
+		filter["owner_id"] = ownerID
+		parts = parts[2:]
+	} else if len(parts) > 0 && parts[0] == "metafields" {
+		filter["owner_resource"] = "shop"
+	}
+
+	resource := parts[0]
+	if _, ok := resources[resource]; !ok || len(parts) > 2 {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && req.Method == "GET":
+		s.list(w, req, resource, filter)
+	case len(parts) == 1 && req.Method == "POST":
+		s.post(w, req, resource, filter)
+	case len(parts) == 2 && parts[1] == "count" && req.Method == "GET":
+		s.count(w, req, resource, filter)
+	case len(parts) == 2:
+		id, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		s.serveRecord(w, req, resource, id, filter)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+func (s *Server) authorized(req *http.Request) bool {
+	if token := req.Header.Get("X-Shopify-Access-Token"); token != "" {
+		return s.Token == "" || token == s.Token
+	}
+	_, _, ok := req.BasicAuth()
+	return ok && s.Token == ""
+}
+
+// Take a call from the bucket and set the call limit header. It reports
+// whether the call is allowed.
+func (s *Server) take(w http.ResponseWriter) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if !s.leakedAt.IsZero() {
+		s.bucket -= now.Sub(s.leakedAt).Seconds() * s.LeakRate
+		if s.bucket < 0 {
+			s.bucket = 0
+		}
+	}
+	s.leakedAt = now
+
+	allowed := s.bucket+1 <= float64(s.BucketSize)
+	if allowed {
+		s.bucket++
+	}
+	w.Header().Set("X-Shopify-Shop-Api-Call-Limit", fmt.Sprintf("%d/%d", int(s.bucket+0.5), s.BucketSize))
+	return allowed
+}
+
+func (s *Server) exists(resource string, id uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.records[resource][id]
+	return ok
+}
+
+// Return the records of a resource that match the filter and query, sorted by
+// id.
+func (s *Server) find(resource string, filter record, query url.Values) ([]record, error) {
+	min := map[string]time.Time{}
+	max := map[string]time.Time{}
+	for _, attribute := range []string{"created_at", "updated_at"} {
+		for bound, times := range map[string]map[string]time.Time{"_min": min, "_max": max} {
+			value := query.Get(attribute + bound)
+			if value == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, fmt.Errorf("%s%s is invalid", attribute, bound)
+			}
+			times[attribute] = t
+		}
+	}
+
+	sinceID, _ := strconv.ParseUint(query.Get("since_id"), 10, 64)
+	status := query.Get("status")
+	if status == "" {
+		status = "open"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := []record{}
+	for id, r := range s.records[resource] {
+		if id <= sinceID || !matches(r, filter) {
+			continue
+		}
+		if resource == "orders" && !hasStatus(r, status) {
+			continue
+		}
+
+		inRange := true
+		for attribute, t := range min {
+			value, _ := time.Parse(time.RFC3339, fmt.Sprint(r[attribute]))
+			inRange = inRange && !value.Before(t)
+		}
+		for attribute, t := range max {
+			value, _ := time.Parse(time.RFC3339, fmt.Sprint(r[attribute]))
+			inRange = inRange && !value.After(t)
+		}
+		if inRange {
+			found = append(found, r.copy())
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool { return recordID(found[i]) < recordID(found[j]) })
+	return found, nil
+}
+
+func matches(r, filter record) bool {
+	for k, v := range filter {
+		if formatValue(r[k]) != formatValue(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Format an attribute for comparison. Numbers decoded from JSON are floats,
+// which are written without an exponent to compare equal to integer ids.
+func formatValue(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// Report whether an order has the status asked for in a list request.
+func hasStatus(r record, status string) bool {
+	cancelled := r["cancelled_at"] != nil
+	closed := r["closed_at"] != nil
+	switch status {
+	case "open":
+		return !cancelled && !closed
+	case "closed":
+		return closed
+	case "cancelled":
+		return cancelled
+	}
+	return true
+}
+
+func (s *Server) list(w http.ResponseWriter, req *http.Request, resource string, filter record) {
+	query := req.URL.Query()
+	found, err := s.find(resource, filter, query)
+	if err != nil {
+		writeErrors(w, http.StatusBadRequest, map[string][]string{"base": {err.Error()}})
+		return
+	}
+
+	limit := defaultPageLimit
+	if value := query.Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			writeErrors(w, http.StatusBadRequest, map[string][]string{"limit": {fmt.Sprintf("must be between 1 and %d", maxPageLimit)}})
+			return
+		}
+	}
+
+	page := 1
+	if value := query.Get("page"); value != "" {
+		page, err = strconv.Atoi(value)
+		if err != nil || page < 1 {
+			page = 1
+		}
+	}
+
+	start := (page - 1) * limit
+	if start > len(found) {
+		start = len(found)
+	}
+	end := start + limit
+	if end > len(found) {
+		end = len(found)
+	}
+
+	fields := []string{}
+	if value := query.Get("fields"); value != "" {
+		fields = strings.Split(value, ",")
+	}
+
+	records := make([]record, 0, end-start)
+	for _, r := range found[start:end] {
+		records = append(records, selectFields(r, fields))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{resource: records})
+}
+
+// Return a copy of a record with only the given fields, or all of them when
+// none are given.
+func selectFields(r record, fields []string) record {
+	if len(fields) == 0 {
+		return r
+	}
+	selected := record{}
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if value, ok := r[field]; ok {
+			selected[field] = value
+		}
+	}
+	return selected
+}
+
+func (s *Server) count(w http.ResponseWriter, req *http.Request, resource string, filter record) {
+	found, err := s.find(resource, filter, req.URL.Query())
+	if err != nil {
+		writeErrors(w, http.StatusBadRequest, map[string][]string{"base": {err.Error()}})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"count": len(found)})
+}
+
+// Decode the record wrapped in a request body, e.g. {"product": {...}}.
+func decodeRecord(req *http.Request, resource string) (record, error) {
+	body := map[string]record{}
+	err := json.NewDecoder(req.Body).Decode(&body)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := body[resources[resource]]
+	if !ok || r == nil {
+		return nil, fmt.Errorf("%s is required", resources[resource])
+	}
+	return r, nil
+}
+
+func (s *Server) post(w http.ResponseWriter, req *http.Request, resource string, filter record) {
+	r, err := decodeRecord(req, resource)
+	if err != nil {
+		writeErrors(w, http.StatusBadRequest, map[string][]string{resources[resource]: {err.Error()}})
+		return
+	}
+
+	invalid := map[string][]string{}
+	for _, attribute := range requiredAttributes[resource] {
+		if value, ok := r[attribute]; !ok || value == nil || value == "" {
+			invalid[attribute] = []string{"can't be blank"}
+		}
+	}
+	if len(invalid) > 0 {
+		writeErrors(w, http.StatusUnprocessableEntity, invalid)
+		return
+	}
+
+	for k, v := range filter {
+		r[k] = v
+	}
+	delete(r, "id")
+
+	s.mu.Lock()
+	s.create(resource, r)
+	created := r.copy()
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{resources[resource]: created})
+}
+
+func (s *Server) serveRecord(w http.ResponseWriter, req *http.Request, resource string, id uint64, filter record) {
+	s.mu.Lock()
+	r, ok := s.records[resource][id]
+	if ok {
+		r = r.copy()
+	}
+	s.mu.Unlock()
+	if !ok || !matches(r, filter) {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	switch req.Method {
+	case "GET":
+		fields := []string{}
+		if value := req.URL.Query().Get("fields"); value != "" {
+			fields = strings.Split(value, ",")
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{resources[resource]: selectFields(r, fields)})
+	case "PUT":
+		update, err := decodeRecord(req, resource)
+		if err != nil {
+			writeErrors(w, http.StatusBadRequest, map[string][]string{resources[resource]: {err.Error()}})
+			return
+		}
+
+		for k, v := range update {
+			if k != "id" && k != "created_at" {
+				r[k] = v
+			}
+		}
+		r["updated_at"] = time.Now().UTC().Format(time.RFC3339)
+
+		s.mu.Lock()
+		s.records[resource][id] = r
+		s.mu.Unlock()
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{resources[resource]: r})
+	case "DELETE":
+		s.mu.Lock()
+		delete(s.records[resource], id)
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, map[string]interface{}{})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// Write an error with a single message, e.g. {"errors": "Not Found"}
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"errors": message})
+}
+
+// Write errors per attribute, e.g. {"errors": {"title": ["can't be blank"]}}
+func writeErrors(w http.ResponseWriter, status int, errors map[string][]string) {
+	writeJSON(w, status, map[string]interface{}{"errors": errors})
+}
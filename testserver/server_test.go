@@ -0,0 +1,212 @@
+package testserver
+
+import (
+	"testing"
+
+	goshopify "github.com/getconversio/go-shopify"
+)
+
+func newTestClient(s *Server) *goshopify.Client {
+	return s.NewClient(goshopify.App{}, "fooshop", "abcd")
+}
+
+func TestProducts(t *testing.T) {
+	s := New()
+	defer s.Close()
+	client := newTestClient(s)
+
+	product, err := client.Product.Create(goshopify.Product{
+		Title:    "Shirt",
+		Variants: []goshopify.Variant{{Sku: "SHIRT-S"}},
+	})
+	if err != nil {
+		t.Fatalf("Product.Create returned error: %v", err)
+	}
+	if product.ID == 0 || product.CreatedAt == nil {
+		t.Errorf("Product.Create returned %+v, expected an id and timestamps", product)
+	}
+	if len(product.Variants) != 1 || product.Variants[0].ID == 0 || product.Variants[0].ProductID != int(product.ID) {
+		t.Errorf("Product.Create returned variants %+v, expected one with ids", product.Variants)
+	}
+
+	product.Title = "T-Shirt"
+	updated, err := client.Product.Update(*product)
+	if err != nil {
+		t.Fatalf("Product.Update returned error: %v", err)
+	}
+	if updated.Title != "T-Shirt" {
+		t.Errorf("Product.Update returned title %v, expected T-Shirt", updated.Title)
+	}
+
+	got, err := client.Product.Get(product.ID, nil)
+	if err != nil {
+		t.Fatalf("Product.Get returned error: %v", err)
+	}
+	if got.Title != "T-Shirt" {
+		t.Errorf("Product.Get returned title %v, expected T-Shirt", got.Title)
+	}
+
+	err = client.Product.Delete(product.ID)
+	if err != nil {
+		t.Fatalf("Product.Delete returned error: %v", err)
+	}
+	_, err = client.Product.Get(product.ID, nil)
+	if responseError, ok := err.(goshopify.ResponseError); !ok || responseError.Status != 404 || responseError.Message != "Not Found" {
+		t.Errorf("Product.Get of a deleted product returned %#v, expected a 404", err)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	s := New()
+	defer s.Close()
+	client := newTestClient(s)
+
+	for i := 0; i < 7; i++ {
+		s.Add("products", goshopify.Product{Title: "Product"})
+	}
+
+	count, err := client.Product.Count(nil)
+	if err != nil || count != 7 {
+		t.Errorf("Product.Count returned %d, %v, expected 7", count, err)
+	}
+
+	page, err := client.Product.List(goshopify.ListOptions{Page: 2, Limit: 3})
+	if err != nil {
+		t.Fatalf("Product.List returned error: %v", err)
+	}
+	if len(page) != 3 || page[0].ID != 1004 {
+		t.Errorf("Product.List of page 2 returned %d products from %d, expected 3 from 1004", len(page), page[0].ID)
+	}
+
+	page, err = client.Product.List(goshopify.ListOptions{SinceID: 1005, Fields: "id"})
+	if err != nil {
+		t.Fatalf("Product.List returned error: %v", err)
+	}
+	if len(page) != 2 || page[0].ID != 1006 || page[0].Title != "" {
+		t.Errorf("Product.List since 1005 returned %+v, expected products 1006 and 1007 with ids only", page)
+	}
+
+	_, err = client.Product.List(goshopify.ListOptions{Limit: 251})
+	if responseError, ok := err.(goshopify.ResponseError); !ok || responseError.Status != 400 {
+		t.Errorf("Product.List with a limit of 251 returned %#v, expected a 400", err)
+	}
+}
+
+func TestOrderStatus(t *testing.T) {
+	s := New()
+	defer s.Close()
+	client := newTestClient(s)
+
+	s.Add("orders", goshopify.Order{Email: "open@example.com"})
+	s.Add("orders", map[string]interface{}{"email": "cancelled@example.com", "cancelled_at": "2017-01-01T00:00:00Z"})
+
+	orders, err := client.Order.List(nil)
+	if err != nil || len(orders) != 1 || orders[0].Email != "open@example.com" {
+		t.Errorf("Order.List returned %+v, %v, expected the open order", orders, err)
+	}
+
+	count, err := client.Order.Count(goshopify.OrderCountOptions{Status: "any"})
+	if err != nil || count != 2 {
+		t.Errorf("Order.Count with status any returned %d, %v, expected 2", count, err)
+	}
+}
+
+func TestMetafields(t *testing.T) {
+	s := New()
+	defer s.Close()
+	client := newTestClient(s)
+
+	productID, _ := s.Add("products", goshopify.Product{Title: "Shirt"})
+	otherID, _ := s.Add("products", goshopify.Product{Title: "Hat"})
+
+	metafield, err := client.Product.CreateMetafield(productID, goshopify.Metafield{
+		Namespace: "inventory",
+		Key:       "warehouse",
+		Value:     "25",
+		ValueType: "string",
+	})
+	if err != nil {
+		t.Fatalf("Product.CreateMetafield returned error: %v", err)
+	}
+	if metafield.OwnerId != int(productID) || metafield.OwnerResource != "product" {
+		t.Errorf("Product.CreateMetafield returned %+v, expected it to be owned by product %d", metafield, productID)
+	}
+
+	metafields, err := client.Product.ListMetafields(productID, nil)
+	if err != nil || len(metafields) != 1 {
+		t.Errorf("Product.ListMetafields returned %+v, %v, expected 1 metafield", metafields, err)
+	}
+
+	metafields, err = client.Product.ListMetafields(otherID, nil)
+	if err != nil || len(metafields) != 0 {
+		t.Errorf("Product.ListMetafields of another product returned %+v, %v, expected none", metafields, err)
+	}
+
+	_, err = client.Product.GetMetafield(otherID, metafield.ID, nil)
+	if responseError, ok := err.(goshopify.ResponseError); !ok || responseError.Status != 404 {
+		t.Errorf("Product.GetMetafield of another product returned %#v, expected a 404", err)
+	}
+}
+
+func TestWebhooksValidation(t *testing.T) {
+	s := New()
+	defer s.Close()
+	client := newTestClient(s)
+
+	_, err := client.Webhook.Create(goshopify.Webhook{Topic: "orders/create"})
+	responseError, ok := err.(goshopify.ResponseError)
+	if !ok || responseError.Status != 422 || responseError.Message != "address: can't be blank" {
+		t.Errorf("Webhook.Create returned %#v, expected a 422 for the address", err)
+	}
+
+	webhook, err := client.Webhook.Create(goshopify.Webhook{Topic: "orders/create", Address: "https://example.com/hook"})
+	if err != nil {
+		t.Fatalf("Webhook.Create returned error: %v", err)
+	}
+	if s.Count("webhooks") != 1 {
+		t.Errorf("Server.Count returned %d webhooks, expected 1", s.Count("webhooks"))
+	}
+
+	stored := goshopify.Webhook{}
+	ok, err = s.Get("webhooks", uint64(webhook.ID), &stored)
+	if !ok || err != nil || stored.Address != "https://example.com/hook" {
+		t.Errorf("Server.Get returned %+v, %v, %v, expected the webhook", stored, ok, err)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	s := New()
+	defer s.Close()
+	s.BucketSize = 2
+	s.LeakRate = 0
+	client := newTestClient(s)
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Shop.Get(nil)
+		if err != nil {
+			t.Fatalf("Shop.Get returned error: %v", err)
+		}
+	}
+
+	_, err := client.Shop.Get(nil)
+	rateLimitError, ok := err.(goshopify.RateLimitError)
+	if !ok || rateLimitError.RetryAfter != 2 {
+		t.Errorf("Shop.Get returned %#v, expected a RateLimitError to retry after 2 seconds", err)
+	}
+}
+
+func TestToken(t *testing.T) {
+	s := New()
+	defer s.Close()
+	s.Token = "secret"
+
+	_, err := newTestClient(s).Shop.Get(nil)
+	if responseError, ok := err.(goshopify.ResponseError); !ok || responseError.Status != 401 {
+		t.Errorf("Shop.Get with the wrong token returned %#v, expected a 401", err)
+	}
+
+	shop, err := s.NewClient(goshopify.App{}, "fooshop", "secret").Shop.Get(nil)
+	if err != nil || shop.Name != "Test Shop" {
+		t.Errorf("Shop.Get returned %+v, %v, expected the test shop", shop, err)
+	}
+}
@@ -0,0 +1,178 @@
+package goshopify
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// The tracking companies Shopify recognizes in a fulfillment's
+// tracking_company. For other companies Shopify cannot link to the
+// tracking page, so the tracking_url has to be given.
+const (
+	TrackingCompany4PX               = "4PX"
+	TrackingCompanyAPC               = "APC"
+	TrackingCompanyAmazonLogisticsUK = "Amazon Logistics UK"
+	TrackingCompanyAmazonLogisticsUS = "Amazon Logistics US"
+	TrackingCompanyAnjunLogistics    = "Anjun Logistics"
+	TrackingCompanyAustraliaPost     = "Australia Post"
+	TrackingCompanyBluedart          = "Bluedart"
+	TrackingCompanyCanadaPost        = "Canada Post"
+	TrackingCompanyCanpar            = "Canpar"
+	TrackingCompanyChinaPost         = "China Post"
+	TrackingCompanyChukou1           = "Chukou1"
+	TrackingCompanyCorreios          = "Correios"
+	TrackingCompanyCouriersPlease    = "Couriers Please"
+	TrackingCompanyDHLExpress        = "DHL Express"
+	TrackingCompanyDHLECommerce      = "DHL eCommerce"
+	TrackingCompanyDHLECommerceAsia  = "DHL eCommerce Asia"
+	TrackingCompanyDPD               = "DPD"
+	TrackingCompanyDPDLocal          = "DPD Local"
+	TrackingCompanyDPDUK             = "DPD UK"
+	TrackingCompanyDelhivery         = "Delhivery"
+	TrackingCompanyEagle             = "Eagle"
+	TrackingCompanyFSC               = "FSC"
+	TrackingCompanyFastwayAustralia  = "Fastway Australia"
+	TrackingCompanyFedEx             = "FedEx"
+	TrackingCompanyGLS               = "GLS"
+	TrackingCompanyGLSUS             = "GLS (US)"
+	TrackingCompanyGlobegistics      = "Globegistics"
+	TrackingCompanyJapanPostEN       = "Japan Post (EN)"
+	TrackingCompanyJapanPostJA       = "Japan Post (JA)"
+	TrackingCompanyLaPoste           = "La Poste"
+	TrackingCompanyNewZealandPost    = "New Zealand Post"
+	TrackingCompanyNewgistics        = "Newgistics"
+	TrackingCompanyPostNL            = "PostNL"
+	TrackingCompanyPostNord          = "PostNord"
+	TrackingCompanyPurolator         = "Purolator"
+	TrackingCompanyRoyalMail         = "Royal Mail"
+	TrackingCompanySFExpress         = "SF Express"
+	TrackingCompanySFCFulfillment    = "SFC Fulfillment"
+	TrackingCompanySagawaEN          = "Sagawa (EN)"
+	TrackingCompanySagawaJA          = "Sagawa (JA)"
+	TrackingCompanySingaporePost     = "Singapore Post"
+	TrackingCompanyStarTrack         = "StarTrack"
+	TrackingCompanyTNT               = "TNT"
+	TrackingCompanyTollIPEC          = "Toll IPEC"
+	TrackingCompanyUPS               = "UPS"
+	TrackingCompanyUSPS              = "USPS"
+	TrackingCompanyWhistl            = "Whistl"
+	TrackingCompanyYamatoEN          = "Yamato (EN)"
+	TrackingCompanyYamatoJA          = "Yamato (JA)"
+	TrackingCompanyYunExpress        = "YunExpress"
+)
+
+// TrackingCompanies lists the tracking companies Shopify recognizes.
+var TrackingCompanies = []string{
+	TrackingCompany4PX, TrackingCompanyAPC, TrackingCompanyAmazonLogisticsUK,
+	TrackingCompanyAmazonLogisticsUS, TrackingCompanyAnjunLogistics,
+	TrackingCompanyAustraliaPost, TrackingCompanyBluedart,
+	TrackingCompanyCanadaPost, TrackingCompanyCanpar, TrackingCompanyChinaPost,
+	TrackingCompanyChukou1, TrackingCompanyCorreios,
+	TrackingCompanyCouriersPlease, TrackingCompanyDHLExpress,
+	TrackingCompanyDHLECommerce, TrackingCompanyDHLECommerceAsia,
+	TrackingCompanyDPD, TrackingCompanyDPDLocal, TrackingCompanyDPDUK,
+	TrackingCompanyDelhivery, TrackingCompanyEagle, TrackingCompanyFSC,
+	TrackingCompanyFastwayAustralia, TrackingCompanyFedEx, TrackingCompanyGLS,
+	TrackingCompanyGLSUS, TrackingCompanyGlobegistics,
+	TrackingCompanyJapanPostEN, TrackingCompanyJapanPostJA,
+	TrackingCompanyLaPoste, TrackingCompanyNewZealandPost,
+	TrackingCompanyNewgistics, TrackingCompanyPostNL, TrackingCompanyPostNord,
+	TrackingCompanyPurolator, TrackingCompanyRoyalMail,
+	TrackingCompanySFExpress, TrackingCompanySFCFulfillment,
+	TrackingCompanySagawaEN, TrackingCompanySagawaJA,
+	TrackingCompanySingaporePost, TrackingCompanyStarTrack,
+	TrackingCompanyTNT, TrackingCompanyTollIPEC, TrackingCompanyUPS,
+	TrackingCompanyUSPS, TrackingCompanyWhistl, TrackingCompanyYamatoEN,
+	TrackingCompanyYamatoJA, TrackingCompanyYunExpress,
+}
+
+// Other names the tracking companies go by, keyed by their trackingKey
+var trackingCompanyAliases = map[string]string{
+	"dhl":                       TrackingCompanyDHLExpress,
+	"federalexpress":            TrackingCompanyFedEx,
+	"unitedparcelservice":       TrackingCompanyUPS,
+	"unitedstatespostalservice": TrackingCompanyUSPS,
+	"auspost":                   TrackingCompanyAustraliaPost,
+	"postescanada":              TrackingCompanyCanadaPost,
+	"nzpost":                    TrackingCompanyNewZealandPost,
+	"japanpost":                 TrackingCompanyJapanPostEN,
+	"sagawa":                    TrackingCompanySagawaEN,
+	"yamato":                    TrackingCompanyYamatoEN,
+	"singpost":                  TrackingCompanySingaporePost,
+}
+
+// Tracking page URLs of the companies, with {number} standing for the
+// tracking number
+var trackingURLs = map[string]string{
+	TrackingCompanyAustraliaPost:  "https://auspost.com.au/mypost/track/#/details/{number}",
+	TrackingCompanyCanadaPost:     "https://www.canadapost.ca/trackweb/en#/search?searchFor={number}",
+	TrackingCompanyDHLExpress:     "https://www.dhl.com/en/express/tracking.html?AWB={number}",
+	TrackingCompanyDPDUK:          "https://www.dpd.co.uk/apps/tracking/?reference={number}",
+	TrackingCompanyFedEx:          "https://www.fedex.com/fedextrack/?tracknumbers={number}",
+	TrackingCompanyJapanPostEN:    "https://trackings.post.japanpost.jp/services/srv/search/?requestNo1={number}&locale=en",
+	TrackingCompanyJapanPostJA:    "https://trackings.post.japanpost.jp/services/srv/search/?requestNo1={number}&locale=ja",
+	TrackingCompanyLaPoste:        "https://www.laposte.fr/outils/suivre-vos-envois?code={number}",
+	TrackingCompanyNewZealandPost: "https://www.nzpost.co.nz/tools/tracking?trackid={number}",
+	TrackingCompanyPurolator:      "https://www.purolator.com/en/shipping/tracker?pin={number}",
+	TrackingCompanyRoyalMail:      "https://www.royalmail.com/track-your-item#/tracking-results/{number}",
+	TrackingCompanyStarTrack:      "https://startrack.com.au/track/details/{number}",
+	TrackingCompanyUPS:            "https://www.ups.com/track?tracknum={number}",
+	TrackingCompanyUSPS:           "https://tools.usps.com/go/TrackConfirmAction?tLabels={number}",
+}
+
+// The letters and digits of a name in lower case, e.g. "glsus" for
+// "GLS (US)".
+func trackingKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// IsTrackingCompany returns whether Shopify recognizes the tracking company,
+// which has to be spelled exactly as in TrackingCompanies.
+func IsTrackingCompany(name string) bool {
+	for _, company := range TrackingCompanies {
+		if company == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeTrackingCompany returns the name Shopify recognizes for a tracking
+// company regardless of case, spacing and punctuation, or some of the other
+// names it goes by, e.g. "FedEx" for "fedex" or "Federal Express". It
+// returns an error for companies Shopify does not recognize.
+func NormalizeTrackingCompany(name string) (string, error) {
+	key := trackingKey(name)
+	if company, ok := trackingCompanyAliases[key]; ok {
+		return company, nil
+	}
+	for _, company := range TrackingCompanies {
+		if trackingKey(company) == key {
+			return company, nil
+		}
+	}
+	return "", fmt.Errorf("%v is not a tracking company Shopify recognizes", name)
+}
+
+// TrackingURL returns the tracking page of a shipment with a tracking
+// company, which may be given by any name NormalizeTrackingCompany accepts. It
+// returns an empty string when the tracking page of the company is not known.
+func TrackingURL(company, number string) string {
+	company, err := NormalizeTrackingCompany(company)
+	if err != nil {
+		return ""
+	}
+	template, ok := trackingURLs[company]
+	number = strings.Replace(number, " ", "", -1)
+	if !ok || number == "" {
+		return ""
+	}
+	return strings.Replace(template, "{number}", url.QueryEscape(number), 1)
+}
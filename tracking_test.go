@@ -0,0 +1,78 @@
+package goshopify
+
+import (
+	"testing"
+)
+
+func TestIsTrackingCompany(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected bool
+	}{
+		{"FedEx", true},
+		{"GLS (US)", true},
+		{"fedex", false},
+		{"Pony Express", false},
+	}
+
+	for _, c := range cases {
+		actual := IsTrackingCompany(c.name)
+		if actual != c.expected {
+			t.Errorf("IsTrackingCompany(%q): expected %v, actual %v", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestNormalizeTrackingCompany(t *testing.T) {
+	cases := []struct {
+		name, expected string
+	}{
+		{"fedex", TrackingCompanyFedEx},
+		{"Fed-Ex", TrackingCompanyFedEx},
+		{"Federal Express", TrackingCompanyFedEx},
+		{"dhl", TrackingCompanyDHLExpress},
+		{"dhl ecommerce", TrackingCompanyDHLECommerce},
+		{"gls us", TrackingCompanyGLSUS},
+		{"ROYAL MAIL", TrackingCompanyRoyalMail},
+	}
+
+	for _, c := range cases {
+		actual, err := NormalizeTrackingCompany(c.name)
+		if err != nil || actual != c.expected {
+			t.Errorf("NormalizeTrackingCompany(%q): expected %q, actual %q, %v", c.name, c.expected, actual, err)
+		}
+	}
+
+	_, err := NormalizeTrackingCompany("Pony Express")
+	if err == nil {
+		t.Errorf("NormalizeTrackingCompany(%q) returned no error", "Pony Express")
+	}
+}
+
+func TestTrackingURL(t *testing.T) {
+	cases := []struct {
+		company, number, expected string
+	}{
+		{"UPS", "1Z 999 AA1 01 2345 6784", "https://www.ups.com/track?tracknum=1Z999AA10123456784"},
+		{"usps", "9400111899223197428490", "https://tools.usps.com/go/TrackConfirmAction?tLabels=9400111899223197428490"},
+		{"Canada Post", "7023210039414604", "https://www.canadapost.ca/trackweb/en#/search?searchFor=7023210039414604"},
+		{"Chukou1", "CK123", ""},
+		{"Pony Express", "123", ""},
+		{"UPS", "", ""},
+	}
+
+	for _, c := range cases {
+		actual := TrackingURL(c.company, c.number)
+		if actual != c.expected {
+			t.Errorf("TrackingURL(%q, %q): expected %q, actual %q", c.company, c.number, c.expected, actual)
+		}
+	}
+}
+
+func TestTrackingURLsOfTrackingCompanies(t *testing.T) {
+	for company := range trackingURLs {
+		if !IsTrackingCompany(company) {
+			t.Errorf("Tracking URL of %q, which is not a tracking company", company)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package goshopify
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 )
 
@@ -39,3 +40,59 @@ func MetafieldPathPrefix(resource string, resourceID uint64) string {
 	}
 	return prefix
 }
+
+// Return the value for the fields option that selects just the attributes
+// of the given model, so that Shopify only sends what the model can hold.
+// The model is a struct, a pointer to one or a slice of them, and its
+// attribute names are read from the json tags. Fields without a name in
+// their tag are left out, as they do not stand for an attribute of Shopify's.
+// A []string is used as the list of attribute names itself.
+func FieldsOf(model interface{}) string {
+	if names, ok := model.([]string); ok {
+		return strings.Join(names, ",")
+	}
+
+	t := reflect.TypeOf(model)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return ""
+	}
+	return strings.Join(fieldNames(t), ",")
+}
+
+// Return the json names of the fields of a struct type, including those of
+// embedded structs. Fields that are not named by their json tag are skipped.
+func fieldNames(t reflect.Type) []string {
+	names := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				names = append(names, fieldNames(embedded)...)
+				continue
+			}
+		}
+
+		// Unexported fields are not decoded
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -80,3 +80,37 @@ func TestMetafieldPathPrefix(t *testing.T) {
 		}
 	}
 }
+
+func TestFieldsOf(t *testing.T) {
+	type Timestamps struct {
+		CreatedAt string `json:"created_at"`
+	}
+	type handleOnly struct {
+		Timestamps
+		ID       uint64 `json:"id,omitempty"`
+		Handle   string `json:"handle"`
+		Title    string
+		Note     string `json:",omitempty"`
+		Ignored  string `json:"-"`
+		internal string
+	}
+
+	cases := []struct {
+		in       interface{}
+		expected string
+	}{
+		{handleOnly{}, "created_at,id,handle"},
+		{&handleOnly{}, "created_at,id,handle"},
+		{[]handleOnly{}, "created_at,id,handle"},
+		{[]string{"id", "handle"}, "id,handle"},
+		{"id", ""},
+		{nil, ""},
+	}
+
+	for _, c := range cases {
+		actual := FieldsOf(c.in)
+		if actual != c.expected {
+			t.Errorf("FieldsOf(%T): expected %s, actual %s", c.in, c.expected, actual)
+		}
+	}
+}
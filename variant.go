@@ -49,6 +49,7 @@ type Variant struct {
 	Barcode              string           `json:"barcode,omitempty"`
 	ImageID              int              `json:"image_id,omitempty"`
 	InventoryQuantity    int              `json:"inventory_quantity,omitempty"`
+	InventoryItemID      int              `json:"inventory_item_id,omitempty"`
 	Weight               *decimal.Decimal `json:"weight,omitempty"`
 	WeightUnit           string           `json:"weight_unit,omitempty"`
 	OldInventoryQuantity int              `json:"old_inventory_quantity,omitempty"`
@@ -0,0 +1,164 @@
+package goshopify
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Webhooks listed per request when reconciling
+const ensureWebhooksPageLimit = 250
+
+// WebhookSpec is a webhook subscription an app wants to have. Format defaults
+// to json.
+type WebhookSpec struct {
+	Topic               string
+	Address             string
+	Format              string
+	Fields              []string
+	MetafieldNamespaces []string
+}
+
+// WebhookChanges are the changes EnsureWebhooks made.
+type WebhookChanges struct {
+	Created []Webhook
+	Updated []Webhook
+	Deleted []Webhook
+}
+
+// EnsureWebhooks makes the shop's webhook subscriptions match the desired
+// ones: missing subscriptions are created, subscriptions to a desired topic
+// with another address, format or fields are updated, and all others are
+// deleted. Running it again with the same specs changes nothing, so it can be
+// run every time an app starts. The changes made so far are returned along
+// with an error.
+//
+// Deleting subscriptions is refused by the production safeguard, so with the
+// safeguard EnsureWebhooks needs a client confirmed with Confirm whenever it
+// would delete a subscription, and otherwise returns a SafeguardError before
+// making any change.
+func EnsureWebhooks(c *Client, desired []WebhookSpec) (WebhookChanges, error) {
+	changes := WebhookChanges{}
+
+	existing := []Webhook{}
+	options := ListOptions{Limit: ensureWebhooksPageLimit}
+	for {
+		webhooks, err := c.Webhook.List(options)
+		if err != nil {
+			return changes, err
+		}
+		existing = append(existing, webhooks...)
+		if len(webhooks) < ensureWebhooksPageLimit {
+			break
+		}
+		options.SinceID = webhooks[len(webhooks)-1].ID
+	}
+
+	// Match the specs to subscriptions with the same topic and address
+	// first, so that only what changed is updated, and then to the remaining
+	// subscriptions to the same topic.
+	claimed := make([]bool, len(existing))
+	matches := make([]int, len(desired))
+	for i, spec := range desired {
+		matches[i] = -1
+		for j, webhook := range existing {
+			if !claimed[j] && webhook.Topic == spec.Topic && webhook.Address == spec.Address {
+				matches[i], claimed[j] = j, true
+				break
+			}
+		}
+	}
+	for i, spec := range desired {
+		for j, webhook := range existing {
+			if matches[i] == -1 && !claimed[j] && webhook.Topic == spec.Topic {
+				matches[i], claimed[j] = j, true
+			}
+		}
+	}
+
+	// Check the safeguard before any change, so that a refused delete does
+	// not leave the subscriptions half reconciled
+	for _, isClaimed := range claimed {
+		if !isClaimed {
+			err := c.guard("webhook reconciliation")
+			if err != nil {
+				return changes, err
+			}
+			break
+		}
+	}
+
+	for i, spec := range desired {
+		webhook := spec.webhook()
+		if matches[i] == -1 {
+			created, err := c.Webhook.Create(webhook)
+			if err != nil {
+				return changes, err
+			}
+			changes.Created = append(changes.Created, *created)
+			continue
+		}
+
+		current := existing[matches[i]]
+		if spec.matches(current) {
+			continue
+		}
+		webhook.ID = current.ID
+		updated, err := c.Webhook.Update(webhook)
+		if err != nil {
+			return changes, err
+		}
+		changes.Updated = append(changes.Updated, *updated)
+	}
+
+	for j, webhook := range existing {
+		if claimed[j] {
+			continue
+		}
+		err := c.Webhook.Delete(webhook.ID)
+		if err != nil {
+			return changes, err
+		}
+		changes.Deleted = append(changes.Deleted, webhook)
+	}
+
+	return changes, nil
+}
+
+func (spec WebhookSpec) webhook() Webhook {
+	format := spec.Format
+	if format == "" {
+		format = "json"
+	}
+	return Webhook{
+		Topic:               spec.Topic,
+		Address:             spec.Address,
+		Format:              format,
+		Fields:              spec.Fields,
+		MetafieldNamespaces: spec.MetafieldNamespaces,
+	}
+}
+
+// Whether a subscription is what the spec asks for.
+func (spec WebhookSpec) matches(webhook Webhook) bool {
+	wanted := spec.webhook()
+	return webhook.Topic == wanted.Topic &&
+		webhook.Address == wanted.Address &&
+		webhook.Format == wanted.Format &&
+		sameStrings(webhook.Fields, wanted.Fields) &&
+		sameStrings(webhook.MetafieldNamespaces, wanted.MetafieldNamespaces)
+}
+
+// Whether two lists hold the same strings in any order.
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	if len(a) == 0 {
+		return true
+	}
+	a = append([]string{}, a...)
+	b = append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return reflect.DeepEqual(a, b)
+}
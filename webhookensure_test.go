@@ -0,0 +1,122 @@
+package goshopify
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+func TestEnsureWebhooks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/webhooks.json",
+		httpmock.NewStringResponder(200, `{"webhooks": [
+			{"id": 1, "topic": "orders/create", "address": "https://example.com/orders", "format": "json", "fields": ["updated_at", "id"]},
+			{"id": 2, "topic": "products/update", "address": "https://old.example.com/products", "format": "json"},
+			{"id": 3, "topic": "app/uninstalled", "address": "https://example.com/uninstalled", "format": "json"},
+			{"id": 4, "topic": "orders/create", "address": "https://example.com/orders-copy", "format": "json"}
+		]}`))
+
+	var created, updated []Webhook
+	deletes := 0
+	echo := func(webhooks *[]Webhook, status int) httpmock.Responder {
+		return func(req *http.Request) (*http.Response, error) {
+			resource := WebhookResource{}
+			json.NewDecoder(req.Body).Decode(&resource)
+			*webhooks = append(*webhooks, *resource.Webhook)
+			return httpmock.NewJsonResponse(status, resource)
+		}
+	}
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/webhooks.json", echo(&created, 201))
+	httpmock.RegisterResponder("PUT", "https://fooshop.myshopify.com/admin/webhooks/2.json", echo(&updated, 200))
+	for _, id := range []string{"1", "2", "3", "4"} {
+		httpmock.RegisterResponder("DELETE", "https://fooshop.myshopify.com/admin/webhooks/"+id+".json",
+			func(req *http.Request) (*http.Response, error) {
+				deletes++
+				return httpmock.NewStringResponse(200, "{}"), nil
+			})
+	}
+
+	changes, err := EnsureWebhooks(client, []WebhookSpec{
+		{Topic: "orders/create", Address: "https://example.com/orders", Fields: []string{"id", "updated_at"}},
+		{Topic: "products/update", Address: "https://example.com/products"},
+		{Topic: "customers/create", Address: "https://example.com/customers"},
+	})
+	if err != nil {
+		t.Fatalf("EnsureWebhooks returned error: %v", err)
+	}
+
+	if len(created) != 1 || created[0].Topic != "customers/create" || created[0].Format != "json" {
+		t.Errorf("EnsureWebhooks created %+v, expected the customers/create webhook", created)
+	}
+	if len(updated) != 1 || updated[0].ID != 2 || updated[0].Address != "https://example.com/products" {
+		t.Errorf("EnsureWebhooks updated %+v, expected webhook 2 with the new address", updated)
+	}
+
+	deletedIDs := []int{}
+	for _, webhook := range changes.Deleted {
+		deletedIDs = append(deletedIDs, webhook.ID)
+	}
+	if len(deletedIDs) != 2 || deletedIDs[0] != 3 || deletedIDs[1] != 4 || deletes != 2 {
+		t.Errorf("EnsureWebhooks deleted %v, expected webhooks 3 and 4", deletedIDs)
+	}
+	if len(changes.Created) != 1 || len(changes.Updated) != 1 {
+		t.Errorf("EnsureWebhooks returned changes %+v", changes)
+	}
+}
+
+func TestEnsureWebhooksUnchanged(t *testing.T) {
+	setup()
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/webhooks.json",
+		httpmock.NewBytesResponder(200, loadFixture("webhooks.json")))
+
+	changes, err := EnsureWebhooks(client, []WebhookSpec{{
+		Topic:               "orders/create",
+		Address:             "http://apple.com",
+		Fields:              []string{"updated_at", "id"},
+		MetafieldNamespaces: []string{"inventory", "google"},
+	}})
+	if err != nil {
+		t.Errorf("EnsureWebhooks returned error: %v", err)
+	}
+	if len(changes.Created)+len(changes.Updated)+len(changes.Deleted) != 0 {
+		t.Errorf("EnsureWebhooks made changes %+v, expected none", changes)
+	}
+}
+
+func TestEnsureWebhooksSafeguard(t *testing.T) {
+	setupSafeguard("enterprise")
+	defer teardown()
+
+	httpmock.RegisterResponder("GET", "https://fooshop.myshopify.com/admin/webhooks.json",
+		httpmock.NewStringResponder(200, `{"webhooks": [
+			{"id": 3, "topic": "app/uninstalled", "address": "https://example.com/uninstalled", "format": "json"}
+		]}`))
+	changed := 0
+	change := func(req *http.Request) (*http.Response, error) {
+		changed++
+		return httpmock.NewStringResponse(201, `{"webhook": {"id": 5}}`), nil
+	}
+	httpmock.RegisterResponder("POST", "https://fooshop.myshopify.com/admin/webhooks.json", change)
+	httpmock.RegisterResponder("DELETE", "https://fooshop.myshopify.com/admin/webhooks/3.json", change)
+
+	specs := []WebhookSpec{{Topic: "customers/create", Address: "https://example.com/customers"}}
+	_, err := EnsureWebhooks(client, specs)
+	if _, ok := err.(SafeguardError); !ok || changed != 0 {
+		t.Fatalf("EnsureWebhooks returned %v after %d changes, expected a SafeguardError before any change", err, changed)
+	}
+
+	var changes WebhookChanges
+	err = client.Confirm(func(c *Client) error {
+		changes, err = EnsureWebhooks(c, specs)
+		return err
+	})
+	if err != nil || len(changes.Created) != 1 || len(changes.Deleted) != 1 {
+		t.Errorf("EnsureWebhooks with a confirmed client returned %+v, %v, expected a webhook created and deleted", changes, err)
+	}
+}
@@ -0,0 +1,260 @@
+package goshopify
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Types the payloads of webhooks are decoded into, by the resource of their
+// topic, e.g. "orders" for "orders/create"
+var webhookPayloadTypes = map[string]reflect.Type{
+	"app":                reflect.TypeOf(Shop{}),
+	"customers":          reflect.TypeOf(Customer{}),
+	"fulfillments":       reflect.TypeOf(Fulfillment{}),
+	"order_transactions": reflect.TypeOf(Transaction{}),
+	"orders":             reflect.TypeOf(Order{}),
+	"products":           reflect.TypeOf(Product{}),
+	"refunds":            reflect.TypeOf(Refund{}),
+	"shop":               reflect.TypeOf(Shop{}),
+	"themes":             reflect.TypeOf(Theme{}),
+}
+
+// WebhookEvent is a webhook received from Shopify. Payload holds the body
+// decoded into a pointer to the model of the topic, e.g. *Order for
+// "orders/create", or a map[string]interface{} for topics without a model.
+type WebhookEvent struct {
+	Topic      string
+	ShopDomain string
+	WebhookID  string
+	APIVersion string
+	Body       []byte
+	Payload    interface{}
+}
+
+// WebhookHandlerFunc handles a webhook. When it returns an error Shopify is
+// told the webhook failed, and sends it again later.
+type WebhookHandlerFunc func(event *WebhookEvent) error
+
+// WebhookMiddleware wraps the handlers of a router, e.g. to log webhooks.
+type WebhookMiddleware func(next WebhookHandlerFunc) WebhookHandlerFunc
+
+// WebhookRouter is an http.Handler that receives webhooks, verifies that they
+// come from Shopify and passes them to the handler of their topic. Webhooks of
+// topics without a handler are acknowledged and dropped.
+type WebhookRouter struct {
+	app        App
+	mu         sync.RWMutex
+	handlers   map[string]WebhookHandlerFunc
+	middleware []WebhookMiddleware
+}
+
+// NewWebhookRouter returns a router verifying webhooks with the app's secret.
+func NewWebhookRouter(app App) *WebhookRouter {
+	return &WebhookRouter{
+		app:      app,
+		handlers: make(map[string]WebhookHandlerFunc),
+	}
+}
+
+// Handle sets the handler of a topic, e.g. "orders/create".
+func (r *WebhookRouter) Handle(topic string, fn WebhookHandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[topic] = fn
+}
+
+// Use adds middleware to all handlers. The middleware added first runs first.
+func (r *WebhookRouter) Use(middleware ...WebhookMiddleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middleware = append(r.middleware, middleware...)
+}
+
+func (r *WebhookRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !r.app.VerifyWebhookRequest(req) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	event := &WebhookEvent{
+		Topic:      req.Header.Get("X-Shopify-Topic"),
+		ShopDomain: req.Header.Get("X-Shopify-Shop-Domain"),
+		WebhookID:  req.Header.Get("X-Shopify-Webhook-Id"),
+		APIVersion: req.Header.Get("X-Shopify-API-Version"),
+	}
+	if event.Topic == "" {
+		http.Error(w, "Missing X-Shopify-Topic", http.StatusBadRequest)
+		return
+	}
+
+	r.mu.RLock()
+	fn, ok := r.handlers[event.Topic]
+	middleware := r.middleware
+	r.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, "Cannot read body", http.StatusBadRequest)
+		return
+	}
+	event.Body = body
+
+	event.Payload, err = decodeWebhookPayload(event.Topic, body)
+	if err != nil {
+		http.Error(w, "Cannot decode payload", http.StatusBadRequest)
+		return
+	}
+
+	for i := len(middleware) - 1; i >= 0; i-- {
+		fn = middleware[i](fn)
+	}
+
+	err = fn(event)
+	if err != nil {
+		http.Error(w, "Webhook failed", http.StatusInternalServerError)
+	}
+}
+
+// Decode a webhook body into the model of its topic.
+func decodeWebhookPayload(topic string, body []byte) (interface{}, error) {
+	resource := topic
+	if i := strings.Index(topic, "/"); i >= 0 {
+		resource = topic[:i]
+	}
+
+	var payload interface{}
+	if t, ok := webhookPayloadTypes[resource]; ok {
+		payload = reflect.New(t).Interface()
+	} else {
+		payload = &map[string]interface{}{}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	err := decoder.Decode(payload)
+	if m, ok := payload.(*map[string]interface{}); ok {
+		return *m, err
+	}
+	return payload, err
+}
+
+// LogWebhooks logs the topic, shop and outcome of every webhook to logger.
+func LogWebhooks(logger *log.Logger) WebhookMiddleware {
+	return func(next WebhookHandlerFunc) WebhookHandlerFunc {
+		return func(event *WebhookEvent) error {
+			start := time.Now()
+			err := next(event)
+			if err != nil {
+				logger.Printf("webhook %s %s from %s failed after %v: %v", event.WebhookID, event.Topic, event.ShopDomain, time.Since(start), err)
+			} else {
+				logger.Printf("webhook %s %s from %s handled in %v", event.WebhookID, event.Topic, event.ShopDomain, time.Since(start))
+			}
+			return err
+		}
+	}
+}
+
+// DedupWebhooks skips webhooks with the X-Shopify-Webhook-Id of a webhook
+// that arrived within ttl and was handled or is being handled, as Shopify may
+// deliver a webhook more than once. Webhooks that failed are handled again.
+func DedupWebhooks(ttl time.Duration) WebhookMiddleware {
+	dedup := newWebhookDedup(ttl)
+
+	return func(next WebhookHandlerFunc) WebhookHandlerFunc {
+		return func(event *WebhookEvent) error {
+			if event.WebhookID == "" {
+				return next(event)
+			}
+			if !dedup.claim(event.WebhookID, time.Now()) {
+				return nil
+			}
+
+			err := next(event)
+			if err != nil {
+				dedup.release(event.WebhookID)
+			}
+			return err
+		}
+	}
+}
+
+// The ids of the webhooks seen within a ttl, in the order they arrived so
+// that the expired ones are found without looking at the others
+type webhookDedup struct {
+	ttl   time.Duration
+	mu    sync.Mutex
+	ids   map[string]*list.Element
+	order *list.List
+}
+
+type webhookArrival struct {
+	id string
+	at time.Time
+}
+
+func newWebhookDedup(ttl time.Duration) *webhookDedup {
+	return &webhookDedup{ttl: ttl, ids: make(map[string]*list.Element), order: list.New()}
+}
+
+// Record the arrival of a webhook and report whether it is the first within
+// the ttl.
+func (d *webhookDedup) claim(id string, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for front := d.order.Front(); front != nil; front = d.order.Front() {
+		arrival := front.Value.(webhookArrival)
+		if now.Sub(arrival.at) <= d.ttl {
+			break
+		}
+		d.order.Remove(front)
+		delete(d.ids, arrival.id)
+	}
+
+	if _, ok := d.ids[id]; ok {
+		return false
+	}
+	d.ids[id] = d.order.PushBack(webhookArrival{id: id, at: now})
+	return true
+}
+
+// Forget a webhook, so that it is handled again when it is delivered again.
+func (d *webhookDedup) release(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if element, ok := d.ids[id]; ok {
+		d.order.Remove(element)
+		delete(d.ids, id)
+	}
+}
+
+// AckWebhooks acknowledges webhooks right away and handles them in the
+// background, so that slow handlers do not make Shopify time out and send
+// them again. Errors are passed to onError, which may be nil.
+func AckWebhooks(onError func(event *WebhookEvent, err error)) WebhookMiddleware {
+	return func(next WebhookHandlerFunc) WebhookHandlerFunc {
+		return func(event *WebhookEvent) error {
+			go func() {
+				err := next(event)
+				if err != nil && onError != nil {
+					onError(event, err)
+				}
+			}()
+			return nil
+		}
+	}
+}
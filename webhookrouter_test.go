@@ -0,0 +1,249 @@
+package goshopify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Deliver a webhook to the router, signed with the app's secret.
+func deliverWebhook(router http.Handler, topic, id string, body []byte) int {
+	mac := hmac.New(sha256.New, []byte(app.ApiSecret))
+	mac.Write(body)
+
+	req := httptest.NewRequest("POST", "/webhooks", bytes.NewReader(body))
+	req.Header.Set("X-Shopify-Topic", topic)
+	req.Header.Set("X-Shopify-Shop-Domain", "fooshop.myshopify.com")
+	req.Header.Set("X-Shopify-Webhook-Id", id)
+	req.Header.Set("X-Shopify-Hmac-Sha256", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder.Code
+}
+
+func TestWebhookRouter(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var events []*WebhookEvent
+	router := NewWebhookRouter(app)
+	router.Handle("orders/create", func(event *WebhookEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	router.Handle("carts/update", func(event *WebhookEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	router.Handle("orders/paid", func(event *WebhookEvent) error {
+		return errors.New("cannot handle")
+	})
+
+	resource := OrderResource{}
+	json.Unmarshal(loadFixture("order.json"), &resource)
+	body, _ := json.Marshal(resource.Order)
+	status := deliverWebhook(router, "orders/create", "1", body)
+	if status != 200 {
+		t.Fatalf("WebhookRouter responded with status %d, expected 200", status)
+	}
+	status = deliverWebhook(router, "carts/update", "2", []byte(`{"id": "abc"}`))
+	if status != 200 {
+		t.Fatalf("WebhookRouter responded with status %d, expected 200", status)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("WebhookRouter handled %d webhooks, expected 2", len(events))
+	}
+	order, ok := events[0].Payload.(*Order)
+	if !ok || order.ID == 0 || events[0].ShopDomain != "fooshop.myshopify.com" || events[0].WebhookID != "1" {
+		t.Errorf("WebhookRouter handled %+v, expected an order", events[0])
+	}
+	cart, ok := events[1].Payload.(map[string]interface{})
+	if !ok || cart["id"] != "abc" {
+		t.Errorf("WebhookRouter decoded cart as %v", events[1].Payload)
+	}
+
+	cases := []struct {
+		topic    string
+		body     string
+		expected int
+	}{
+		{"orders/paid", `{"id": 1}`, 500},
+		{"orders/create", `not json`, 400},
+		{"products/create", `{"id": 1}`, 200},
+		{"", `{"id": 1}`, 400},
+	}
+	for _, c := range cases {
+		status := deliverWebhook(router, c.topic, "3", []byte(c.body))
+		if status != c.expected {
+			t.Errorf("WebhookRouter responded to %v with status %d, expected %d", c.topic, status, c.expected)
+		}
+	}
+
+	req := httptest.NewRequest("POST", "/webhooks", strings.NewReader(`{"id": 1}`))
+	req.Header.Set("X-Shopify-Topic", "orders/create")
+	req.Header.Set("X-Shopify-Hmac-Sha256", "forged")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	if recorder.Code != 401 {
+		t.Errorf("WebhookRouter responded to a forged webhook with status %d, expected 401", recorder.Code)
+	}
+}
+
+func TestWebhookRouterMiddleware(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var order []string
+	trace := func(name string) WebhookMiddleware {
+		return func(next WebhookHandlerFunc) WebhookHandlerFunc {
+			return func(event *WebhookEvent) error {
+				order = append(order, name)
+				return next(event)
+			}
+		}
+	}
+
+	router := NewWebhookRouter(app)
+	router.Use(trace("first"), trace("second"))
+	router.Handle("products/update", func(event *WebhookEvent) error {
+		order = append(order, "handler")
+		return nil
+	})
+
+	deliverWebhook(router, "products/update", "1", []byte(`{"id": 1}`))
+	if strings.Join(order, ",") != "first,second,handler" {
+		t.Errorf("Middleware ran in order %v", order)
+	}
+}
+
+func TestDedupWebhooks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	handled := 0
+	fail := true
+	router := NewWebhookRouter(app)
+	router.Use(DedupWebhooks(time.Minute))
+	router.Handle("products/update", func(event *WebhookEvent) error {
+		handled++
+		if fail {
+			fail = false
+			return errors.New("try again")
+		}
+		return nil
+	})
+
+	for _, id := range []string{"1", "1", "1", "2"} {
+		deliverWebhook(router, "products/update", id, []byte(`{"id": 1}`))
+	}
+	// The failed first delivery, its retry and the second webhook
+	if handled != 3 {
+		t.Errorf("DedupWebhooks let %d webhooks through, expected 3", handled)
+	}
+}
+
+func TestDedupWebhooksConcurrent(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var handled int32
+	release := make(chan struct{})
+	router := NewWebhookRouter(app)
+	router.Use(DedupWebhooks(time.Minute))
+	router.Handle("products/update", func(event *WebhookEvent) error {
+		atomic.AddInt32(&handled, 1)
+		<-release
+		return nil
+	})
+
+	// The second delivery arrives while the first is being handled
+	done := make(chan struct{})
+	go func() {
+		deliverWebhook(router, "products/update", "1", []byte(`{"id": 1}`))
+		close(done)
+	}()
+	for atomic.LoadInt32(&handled) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	deliverWebhook(router, "products/update", "1", []byte(`{"id": 1}`))
+	close(release)
+	<-done
+
+	if handled != 1 {
+		t.Errorf("DedupWebhooks let %d concurrent deliveries through, expected 1", handled)
+	}
+}
+
+func TestWebhookDedupExpiry(t *testing.T) {
+	dedup := newWebhookDedup(time.Minute)
+	now := time.Now()
+
+	dedup.claim("1", now)
+	dedup.claim("2", now.Add(30*time.Second))
+	if dedup.claim("1", now.Add(time.Minute)) {
+		t.Errorf("webhookDedup.claim allowed a webhook again within the ttl")
+	}
+
+	// Only the first webhook expired
+	if !dedup.claim("3", now.Add(61*time.Second)) || len(dedup.ids) != 2 || dedup.order.Len() != 2 {
+		t.Errorf("webhookDedup kept %d webhooks after the first expired, expected 2", len(dedup.ids))
+	}
+	if !dedup.claim("1", now.Add(62*time.Second)) {
+		t.Errorf("webhookDedup.claim refused an expired webhook")
+	}
+}
+
+func TestAckWebhooks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	errs := make(chan error, 1)
+	router := NewWebhookRouter(app)
+	router.Use(AckWebhooks(func(event *WebhookEvent, err error) {
+		errs <- err
+	}))
+	router.Handle("products/update", func(event *WebhookEvent) error {
+		return errors.New("failed later")
+	})
+
+	status := deliverWebhook(router, "products/update", "1", []byte(`{"id": 1}`))
+	if status != 200 {
+		t.Errorf("AckWebhooks responded with status %d, expected 200", status)
+	}
+	select {
+	case err := <-errs:
+		if err.Error() != "failed later" {
+			t.Errorf("AckWebhooks passed error %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("AckWebhooks did not pass on the error")
+	}
+}
+
+func TestLogWebhooks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	buf := new(bytes.Buffer)
+	router := NewWebhookRouter(app)
+	router.Use(LogWebhooks(log.New(buf, "", 0)))
+	router.Handle("products/update", func(event *WebhookEvent) error {
+		return nil
+	})
+
+	deliverWebhook(router, "products/update", "42", []byte(`{"id": 1}`))
+	if !strings.HasPrefix(buf.String(), "webhook 42 products/update from fooshop.myshopify.com handled in") {
+		t.Errorf("LogWebhooks logged %q", buf.String())
+	}
+}